@@ -6,17 +6,19 @@ import (
 )
 
 var logCmd = &cobra.Command{
-	Use:   "log",
-	Short: "Show the detailed log of the last execution",
-	Long:  `Reads and displays the most recent log file from the .vex/logs directory.`,
+	Use:   "log [ambiente]",
+	Short: "Muestra el log de la última ejecución en un ambiente",
+	Long: `Muestra el log de la última ejecución del proyecto en un ambiente: el estado de
+cada paso y los comandos de sus hooks con su salida.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		factoryApp, err := factory.NewFactory()
+		factoryApp, err := factory.NewFactory(toolVersion)
 		if err != nil {
 			return err
 		}
 
 		logService := factoryApp.BuildLogService()
-		err = logService.ShowLog(factoryApp.PathAppProject())
+		err = logService.ShowLog(factoryApp.PathAppProject(), args[0])
 		if err != nil {
 			return err
 		}
@@ -24,3 +26,7 @@ var logCmd = &cobra.Command{
 		return nil
 	},
 }
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+// addParanoidFlag registra --paranoid en los comandos que calculan fingerprints.
+func addParanoidFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("paranoid", false, "calcula los fingerprints leyendo todos los archivos, sin usar el índice")
+}
+
+// readParanoidFlag devuelve la opción de la factoría que corresponde a --paranoid.
+func readParanoidFlag(cmd *cobra.Command) (factory.FactoryOption, error) {
+	paranoid, err := cmd.Flags().GetBool("paranoid")
+	if err != nil {
+		return nil, err
+	}
+	return factory.WithParanoidFingerprints(paranoid), nil
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	defAgg "github.com/jairoprogramador/vex/internal/domain/definition/aggregates"
+	defVos "github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan [paso] [ambiente]",
+	Short: "Muestra el plan combinado de las capas de la plantilla sin ejecutarlo",
+	Long: `Combina la plantilla base con sus overlays y muestra los pasos, comandos, hooks y
+variables que se ejecutarían, indicando la capa de la que sale cada elemento.
+Sin paso se muestran todos los pasos de la plantilla.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stepName, environment := "", ""
+		if len(args) > 0 {
+			stepName = args[0]
+		}
+		if len(args) > 1 {
+			environment = args[1]
+		}
+		templateDir, err := cmd.Flags().GetString("template-dir")
+		if err != nil {
+			return err
+		}
+
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+		orchestrator, err := factoryApp.BuildExecutionOrchestrator()
+		if err != nil {
+			return err
+		}
+
+		planDef, err := orchestrator.Plan(
+			context.Background(), stepName, environment, appDto.ExecutionOptions{TemplateDir: templateDir})
+		if err != nil {
+			return err
+		}
+		printPlan(planDef)
+		return nil
+	},
+}
+
+func printPlan(planDef *defAgg.ExecutionPlanDefinition) {
+	// Todas las capas aportan una fuente a cada paso, así que el primero sirve de leyenda.
+	layerNumbers := make(map[string]int)
+	fmt.Println("Capas:")
+	for i, source := range planDef.Steps()[0].SourcesDef() {
+		layerNumbers[source.Layer()] = i + 1
+		fmt.Printf("  [%d] %s\n", i+1, source.Layer())
+	}
+	layerOf := func(layer string) string {
+		if number, exists := layerNumbers[layer]; exists {
+			return fmt.Sprintf("[%d]", number)
+		}
+		if layer != "" {
+			// Variables que no vienen de la plantilla, como las de vexconfig.yaml.
+			return "[" + layer + "]"
+		}
+		return "[-]"
+	}
+
+	environment := planDef.Environment()
+	fmt.Printf("\nEntorno: %s (%s) %s\n", environment.String(), environment.Name(), layerOf(environment.Layer()))
+	if environment.Description() != "" {
+		fmt.Printf("  %s\n", environment.Description())
+	}
+	if environment.Extends() != "" {
+		fmt.Printf("  hereda de: %s\n", environment.Extends())
+	}
+	if environment.IsProtected() {
+		fmt.Println("  protegido")
+	}
+
+	fmt.Println("\nPasos:")
+	for _, step := range planDef.Steps() {
+		definedIn := ""
+		for _, source := range step.SourcesDef() {
+			if source.StepDir() != "" {
+				definedIn += layerOf(source.Layer())
+			}
+		}
+		fmt.Printf("  %s %s\n", step.NameDef().FullName(), definedIn)
+		printPlanCommands("comandos", step.CommandsDef(), layerOf)
+		hooks := step.HooksDef()
+		printPlanCommands("on_failure", hooks.OnFailure(), layerOf)
+		printPlanCommands("finally", hooks.Finally(), layerOf)
+		printPlanCommands("rollback", hooks.Rollback(), layerOf)
+
+		fingerprint := step.FingerprintScopeDef()
+		if len(fingerprint.Include()) > 0 {
+			fmt.Printf("    fingerprint incluye: %s\n", strings.Join(fingerprint.Include(), ", "))
+		}
+		if len(fingerprint.Exclude()) > 0 {
+			fmt.Printf("    fingerprint excluye: %s\n", strings.Join(fingerprint.Exclude(), ", "))
+		}
+
+		if len(step.VariablesDef()) > 0 {
+			fmt.Println("    variables:")
+			for _, variable := range step.VariablesDef() {
+				fmt.Printf("      - %s = %v %s\n", variable.Name(), variable.Value(), layerOf(variable.Layer()))
+			}
+		}
+	}
+
+	if collisions := planDef.OutputCollisions(); len(collisions) > 0 {
+		title := "\nSalidas repetidas:"
+		if planDef.Settings().StrictOutputs() {
+			title = "\nSalidas repetidas (strict_outputs: el plan no se ejecutará):"
+		}
+		fmt.Println(title)
+		for _, collision := range collisions {
+			fmt.Printf("  - %s\n", collision)
+		}
+	}
+}
+
+func printPlanCommands(title string, commands []defVos.CommandDefinition, layerOf func(string) string) {
+	if len(commands) == 0 {
+		return
+	}
+	fmt.Printf("    %s:\n", title)
+	for _, command := range commands {
+		fmt.Printf("      - %s: %s %s\n", command.Name(), command.Cmd(), layerOf(command.Layer()))
+	}
+}
+
+func init() {
+	planCmd.Flags().String("template-dir", "", "usa este directorio local como capa superior de la plantilla")
+	rootCmd.AddCommand(planCmd)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote [origen] [destino]",
+	Short: "Despliega en un ambiente la versión ya desplegada en el ambiente anterior",
+	Long: `Toma un despliegue exitoso del ambiente de origen y lo despliega en un ambiente
+del siguiente nivel de la cadena de promoción, definida por 'order' en environments.yaml.
+Los ambientes con el mismo 'order', p. ej. varias regiones, forman un nivel.
+El paso de empaquetado no se vuelve a ejecutar: se reutilizan sus salidas, como la
+etiqueta de la imagen o el digest del artefacto.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, _ := cmd.Flags().GetString("version")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		templateDir, _ := cmd.Flags().GetString("template-dir")
+		variables, variableOrigins, err := readVarFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		paranoid, err := readParanoidFlag(cmd)
+		if err != nil {
+			return err
+		}
+		factoryApp, err := factory.NewFactory(toolVersion, paranoid)
+		if err != nil {
+			return err
+		}
+		orchestrator, err := factoryApp.BuildExecutionOrchestrator()
+		if err != nil {
+			return err
+		}
+
+		return orchestrator.Promote(context.Background(), args[0], args[1], version, appDto.ExecutionOptions{
+			TemplateDir:     templateDir,
+			Variables:       variables,
+			VariableOrigins: variableOrigins,
+			AssumeYes:       assumeYes,
+			Confirm:         confirmProtectedEnvironment,
+		})
+	},
+}
+
+func init() {
+	promoteCmd.Flags().String("version", "", "versión a promover; si se omite se promueve el último despliegue del origen")
+	promoteCmd.Flags().BoolP("yes", "y", false, "confirma sin preguntar la promoción a ambientes protegidos")
+	promoteCmd.Flags().String("template-dir", "", "usa la plantilla de este directorio local en lugar de clonar la del proyecto")
+	addVarFlags(promoteCmd)
+	addParanoidFlag(promoteCmd)
+	rootCmd.AddCommand(promoteCmd)
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+const releaseTimeLayout = "2006-01-02 15:04:05"
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Consulta los manifiestos de las ejecuciones registradas",
+}
+
+var releaseListCmd = &cobra.Command{
+	Use:   "list [ambiente]",
+	Short: "Lista las ejecuciones registradas en un ambiente",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+
+		releases, err := factoryApp.BuildReleaseService().List(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+		if len(releases) == 0 {
+			fmt.Printf("No hay ejecuciones registradas en el ambiente '%s'.\n", args[0])
+			return nil
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tVERSIÓN\tTIPO\tPASO\tESTADO\tINICIO\tDURACIÓN")
+		for _, release := range releases {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				shortHash(release.ID()),
+				release.Version(),
+				release.Kind(),
+				release.FinalStep(),
+				release.Status(),
+				release.StartedAt().Local().Format(releaseTimeLayout),
+				formatDuration(release.StartedAt(), release.FinishedAt()))
+		}
+		return writer.Flush()
+	},
+}
+
+var releaseShowCmd = &cobra.Command{
+	Use:   "show [ambiente] [id|versión]",
+	Short: "Muestra el manifiesto de una ejecución registrada",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+
+		release, err := factoryApp.BuildReleaseService().Find(context.Background(), args[0], args[1])
+		if err != nil {
+			return err
+		}
+		printRelease(release)
+		return nil
+	},
+}
+
+func printRelease(release *relAgg.Release) {
+	fmt.Printf("Ejecución:   %s\n", release.ID())
+	fmt.Printf("Ambiente:    %s\n", release.Environment())
+	fmt.Printf("Tipo:        %s\n", release.Kind())
+	if release.RollbackOf() != "" {
+		fmt.Printf("Restaura:    %s\n", release.RollbackOf())
+	}
+	if release.PromotedFrom() != "" {
+		fmt.Printf("Promueve:    %s\n", release.PromotedFrom())
+	}
+	fmt.Printf("Estado:      %s\n", release.Status())
+	if release.ErrorMessage() != "" {
+		fmt.Printf("Error:       %s\n", release.ErrorMessage())
+	}
+	fmt.Printf("Versión:     %s\n", release.Version())
+	fmt.Printf("Commit:      %s\n", release.Commit())
+	fmt.Printf("Plantilla:   %s@%s (%s)\n", release.Template().URL(), release.Template().Ref(), release.Template().SHA())
+	for _, overlay := range release.TemplateOverlays() {
+		fmt.Printf("Overlay:     %s@%s (%s)\n", overlay.URL(), overlay.Ref(), overlay.SHA())
+	}
+	fmt.Printf("Vex:         %s\n", release.ToolVersion())
+	fmt.Printf("Inicio:      %s\n", release.StartedAt().Local().Format(releaseTimeLayout))
+	fmt.Printf("Duración:    %s\n", formatDuration(release.StartedAt(), release.FinishedAt()))
+
+	fmt.Println("\nPasos:")
+	for _, step := range release.Steps() {
+		fmt.Printf("  - %s: %s (%s)\n", step.Name(), step.Outcome(), formatDuration(step.StartedAt(), step.FinishedAt()))
+		fingerprints := step.Fingerprints()
+		fmt.Printf("      código: %s\n", fingerprints.Code())
+		fmt.Printf("      instrucciones: %s\n", fingerprints.Instruction())
+		fmt.Printf("      variables: %s\n", fingerprints.Vars())
+		fmt.Printf("      entorno: %s\n", fingerprints.Environment())
+		printVariables("      ", step.Outputs())
+		for _, hook := range step.Hooks() {
+			result := "ok"
+			if !hook.Succeeded() {
+				result = "falló: " + hook.ErrorMessage()
+			}
+			fmt.Printf("      hook %s '%s': %s\n", hook.Kind(), hook.Command(), result)
+		}
+	}
+
+	if overrides := release.Overrides(); len(overrides) > 0 {
+		fmt.Println("\nVariables de la línea de comandos:")
+		printVariables("  ", overrides)
+	}
+
+	fmt.Println("\nVariables:")
+	printVariables("  ", release.Variables())
+}
+
+func printVariables(indent string, variables map[string]string) {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s%s = %s\n", indent, name, variables[name])
+	}
+}
+
+// shortHash abrevia un id de ejecución o un commit. Una ejecución registrada sin git
+// no tiene commit, y un commit puede guardarse ya abreviado.
+func shortHash(hash string) string {
+	if hash == "" {
+		return "-"
+	}
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
+
+func formatDuration(startedAt, finishedAt time.Time) string {
+	if finishedAt.IsZero() {
+		return "-"
+	}
+	return finishedAt.Sub(startedAt).Round(time.Millisecond).String()
+}
+
+func init() {
+	releaseCmd.AddCommand(releaseListCmd)
+	releaseCmd.AddCommand(releaseShowCmd)
+	rootCmd.AddCommand(releaseCmd)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [ambiente]",
+	Short: "Vuelve a desplegar una versión anterior en un ambiente",
+	Long: `Lista los despliegues exitosos registrados para el ambiente y vuelve a desplegar
+la versión seleccionada partiendo de las variables que estaban vigentes en ese
+momento. Los secretos no se registran: si un paso los usó, indícalos con --var
+o --var-file.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environment := args[0]
+		version, _ := cmd.Flags().GetString("version")
+		assumeYes, _ := cmd.Flags().GetBool("yes")
+		variables, variableOrigins, err := readVarFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		paranoid, err := readParanoidFlag(cmd)
+		if err != nil {
+			return err
+		}
+		factoryApp, err := factory.NewFactory(toolVersion, paranoid)
+		if err != nil {
+			return err
+		}
+
+		orchestrator, err := factoryApp.BuildExecutionOrchestrator()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		deployments, err := orchestrator.ListDeployments(ctx, environment)
+		if err != nil {
+			return err
+		}
+		if len(deployments) == 0 {
+			return fmt.Errorf("no hay despliegues registrados para el ambiente '%s'", environment)
+		}
+
+		target, err := selectDeployment(deployments, version)
+		if err != nil {
+			return err
+		}
+
+		return orchestrator.RollbackTo(ctx, target, appDto.ExecutionOptions{
+			Variables:       variables,
+			VariableOrigins: variableOrigins,
+			AssumeYes:       assumeYes,
+			Confirm:         confirmProtectedEnvironment,
+		})
+	},
+}
+
+func selectDeployment(deployments []*relAgg.Release, version string) (*relAgg.Release, error) {
+	if version != "" {
+		for _, deployment := range deployments {
+			if deployment.Version() == version {
+				return deployment, nil
+			}
+		}
+		return nil, fmt.Errorf("no hay un despliegue exitoso registrado con la versión '%s'", version)
+	}
+
+	options := make([]string, len(deployments))
+	for i, deployment := range deployments {
+		options[i] = fmt.Sprintf("%s (%s, %s)",
+			deployment.Version(), shortHash(deployment.Commit()), deployment.FinishedAt().Format("2006-01-02 15:04:05"))
+	}
+
+	var selected int
+	prompt := &survey.Select{
+		Message: "Selecciona la versión a restaurar:",
+		Options: options,
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		return nil, errors.New("rollback cancelado")
+	}
+	return deployments[selected], nil
+}
+
+func init() {
+	rollbackCmd.Flags().String("version", "", "versión a restaurar; si se omite se muestra una lista para elegir")
+	rollbackCmd.Flags().BoolP("yes", "y", false, "confirma sin preguntar el rollback de ambientes protegidos")
+	addVarFlags(rollbackCmd)
+	addParanoidFlag(rollbackCmd)
+	rootCmd.AddCommand(rollbackCmd)
+}
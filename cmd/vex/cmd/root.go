@@ -5,16 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
 	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "vex [paso] [ambiente]",
+	Use:   "vex [paso] [ambiente[,ambiente...]]",
 	Short: "Vex es una herramienta CLI para automatizar despliegues.",
 	Long:  `Una herramienta para orquestar despliegues de software a través de diferentes ambientes`,
 	Args: func(cmd *cobra.Command, args []string) error {
@@ -33,25 +38,140 @@ var rootCmd = &cobra.Command{
 			environment = args[1]
 		}
 
-		factoryApp, err := factory.NewFactory()
+		paranoid, err := readParanoidFlag(cmd)
+		if err != nil {
+			return err
+		}
+		factoryApp, err := factory.NewFactory(toolVersion, paranoid)
 		if err != nil {
 			return err
 		}
 
-		orchestrator, err := factoryApp.BuildExecutionOrchestrator()
+		rollbackOnFailure, err := cmd.Flags().GetBool("rollback-on-failure")
 		if err != nil {
 			return err
 		}
-		err = orchestrator.ExecutePlan(context.Background(), finalStepName, environment)
+		templateDir, err := cmd.Flags().GetString("template-dir")
 		if err != nil {
 			return err
 		}
-		return nil
+		assumeYes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return err
+		}
+		variables, variableOrigins, err := readVarFlags(cmd)
+		if err != nil {
+			return err
+		}
+		opts := appDto.ExecutionOptions{
+			RollbackOnFailure: rollbackOnFailure,
+			TemplateDir:       templateDir,
+			Variables:         variables,
+			VariableOrigins:   variableOrigins,
+			AssumeYes:         assumeYes,
+			Confirm:           confirmProtectedEnvironment,
+		}
+
+		envGroup, err := cmd.Flags().GetString("env-group")
+		if err != nil {
+			return err
+		}
+		parallel, err := cmd.Flags().GetInt("parallel")
+		if err != nil {
+			return err
+		}
+
+		orchestrator, err := factoryApp.BuildExecutionOrchestrator()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		environments := splitEnvironments(environment)
+		if envGroup != "" {
+			if environment != "" {
+				return errors.New("indica los ambientes como argumento o con --env-group, no ambos")
+			}
+			environments, err = orchestrator.EnvironmentGroup(ctx, envGroup, opts)
+			if err != nil {
+				return err
+			}
+		}
+		switch len(environments) {
+		case 0:
+			return orchestrator.ExecutePlan(ctx, finalStepName, "", opts)
+		case 1:
+			return orchestrator.ExecutePlan(ctx, finalStepName, environments[0], opts)
+		}
+
+		results := orchestrator.ExecutePlanInEnvironments(ctx, finalStepName, environments, parallel, opts)
+		return printEnvironmentResults(results)
 	},
 }
 
+// splitEnvironments separa una lista de ambientes escrita como 'prod-eu,prod-us',
+// descartando los vacíos y los repetidos.
+func splitEnvironments(value string) []string {
+	environments := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, environment := range strings.Split(value, ",") {
+		environment = strings.TrimSpace(environment)
+		if environment == "" {
+			continue
+		}
+		if _, duplicated := seen[environment]; duplicated {
+			continue
+		}
+		seen[environment] = struct{}{}
+		environments = append(environments, environment)
+	}
+	return environments
+}
+
+// printEnvironmentResults muestra el resumen de una ejecución en varios ambientes y
+// devuelve un error si alguno falló.
+func printEnvironmentResults(results []appDto.EnvironmentRunResult) error {
+	fmt.Println()
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "AMBIENTE\tESTADO\tDURACIÓN\tERROR")
+	failed := 0
+	for _, result := range results {
+		status := color.GreenString("ok")
+		message := "-"
+		if !result.Succeeded() {
+			failed++
+			status = color.RedString("error")
+			message = strings.SplitN(result.Err.Error(), "\n", 2)[0]
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+			result.Environment, status, result.Duration.Round(time.Second), message)
+	}
+	writer.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("la ejecución falló en %d de %d ambientes", failed, len(results))
+	}
+	return nil
+}
+
+// confirmProtectedEnvironment pide escribir el nombre de un ambiente protegido antes de ejecutarlo.
+func confirmProtectedEnvironment(environment string) (string, error) {
+	var typed string
+	prompt := &survey.Input{
+		Message: fmt.Sprintf("El ambiente '%s' está protegido. Escribe su nombre para continuar:", environment),
+	}
+	if err := survey.AskOne(prompt, &typed); err != nil {
+		return "", err
+	}
+	return typed, nil
+}
+
+// toolVersion es la versión de vex que se registra en los manifiestos de ejecución.
+var toolVersion = "unknown"
+
 func Execute(versionMain string) {
 	version := versionMain
+	toolVersion = version
 	rootCmd.Version = fmt.Sprintf("v%s\n", version)
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -65,7 +185,13 @@ func init() {
 	rootCmd.PersistentFlags().String("color", "always", "control color output (auto, always, never)")
 	viper.BindPFlag("color", rootCmd.PersistentFlags().Lookup("color"))
 
-	//rootCmd.AddCommand(logCmd)
+	rootCmd.Flags().Bool("rollback-on-failure", false, "revierte los pasos completados en esta ejecución si un paso posterior falla")
+	addVarFlags(rootCmd)
+	addParanoidFlag(rootCmd)
+	rootCmd.Flags().String("env-group", "", "ejecuta el plan en todos los ambientes de este grupo de environments.yaml")
+	rootCmd.Flags().Int("parallel", 3, "número máximo de ambientes que se ejecutan a la vez")
+	rootCmd.Flags().BoolP("yes", "y", false, "confirma sin preguntar la ejecución en ambientes protegidos")
+	rootCmd.Flags().String("template-dir", "", "usa la plantilla de este directorio local en lugar de clonar la del proyecto")
 
 	cobra.OnInitialize(initConfig)
 }
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jairoprogramador/vex/internal/application"
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Consulta y limpia el estado con el que vex decide si omitir un paso",
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:   "show [paso]",
+	Short: "Muestra las entradas de estado de los pasos por ambiente",
+	Long: `Muestra, por paso y ambiente, las entradas de estado guardadas con su antigüedad
+y qué huellas difieren de las actuales. Un paso se omite cuando alguna de sus
+entradas coincide en todas las huellas. Las huellas actuales se calculan sin las
+variables de --var y --var-file.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stepName := ""
+		if len(args) > 0 {
+			stepName = args[0]
+		}
+		templateDir, err := cmd.Flags().GetString("template-dir")
+		if err != nil {
+			return err
+		}
+		paranoid, err := readParanoidFlag(cmd)
+		if err != nil {
+			return err
+		}
+		orchestrator, err := buildOrchestrator(paranoid)
+		if err != nil {
+			return err
+		}
+		states, err := orchestrator.InspectState(
+			context.Background(), stepName, appDto.ExecutionOptions{TemplateDir: templateDir})
+		if err != nil {
+			return err
+		}
+		if len(states) == 0 {
+			fmt.Println("No hay estado guardado.")
+			return nil
+		}
+		now := time.Now()
+		for i, state := range states {
+			if i > 0 {
+				fmt.Println()
+			}
+			printStepState(state, now)
+		}
+		return nil
+	},
+}
+
+var stateClearCmd = &cobra.Command{
+	Use:   "clear [paso] [ambiente]",
+	Short: "Borra el estado de un paso para que la siguiente ejecución no lo omita",
+	Long: `Borra las entradas de estado de un paso en un ambiente, o en todos si no se indica
+ambiente. La siguiente ejecución del paso no se omitirá.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		envName := ""
+		if len(args) > 1 {
+			envName = args[1]
+		}
+		orchestrator, err := buildOrchestrator()
+		if err != nil {
+			return err
+		}
+		removed, err := orchestrator.ClearState(context.Background(), args[0], envName)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Se borraron %d entradas de estado del paso '%s'.\n", removed, args[0])
+		return nil
+	},
+}
+
+var statePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Borra las entradas de estado más antiguas que --older-than",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, err := cmd.Flags().GetString("older-than")
+		if err != nil {
+			return err
+		}
+		age, err := parseAge(olderThan)
+		if err != nil {
+			return err
+		}
+		orchestrator, err := buildOrchestrator()
+		if err != nil {
+			return err
+		}
+		removed, err := orchestrator.PruneState(context.Background(), time.Now().Add(-age))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Se borraron %d entradas de estado con más de %s.\n", removed, olderThan)
+		return nil
+	},
+}
+
+func printStepState(state appDto.StepState, now time.Time) {
+	fmt.Printf("%s:\n", state.Step)
+	for _, entry := range state.Entries {
+		fmt.Printf("  %-12s hace %-8s código %s  instrucciones %s  variables %s\n",
+			entry.Environment, formatAge(now.Sub(entry.CreatedAt)),
+			shortFingerprint(entry.Code), shortFingerprint(entry.Instruction), shortFingerprint(entry.Vars))
+		switch {
+		case !entry.Compared:
+			fmt.Printf("    sin comparar: %s\n", entry.CompareError)
+		case len(entry.Changed) == 0:
+			fmt.Println("    vigente: coincide con las huellas actuales")
+		default:
+			fmt.Printf("    cambió: %s\n", strings.Join(entry.Changed, ", "))
+		}
+	}
+}
+
+func shortFingerprint(fingerprint string) string {
+	if fingerprint == "" {
+		return "-"
+	}
+	if len(fingerprint) > 12 {
+		return fingerprint[:12]
+	}
+	return fingerprint
+}
+
+// formatAge muestra una antigüedad en la unidad mayor que tenga, p. ej. "3d" o "5m".
+func formatAge(age time.Duration) string {
+	switch {
+	case age >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	case age >= time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	case age >= time.Minute:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	default:
+		return fmt.Sprintf("%ds", int(age.Seconds()))
+	}
+}
+
+// parseAge interpreta una antigüedad como "30d" o cualquier duración de Go, como "12h".
+func parseAge(value string) (time.Duration, error) {
+	if days, isDays := strings.CutSuffix(value, "d"); isDays {
+		count, err := strconv.Atoi(days)
+		if err == nil && count > 0 {
+			return time.Duration(count) * 24 * time.Hour, nil
+		}
+	} else if age, err := time.ParseDuration(value); err == nil && age > 0 {
+		return age, nil
+	}
+	return 0, fmt.Errorf("antigüedad inválida '%s': usa, p. ej., 30d o 12h", value)
+}
+
+func buildOrchestrator(opts ...factory.FactoryOption) (*application.ExecutionOrchestrator, error) {
+	factoryApp, err := factory.NewFactory(toolVersion, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return factoryApp.BuildExecutionOrchestrator()
+}
+
+func init() {
+	stateShowCmd.Flags().String("template-dir", "", "usa este directorio local como capa superior de la plantilla")
+	addParanoidFlag(stateShowCmd)
+	statePruneCmd.Flags().String("older-than", "30d", "antigüedad a partir de la cual se borran las entradas, p. ej. 30d o 12h")
+
+	stateCmd.AddCommand(stateShowCmd)
+	stateCmd.AddCommand(stateClearCmd)
+	stateCmd.AddCommand(statePruneCmd)
+	rootCmd.AddCommand(stateCmd)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Gestiona el repositorio de plantillas del proyecto",
+}
+
+var templateUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Actualiza el commit bloqueado de la plantilla",
+	Long: `Trae los cambios del repositorio de plantillas, resuelve la referencia configurada
+en vexconfig.yaml y guarda el nuevo commit en vexconfig.lock, mostrando los commits incorporados.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+
+		_, err = factoryApp.BuildTemplateService().Update(context.Background())
+		return err
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(templateUpdateCmd)
+	rootCmd.AddCommand(templateCmd)
+}
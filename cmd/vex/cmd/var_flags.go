@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// addVarFlags registra --var y --var-file en un comando que ejecuta el plan.
+func addVarFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("var", nil, "fija una variable con prioridad sobre cualquier otro origen, p. ej. --var replicas=3")
+	cmd.Flags().StringArray("var-file", nil, "fija las variables de un archivo YAML de pares nombre: valor")
+}
+
+// readVarFlags combina los archivos de --var-file, en orden, y después los valores
+// de --var, que tienen prioridad sobre los archivos. Devuelve también el flag o
+// archivo que fijó cada variable.
+func readVarFlags(cmd *cobra.Command) (map[string]string, map[string]string, error) {
+	files, err := cmd.Flags().GetStringArray("var-file")
+	if err != nil {
+		return nil, nil, err
+	}
+	assignments, err := cmd.Flags().GetStringArray("var")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variables := make(map[string]string)
+	origins := make(map[string]string)
+	for _, file := range files {
+		fileVariables, err := readVarFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		for name, value := range fileVariables {
+			variables[name] = value
+			origins[name] = "--var-file " + file
+		}
+	}
+	for _, assignment := range assignments {
+		name, value, found := strings.Cut(assignment, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || value == "" {
+			return nil, nil, fmt.Errorf("variable inválida '%s': usa el formato nombre=valor", assignment)
+		}
+		variables[name] = value
+		origins[name] = "--var"
+	}
+	return variables, origins, nil
+}
+
+func readVarFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el archivo de variables '%s': %w", path, err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error al parsear el archivo de variables '%s': %w", path, err)
+	}
+
+	variables := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("la variable '%s' de '%s' debe tener un valor simple", name, path)
+		case nil:
+			return nil, fmt.Errorf("la variable '%s' de '%s' no tiene valor", name, path)
+		default:
+			variables[name] = fmt.Sprintf("%v", value)
+		}
+	}
+	return variables, nil
+}
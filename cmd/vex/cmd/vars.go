@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	relVos "github.com/jairoprogramador/vex/internal/domain/release/vos"
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+var varsCmd = &cobra.Command{
+	Use:   "vars",
+	Short: "Consulta y corrige las variables guardadas de un ambiente",
+}
+
+var varsListCmd = &cobra.Command{
+	Use:   "list [ambiente] [paso]",
+	Short: "Lista las salidas guardadas de los pasos de un ambiente",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reveal, _ := cmd.Flags().GetBool("show-secrets")
+		stored, err := listStoredVariables(args, reveal)
+		if err != nil {
+			return err
+		}
+		if len(stored) == 0 {
+			fmt.Printf("No hay variables guardadas en el ambiente '%s'.\n", args[0])
+			return nil
+		}
+		for i, step := range stored {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s/%s:\n", step.Scope, step.Step)
+			printVariables("  ", step.Variables)
+		}
+		return nil
+	},
+}
+
+var varsGetCmd = &cobra.Command{
+	Use:   "get [ambiente] [paso] [variable]",
+	Short: "Muestra el valor guardado de una variable",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shared, _ := cmd.Flags().GetBool("shared")
+		reveal, _ := cmd.Flags().GetBool("show-secrets")
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+		value, err := factoryApp.BuildVarsService().Get(context.Background(), args[0], args[1], args[2], shared, reveal)
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var varsSetCmd = &cobra.Command{
+	Use:   "set [ambiente] [paso] [variable] [valor]",
+	Short: "Cambia el valor guardado de una variable",
+	Long: `Cambia el valor guardado de una salida de un paso, p. ej. cuando la sonda
+capturó un valor equivocado. El paso no se vuelve a ejecutar: el nuevo valor se usa
+en las siguientes ejecuciones de los pasos posteriores.`,
+	Args: cobra.ExactArgs(4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shared, _ := cmd.Flags().GetBool("shared")
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+		return factoryApp.BuildVarsService().Set(context.Background(), args[0], args[1], args[2], args[3], shared)
+	},
+}
+
+var varsUnsetCmd = &cobra.Command{
+	Use:   "unset [ambiente] [paso] [variable]",
+	Short: "Borra una variable guardada",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shared, _ := cmd.Flags().GetBool("shared")
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+		return factoryApp.BuildVarsService().Unset(context.Background(), args[0], args[1], args[2], shared)
+	},
+}
+
+var varsExportCmd = &cobra.Command{
+	Use:   "export [ambiente] [paso]",
+	Short: "Exporta las salidas guardadas de un ambiente como dotenv, JSON o YAML",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		reveal, _ := cmd.Flags().GetBool("show-secrets")
+		stored, err := listStoredVariables(args, reveal)
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case "dotenv":
+			fmt.Print(formatDotenv(stored))
+			return nil
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(groupByScope(stored))
+		case "yaml":
+			encoder := yaml.NewEncoder(os.Stdout)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(groupByScope(stored)); err != nil {
+				return err
+			}
+			return encoder.Close()
+		default:
+			return fmt.Errorf("formato '%s' no soportado: usa dotenv, json o yaml", format)
+		}
+	},
+}
+
+func listStoredVariables(args []string, reveal bool) ([]appDto.StoredVariables, error) {
+	factoryApp, err := factory.NewFactory(toolVersion)
+	if err != nil {
+		return nil, err
+	}
+	stepName := ""
+	if len(args) == 2 {
+		stepName = args[1]
+	}
+	return factoryApp.BuildVarsService().List(context.Background(), args[0], stepName, reveal)
+}
+
+// groupByScope agrupa las variables por ámbito y paso: {ambiente: {paso: {variable: valor}}}.
+func groupByScope(stored []appDto.StoredVariables) map[string]map[string]map[string]string {
+	grouped := make(map[string]map[string]map[string]string)
+	for _, step := range stored {
+		if grouped[step.Scope] == nil {
+			grouped[step.Scope] = make(map[string]map[string]string)
+		}
+		grouped[step.Scope][step.Step] = step.Variables
+	}
+	return grouped
+}
+
+// formatDotenv escribe una línea NOMBRE=valor por variable, precedidas por un
+// comentario con el paso. Si dos pasos guardan la misma variable, la última línea
+// es la que toman los cargadores de dotenv.
+func formatDotenv(stored []appDto.StoredVariables) string {
+	var builder strings.Builder
+	for _, step := range stored {
+		fmt.Fprintf(&builder, "# %s/%s\n", step.Scope, step.Step)
+		names := make([]string, 0, len(step.Variables))
+		for name := range step.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&builder, "%s=%s\n", name, dotenvValue(step.Variables[name]))
+		}
+	}
+	return builder.String()
+}
+
+func dotenvValue(value string) string {
+	if strings.ContainsAny(value, " \t\n\"'#$\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+var varsExplainCmd = &cobra.Command{
+	Use:   "explain [ambiente] [variable]",
+	Short: "Muestra de dónde sale el valor de cada variable",
+	Long: `Muestra, para cada paso de la última ejecución del ambiente, la cadena de
+resolución de sus variables: el valor vigente, quién lo fijó (archivo de la
+plantilla, paso y comando, flag de la línea de comandos...) y los valores que
+sobrescribió. Si se indica una variable, solo se muestra esa.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+
+		release, err := factoryApp.BuildReleaseService().LatestWithVariables(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+
+		name := ""
+		if len(args) == 2 {
+			name = args[1]
+		}
+
+		fmt.Printf("Ejecución %s (%s, %s)\n", shortHash(release.ID()), release.Version(),
+			release.StartedAt().Local().Format(releaseTimeLayout))
+		found := false
+		for _, step := range release.Steps() {
+			traces := step.Variables()
+			if name != "" {
+				trace, exists := step.Variable(name)
+				if !exists {
+					continue
+				}
+				traces = []relVos.VariableTrace{trace}
+			}
+			if len(traces) == 0 {
+				continue
+			}
+			found = true
+
+			fmt.Printf("\nPaso %s:\n", step.Name())
+			for _, trace := range traces {
+				fmt.Printf("  %s = %s  [%s]\n", trace.Name(), trace.Value(), trace.Source())
+				for _, overridden := range trace.Overridden() {
+					fmt.Printf("    sobrescribe: %s  [%s]\n", overridden.Value(), overridden.Source())
+				}
+			}
+		}
+		if !found && name != "" {
+			return fmt.Errorf("la variable '%s' no se usó en ningún paso de la ejecución '%s'", name, shortHash(release.ID()))
+		}
+		return nil
+	},
+}
+
+func init() {
+	varsListCmd.Flags().Bool("show-secrets", false, "muestra los valores de las variables secretas")
+	varsGetCmd.Flags().Bool("show-secrets", false, "muestra el valor aunque la variable sea secreta")
+	varsGetCmd.Flags().Bool("shared", false, "usa las variables compartidas entre ambientes del paso")
+	varsSetCmd.Flags().Bool("shared", false, "usa las variables compartidas entre ambientes del paso")
+	varsUnsetCmd.Flags().Bool("shared", false, "usa las variables compartidas entre ambientes del paso")
+	varsExportCmd.Flags().String("format", "dotenv", "formato de salida: dotenv, json o yaml")
+	varsExportCmd.Flags().Bool("show-secrets", false, "exporta los valores de las variables secretas")
+
+	varsCmd.AddCommand(varsListCmd)
+	varsCmd.AddCommand(varsGetCmd)
+	varsCmd.AddCommand(varsSetCmd)
+	varsCmd.AddCommand(varsUnsetCmd)
+	varsCmd.AddCommand(varsExportCmd)
+	varsCmd.AddCommand(varsExplainCmd)
+	rootCmd.AddCommand(varsCmd)
+}
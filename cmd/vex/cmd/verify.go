@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [ambiente|archivo]",
+	Short: "Verifica la declaración de procedencia de un despliegue",
+	Long: `Comprueba la firma de una declaración de procedencia y la contrasta con el checkout actual:
+commit del proyecto, commit de la plantilla y artefactos declarados.
+Si se indica un ambiente, se verifica su declaración más reciente. Un artefacto
+sin copia local no se puede comprobar y hace fallar la verificación salvo con
+--allow-missing-artifacts.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		allowMissing, _ := cmd.Flags().GetBool("allow-missing-artifacts")
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+
+		report, err := factoryApp.BuildProvenanceService().Verify(context.Background(), args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Declaración: %s\n", report.StatementPath)
+		fmt.Printf("Ambiente:    %s\n", report.Environment)
+		fmt.Printf("Versión:     %s\n", report.Version)
+		for _, check := range report.Checks {
+			mark := "OK"
+			if check.Skipped {
+				mark = "OMITIDO"
+			} else if !check.Passed {
+				mark = "FALLO"
+			}
+			fmt.Printf("  [%s] %s: %s\n", mark, check.Name, check.Detail)
+		}
+
+		if !report.Passed(allowMissing) {
+			if report.Passed(true) {
+				return errors.New("no se comprobaron todos los artefactos declarados; usa --allow-missing-artifacts para aceptarlo")
+			}
+			return errors.New("la declaración de procedencia no coincide con el checkout actual")
+		}
+		return nil
+	},
+}
+
+func init() {
+	verifyCmd.Flags().Bool("allow-missing-artifacts", false, "acepta los artefactos declarados que no tienen copia local")
+	rootCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why [paso] [ambiente]",
+	Short: "Explica por qué un paso se volverá a ejecutar en un ambiente",
+	Long: `Compara las huellas actuales de un paso con la entrada de estado más reciente del
+ambiente y lista los archivos del proyecto añadidos, borrados y modificados y las
+variables que cambiaron.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templateDir, err := cmd.Flags().GetString("template-dir")
+		if err != nil {
+			return err
+		}
+		vars, origins, err := readVarFlags(cmd)
+		if err != nil {
+			return err
+		}
+		paranoid, err := readParanoidFlag(cmd)
+		if err != nil {
+			return err
+		}
+		orchestrator, err := buildOrchestrator(paranoid)
+		if err != nil {
+			return err
+		}
+		diagnosis, err := orchestrator.Why(context.Background(), args[0], args[1], appDto.ExecutionOptions{
+			TemplateDir:     templateDir,
+			Variables:       vars,
+			VariableOrigins: origins,
+		})
+		if err != nil {
+			return err
+		}
+		printDiagnosis(diagnosis)
+		return nil
+	},
+}
+
+func printDiagnosis(diagnosis appDto.StepDiagnosis) {
+	if !diagnosis.HasBaseline {
+		fmt.Printf("El paso '%s' no tiene estado guardado: se ejecutará.\n", diagnosis.Step)
+		return
+	}
+	if !diagnosis.Rerun {
+		fmt.Printf("El paso '%s' no se volverá a ejecutar en '%s': coincide con una entrada de estado.\n",
+			diagnosis.Step, diagnosis.Environment)
+	} else {
+		fmt.Printf("El paso '%s' se volverá a ejecutar en '%s'.\n", diagnosis.Step, diagnosis.Environment)
+	}
+	fmt.Printf("Comparado con la entrada de '%s' de hace %s.\n",
+		diagnosis.BaselineEnvironment, formatAge(time.Since(diagnosis.BaselineCreatedAt)))
+	if len(diagnosis.Changed) == 0 {
+		fmt.Println("Las huellas coinciden.")
+		return
+	}
+	fmt.Printf("Huellas que cambiaron: %s\n", strings.Join(diagnosis.Changed, ", "))
+
+	printManifestChanges("Archivos", diagnosis.Files)
+	printManifestChanges("Variables", diagnosis.Variables)
+}
+
+func printManifestChanges(title string, changes *appDto.ManifestChanges) {
+	if changes == nil {
+		fmt.Printf("\n%s: la entrada de estado no guarda el detalle.\n", title)
+		return
+	}
+	if len(changes.Added)+len(changes.Removed)+len(changes.Modified) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+	for _, name := range changes.Added {
+		fmt.Printf("  + %s\n", name)
+	}
+	for _, name := range changes.Removed {
+		fmt.Printf("  - %s\n", name)
+	}
+	for _, name := range changes.Modified {
+		fmt.Printf("  ~ %s\n", name)
+	}
+}
+
+func init() {
+	whyCmd.Flags().String("template-dir", "", "usa este directorio local como capa superior de la plantilla")
+	addVarFlags(whyCmd)
+	addParanoidFlag(whyCmd)
+	rootCmd.AddCommand(whyCmd)
+}
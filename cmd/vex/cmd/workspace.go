@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jairoprogramador/vex/internal/infrastructure/factory"
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Gestiona los workspaces de vex",
+}
+
+var workspaceMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Convierte el estado y las variables guardadas al formato actual",
+	Long: `Recorre todos los workspaces de ~/.vex (o de VEX_HOME) y convierte al formato JSON
+actual las tablas de estado y los archivos de variables guardados por versiones anteriores.
+vex también convierte los que usa al ejecutar un paso; este comando lo hace de una vez para todo el árbol.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		factoryApp, err := factory.NewFactory(toolVersion)
+		if err != nil {
+			return err
+		}
+
+		result, err := factoryApp.BuildStorageMigrationService().Migrate(context.Background())
+		if err != nil {
+			return err
+		}
+		for _, path := range result.Migrated {
+			fmt.Printf("  ~ %s\n", path)
+		}
+		for _, failure := range result.Failed {
+			fmt.Printf("  ! %s: %s\n", failure.Path, failure.Error)
+		}
+		fmt.Printf("Se convirtieron %d archivos; %d ya estaban en el formato actual.\n",
+			len(result.Migrated), result.Current)
+		if len(result.Failed) > 0 {
+			return fmt.Errorf("no se pudieron convertir %d archivos", len(result.Failed))
+		}
+		return nil
+	},
+}
+
+func init() {
+	workspaceCmd.AddCommand(workspaceMigrateCmd)
+	rootCmd.AddCommand(workspaceCmd)
+}
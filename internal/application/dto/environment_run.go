@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// EnvironmentRunResult es el resultado de ejecutar el plan en uno de varios ambientes.
+type EnvironmentRunResult struct {
+	Environment string
+	Duration    time.Duration
+	Err         error
+}
+
+// Succeeded indica si el plan terminó sin errores en el ambiente.
+func (r EnvironmentRunResult) Succeeded() bool {
+	return r.Err == nil
+}
@@ -0,0 +1,29 @@
+package dto
+
+// ExecutionOptions agrupa las opciones de línea de comandos que modifican
+// la forma en que se ejecuta un plan.
+type ExecutionOptions struct {
+	// RollbackOnFailure ejecuta, en orden inverso, los comandos de rollback de
+	// los pasos completados en esta ejecución cuando un paso posterior falla.
+	RollbackOnFailure bool
+
+	// TemplateDir, si no está vacío, reemplaza la plantilla del proyecto por un
+	// directorio local durante esta ejecución, sin clonarla.
+	TemplateDir string
+
+	// Variables son los valores de --var y --var-file. Tienen prioridad sobre
+	// cualquier otro origen, incluidas las salidas de los pasos.
+	Variables map[string]string
+
+	// VariableOrigins indica, por nombre, qué flag o archivo fijó cada valor de
+	// Variables, p. ej. "--var" o "--var-file overrides.yaml".
+	VariableOrigins map[string]string
+
+	// AssumeYes omite la confirmación de los ambientes protegidos, p. ej. en CI.
+	AssumeYes bool
+
+	// Confirm pide al usuario que escriba el nombre de un ambiente protegido y
+	// devuelve lo que escribió. Si es nil y no se usa AssumeYes, la ejecución de un
+	// ambiente protegido se rechaza.
+	Confirm func(environment string) (string, error)
+}
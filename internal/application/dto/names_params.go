@@ -3,6 +3,7 @@ package dto
 type NamesParams struct {
 	projectName    string
 	repositoryName string
+	environment    string
 }
 
 func NewNamesParams(projectName, repositoryName string) NamesParams {
@@ -19,3 +20,14 @@ func (r *NamesParams) ProjectName() string {
 func (r *NamesParams) RepositoryName() string {
 	return r.repositoryName
 }
+
+// WithEnvironment separa el log de cada ambiente, para que las ejecuciones
+// simultáneas en varios ambientes no escriban el mismo archivo.
+func (r NamesParams) WithEnvironment(environment string) NamesParams {
+	r.environment = environment
+	return r
+}
+
+func (r *NamesParams) Environment() string {
+	return r.environment
+}
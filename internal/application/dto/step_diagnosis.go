@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// StepDiagnosis explica por qué un paso se volverá a ejecutar en un entorno,
+// comparando sus huellas actuales con la entrada de estado más reciente.
+type StepDiagnosis struct {
+	Step        string
+	Environment string
+	// Rerun es false si alguna entrada de estado coincide y el paso se omitirá.
+	Rerun bool
+	// HasBaseline es false si el paso no tiene estado guardado con el que comparar.
+	HasBaseline         bool
+	BaselineEnvironment string
+	BaselineCreatedAt   time.Time
+	// Changed son las huellas que difieren de la entrada, p. ej. "código".
+	Changed []string
+	// Files y Variables quedan a nil si la entrada se guardó sin manifiesto.
+	Files     *ManifestChanges
+	Variables *ManifestChanges
+}
+
+// ManifestChanges son los archivos o variables añadidos, borrados y modificados.
+type ManifestChanges struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
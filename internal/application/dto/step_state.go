@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// StepState son las entradas de la tabla de estado de un paso.
+type StepState struct {
+	Step    string
+	Entries []StateEntryStatus
+}
+
+// StateEntryStatus es una entrada de la tabla de estado comparada con las huellas
+// actuales del paso en su entorno.
+type StateEntryStatus struct {
+	Environment string
+	CreatedAt   time.Time
+	Code        string
+	Instruction string
+	Vars        string
+	// Compared indica si se pudieron calcular las huellas actuales; si no,
+	// CompareError explica por qué y Changed queda vacío.
+	Compared     bool
+	CompareError string
+	// Changed son las huellas que difieren de las actuales, p. ej. "código".
+	Changed []string
+}
@@ -0,0 +1,17 @@
+package dto
+
+// StorageMigration resume la conversión de las tablas de estado y los archivos de
+// variables de la raíz de vex al formato actual.
+type StorageMigration struct {
+	// Migrated son las rutas de los archivos convertidos.
+	Migrated []string
+	// Current cuenta los archivos que ya estaban en el formato actual.
+	Current int
+	Failed  []StorageMigrationFailure
+}
+
+// StorageMigrationFailure es un archivo que no se pudo convertir.
+type StorageMigrationFailure struct {
+	Path  string
+	Error string
+}
@@ -0,0 +1,8 @@
+package dto
+
+// StoredVariables son las salidas guardadas de un paso en un entorno o en 'shared'.
+type StoredVariables struct {
+	Scope     string
+	Step      string
+	Variables map[string]string
+}
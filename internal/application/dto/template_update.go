@@ -0,0 +1,16 @@
+package dto
+
+// TemplateUpdate describe el cambio del commit bloqueado de la plantilla.
+type TemplateUpdate struct {
+	URL       string
+	Ref       string
+	OldCommit string
+	NewCommit string
+	// Commits son los commits incorporados, del más reciente al más antiguo.
+	Commits []string
+}
+
+// Changed indica si el commit bloqueado cambió.
+func (u TemplateUpdate) Changed() bool {
+	return u.OldCommit != u.NewCommit
+}
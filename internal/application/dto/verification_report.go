@@ -0,0 +1,32 @@
+package dto
+
+// VerificationCheck es el resultado de una comprobación de `vex verify`.
+// Una comprobación omitida, como la de un artefacto sin copia local, no se hizo.
+type VerificationCheck struct {
+	Name    string
+	Passed  bool
+	Skipped bool
+	Detail  string
+}
+
+// VerificationReport reúne las comprobaciones hechas sobre una declaración de procedencia.
+type VerificationReport struct {
+	StatementPath string
+	Environment   string
+	Version       string
+	Checks        []VerificationCheck
+}
+
+// Passed indica si todas las comprobaciones fueron exitosas. Las omitidas solo se
+// aceptan si allowSkipped es true.
+func (r VerificationReport) Passed(allowSkipped bool) bool {
+	for _, check := range r.Checks {
+		if check.Skipped && allowSkipped {
+			continue
+		}
+		if !check.Passed {
+			return false
+		}
+	}
+	return true
+}
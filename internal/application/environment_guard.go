@@ -0,0 +1,116 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	defVos "github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	relVos "github.com/jairoprogramador/vex/internal/domain/release/vos"
+	verVos "github.com/jairoprogramador/vex/internal/domain/versioning/vos"
+)
+
+// maxListedChanges limita los archivos con cambios que se muestran en el error.
+const maxListedChanges = 5
+
+// guardEnvironment aplica las reglas de un ambiente protegido antes de ejecutar el
+// plan. Un rollback o una promoción despliegan una revisión ya desplegada, así que
+// solo se les pide la confirmación.
+func (o *ExecutionOrchestrator) guardEnvironment(
+	ctx context.Context, run runRequest, env defVos.EnvironmentDefinition) error {
+	if !env.IsProtected() {
+		return nil
+	}
+
+	if run.kind == relVos.Execution {
+		status, err := o.gitRepository.GetWorkingTreeStatus(ctx, run.projectPath, env.Remote())
+		if err != nil {
+			return fmt.Errorf("no se pudo comprobar el repositorio para el ambiente protegido '%s': %w", env.String(), err)
+		}
+		if violations := protectedEnvironmentViolations(env, status); len(violations) > 0 {
+			return fmt.Errorf("el ambiente '%s' está protegido y no se cumplen sus reglas:\n  - %s",
+				env.String(), strings.Join(violations, "\n  - "))
+		}
+	}
+
+	return confirmEnvironment(env, run.opts)
+}
+
+// protectedEnvironmentViolations devuelve, en orden, las reglas del ambiente que el
+// repositorio no cumple.
+func protectedEnvironmentViolations(env defVos.EnvironmentDefinition, status *verVos.WorkingTreeStatus) []string {
+	violations := make([]string, 0)
+
+	if status.IsDetached() {
+		if !hasAllowedRefAtHead(env, status) {
+			violations = append(violations, fmt.Sprintf(
+				"HEAD está desacoplado y no es la punta de una rama permitida de '%s' ni un tag permitido; "+
+					"cambia a una rama antes de ejecutar", env.Remote()))
+		}
+	} else if !env.IsBranchAllowed(status.Branch) {
+		violations = append(violations, fmt.Sprintf("la rama '%s' no está permitida (ramas permitidas: %s)",
+			status.Branch, strings.Join(env.AllowedBranches(), ", ")))
+	}
+
+	if !status.IsClean() {
+		listed := status.ChangedFiles
+		suffix := ""
+		if len(listed) > maxListedChanges {
+			suffix = fmt.Sprintf(" y %d más", len(listed)-maxListedChanges)
+			listed = listed[:maxListedChanges]
+		}
+		violations = append(violations, fmt.Sprintf("hay cambios sin confirmar: %s%s", strings.Join(listed, ", "), suffix))
+	}
+
+	// Con HEAD desacoplado, la punta de una rama remota ya está publicada y un tag
+	// se da por publicado: es lo que CI descarga del remoto.
+	switch {
+	case status.IsDetached():
+	case status.RemoteBranch == "":
+		violations = append(violations, fmt.Sprintf("la rama '%s' no existe en el remoto '%s'; haz push antes de ejecutar",
+			status.Branch, env.Remote()))
+	case !status.Pushed:
+		violations = append(violations, fmt.Sprintf("el commit %s no está en '%s'; haz push antes de ejecutar",
+			shortHash(status.Head), status.RemoteBranch))
+	}
+	return violations
+}
+
+// hasAllowedRefAtHead indica si, con HEAD desacoplado, alguna rama remota o tag en HEAD
+// cumple los patrones de ramas permitidas del ambiente.
+func hasAllowedRefAtHead(env defVos.EnvironmentDefinition, status *verVos.WorkingTreeStatus) bool {
+	for _, refs := range [][]string{status.RemoteBranchesAtHead, status.TagsAtHead} {
+		for _, ref := range refs {
+			if env.IsBranchAllowed(ref) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func confirmEnvironment(env defVos.EnvironmentDefinition, opts appDto.ExecutionOptions) error {
+	if opts.AssumeYes {
+		return nil
+	}
+	if opts.Confirm == nil {
+		return fmt.Errorf("el ambiente '%s' está protegido: confirma la ejecución de forma interactiva o usa --yes", env.String())
+	}
+
+	typed, err := opts.Confirm(env.String())
+	if err != nil {
+		return fmt.Errorf("ejecución en el ambiente protegido '%s' cancelada: %w", env.String(), err)
+	}
+	if strings.TrimSpace(typed) != env.String() {
+		return fmt.Errorf("el nombre escrito no coincide con el ambiente protegido '%s'; ejecución cancelada", env.String())
+	}
+	return nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
@@ -0,0 +1,97 @@
+package application
+
+import (
+	"errors"
+	"testing"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	defVos "github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	verVos "github.com/jairoprogramador/vex/internal/domain/versioning/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func protectedProd(t *testing.T) defVos.EnvironmentDefinition {
+	env, err := defVos.NewEnvironment("prod", "Producción",
+		defVos.WithProtected(true), defVos.WithAllowedBranches([]string{"main", "release/*"}))
+	require.NoError(t, err)
+	return env
+}
+
+func TestProtectedEnvironmentViolations(t *testing.T) {
+	env := protectedProd(t)
+
+	t.Run("should accept a clean and pushed allowed branch", func(t *testing.T) {
+		status := &verVos.WorkingTreeStatus{
+			Branch: "release/1.2", Head: "abcdef0123456789", RemoteBranch: "origin/release/1.2", Pushed: true,
+		}
+
+		assert.Empty(t, protectedEnvironmentViolations(env, status))
+	})
+
+	t.Run("should report every violated rule", func(t *testing.T) {
+		status := &verVos.WorkingTreeStatus{
+			Branch:       "feature/login",
+			Head:         "abcdef0123456789",
+			ChangedFiles: []string{"a.go", "b.go", "c.go", "d.go", "e.go", "f.go", "g.go"},
+			RemoteBranch: "origin/feature/login",
+		}
+
+		violations := protectedEnvironmentViolations(env, status)
+
+		require.Len(t, violations, 3)
+		assert.Contains(t, violations[0], "la rama 'feature/login' no está permitida")
+		assert.Contains(t, violations[1], "a.go, b.go, c.go, d.go, e.go y 2 más")
+		assert.Contains(t, violations[2], "el commit abcdef01 no está en 'origin/feature/login'")
+	})
+
+	t.Run("should require the branch to exist in the remote", func(t *testing.T) {
+		status := &verVos.WorkingTreeStatus{Branch: "main", Head: "abcdef0123456789"}
+
+		violations := protectedEnvironmentViolations(env, status)
+
+		require.Len(t, violations, 1)
+		assert.Contains(t, violations[0], "no existe en el remoto 'origin'")
+	})
+
+	t.Run("should reject a detached HEAD without an allowed branch or tag", func(t *testing.T) {
+		status := &verVos.WorkingTreeStatus{
+			Head: "abcdef0123456789", RemoteBranchesAtHead: []string{"feature/login"}, TagsAtHead: []string{"nightly"},
+		}
+
+		violations := protectedEnvironmentViolations(env, status)
+
+		require.Len(t, violations, 1)
+		assert.Contains(t, violations[0], "HEAD está desacoplado")
+	})
+
+	t.Run("should accept a detached HEAD at the tip of an allowed remote branch", func(t *testing.T) {
+		status := &verVos.WorkingTreeStatus{Head: "abcdef0123456789", RemoteBranchesAtHead: []string{"main"}}
+
+		assert.Empty(t, protectedEnvironmentViolations(env, status))
+	})
+
+	t.Run("should accept a detached HEAD at an allowed tag", func(t *testing.T) {
+		tagged, err := defVos.NewEnvironment("prod", "Producción",
+			defVos.WithProtected(true), defVos.WithAllowedBranches([]string{"main", "v*"}))
+		require.NoError(t, err)
+		status := &verVos.WorkingTreeStatus{Head: "abcdef0123456789", TagsAtHead: []string{"v1.2.0"}}
+
+		assert.Empty(t, protectedEnvironmentViolations(tagged, status))
+	})
+}
+
+func TestConfirmEnvironment(t *testing.T) {
+	env := protectedProd(t)
+	typing := func(text string) func(string) (string, error) {
+		return func(string) (string, error) { return text, nil }
+	}
+
+	assert.NoError(t, confirmEnvironment(env, appDto.ExecutionOptions{AssumeYes: true}))
+	assert.NoError(t, confirmEnvironment(env, appDto.ExecutionOptions{Confirm: typing("prod")}))
+	assert.ErrorContains(t, confirmEnvironment(env, appDto.ExecutionOptions{Confirm: typing("staging")}), "no coincide")
+	assert.ErrorContains(t, confirmEnvironment(env, appDto.ExecutionOptions{}), "--yes")
+	assert.ErrorContains(t, confirmEnvironment(env, appDto.ExecutionOptions{
+		Confirm: func(string) (string, error) { return "", errors.New("interrupt") },
+	}), "cancelada")
+}
@@ -2,17 +2,31 @@ package application
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
 	defAgg "github.com/jairoprogramador/vex/internal/domain/definition/aggregates"
+	defEnt "github.com/jairoprogramador/vex/internal/domain/definition/entities"
 	defPrt "github.com/jairoprogramador/vex/internal/domain/definition/ports"
 	defVos "github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	exeEnt "github.com/jairoprogramador/vex/internal/domain/execution/entities"
 	exePrt "github.com/jairoprogramador/vex/internal/domain/execution/ports"
 	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	logAgg "github.com/jairoprogramador/vex/internal/domain/logger/aggregates"
 	proAgg "github.com/jairoprogramador/vex/internal/domain/project/aggregates"
-	proPrt "github.com/jairoprogramador/vex/internal/domain/project/ports"
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	relPrt "github.com/jairoprogramador/vex/internal/domain/release/ports"
+	relVos "github.com/jairoprogramador/vex/internal/domain/release/vos"
 	staPrt "github.com/jairoprogramador/vex/internal/domain/state/ports"
 	staVos "github.com/jairoprogramador/vex/internal/domain/state/vos"
 	verPrt "github.com/jairoprogramador/vex/internal/domain/versioning/ports"
+	verVos "github.com/jairoprogramador/vex/internal/domain/versioning/vos"
 	worAgg "github.com/jairoprogramador/vex/internal/domain/workspace/aggregates"
 )
 
@@ -23,15 +37,23 @@ type ExecutionOrchestrator struct {
 	rootVexPath       string
 	projectSvc        *ProjectService
 	workspaceSvc      *WorkspaceService
-	gitCloner         proPrt.ClonerTemplate
+	templateSvc       *TemplateService
 	versionCalculator verPrt.VersionCalculator
 	planBuilder       defPrt.PlanBuilder
 	fingerprintSvc    staPrt.FingerprintService
 	stateManager      staPrt.StateManager
+	manifestKeys      staPrt.ManifestKeyRepository
 	stepExecutor      exePrt.StepExecutor
 	copyWorkdir       exePrt.CopyWorkdir
 	varsRepository    exePrt.VarsRepository
 	gitRepository     verPrt.GitRepository
+	releaseRepository relPrt.ReleaseRepository
+	provenanceSvc     *ProvenanceService
+	loggerSvc         *LoggerService
+	toolVersion       string
+	// locks protege los archivos compartidos entre ambientes cuando el plan se
+	// ejecuta en varios ambientes a la vez.
+	locks *keyedMutex
 }
 
 // NewExecutionOrchestrator crea una nueva instancia del orquestador.
@@ -40,70 +62,162 @@ func NewExecutionOrchestrator(
 	rootVexPath string,
 	projectSvc *ProjectService,
 	workspaceSvc *WorkspaceService,
-	gitCloner proPrt.ClonerTemplate,
+	templateSvc *TemplateService,
 	versionCalculator verPrt.VersionCalculator,
 	planBuilder defPrt.PlanBuilder,
 	fingerprintSvc staPrt.FingerprintService,
 	stateManager staPrt.StateManager,
+	manifestKeys staPrt.ManifestKeyRepository,
 	stepExecutor exePrt.StepExecutor,
 	copyWorkdir exePrt.CopyWorkdir,
 	varsRepository exePrt.VarsRepository,
 	gitRepository verPrt.GitRepository,
+	releaseRepository relPrt.ReleaseRepository,
+	provenanceSvc *ProvenanceService,
+	loggerSvc *LoggerService,
+	toolVersion string,
 ) *ExecutionOrchestrator {
 	return &ExecutionOrchestrator{
 		projectPath:       projectPath,
 		rootVexPath:       rootVexPath,
 		projectSvc:        projectSvc,
 		workspaceSvc:      workspaceSvc,
-		gitCloner:         gitCloner,
+		templateSvc:       templateSvc,
 		versionCalculator: versionCalculator,
 		planBuilder:       planBuilder,
 		fingerprintSvc:    fingerprintSvc,
 		stateManager:      stateManager,
+		manifestKeys:      manifestKeys,
 		stepExecutor:      stepExecutor,
 		copyWorkdir:       copyWorkdir,
 		varsRepository:    varsRepository,
 		gitRepository:     gitRepository,
+		releaseRepository: releaseRepository,
+		provenanceSvc:     provenanceSvc,
+		loggerSvc:         loggerSvc,
+		toolVersion:       toolVersion,
+		locks:             newKeyedMutex(),
 	}
 }
 
 // ExecutePlan es el caso de uso principal que ejecuta un plan de despliegue.
-func (o *ExecutionOrchestrator) ExecutePlan(ctx context.Context, stepName, envName string) error {
+func (o *ExecutionOrchestrator) ExecutePlan(
+	ctx context.Context, stepName, envName string, opts appDto.ExecutionOptions) error {
+	return o.executePlan(ctx, runRequest{
+		projectPath: o.projectPath,
+		stepName:    stepName,
+		envName:     envName,
+		opts:        opts,
+		kind:        relVos.Execution,
+	})
+}
+
+// runRequest describe una ejecución concreta del plan. Las ejecuciones normales
+// calculan versión y commit a partir del proyecto; un rollback o una promoción los
+// fijan a los de la ejecución que se restaura o se promueve.
+type runRequest struct {
+	projectPath string
+	stepName    string
+	envName     string
+	opts        appDto.ExecutionOptions
+	kind        relVos.Kind
+	version     *verVos.Version
+	commit      *verVos.Commit
+	rollbackOf  string
+	overrides   exeVos.VariableSet
+	// restored son las variables vigentes en la ejecución que se revierte. Son solo
+	// valores iniciales: las salidas de los pasos de esta ejecución las reemplazan.
+	restored exeVos.VariableSet
+	// restoredSecrets son, por paso, las variables secretas con las que se ejecutó
+	// en la ejecución que se revierte. Su valor no se registra y hay que darlo de nuevo.
+	restoredSecrets map[string][]string
+	// promotedFrom y reusedOutputs solo se usan en una promoción: los pasos de
+	// reusedOutputs no se ejecutan y aportan las salidas registradas en el origen.
+	promotedFrom  string
+	reusedOutputs map[string]exeVos.VariableSet
+}
+
+func (o *ExecutionOrchestrator) executePlan(ctx context.Context, run runRequest) (runErr error) {
+	stepName := run.stepName
+	opts := run.opts
+	// Las variables de la línea de comandos se imponen incluso a las restauradas.
+	run.overrides = run.overrides.Clone()
+	run.overrides.AddAll(commandLineVariables(opts))
+
 	// 1. Inicializar, Cargar y Clonar
-	project, err := o.loadProject(ctx, o.projectPath)
+	project, err := o.loadProject(ctx, run.projectPath)
 	if err != nil {
 		return err
 	}
-	workspace, err := o.loadWorkspace(project, o.rootVexPath)
+	workspace, err := o.loadWorkspace(project, o.rootVexPath, opts.TemplateDir)
 	if err != nil {
 		return err
 	}
 
-	templateLocalPath := workspace.TemplatePath()
-	err = o.cloneTemplate(ctx, project, templateLocalPath)
+	err = o.cloneTemplate(ctx, project, workspace, run.projectPath)
 	if err != nil {
 		return err
 	}
 
-	planDef, err := o.buildPlan(ctx, templateLocalPath, stepName, envName)
+	planDef, err := o.buildPlan(ctx, project, workspace, stepName, run.envName)
 	if err != nil {
 		return err
 	}
-
-	version, commit, err := o.versionCalculator.CalculateNextVersion(ctx, o.projectPath, false)
-	if err != nil {
+	if err := checkOutputCollisions(planDef); err != nil {
+		return err
+	}
+	if err := o.guardEnvironment(ctx, run, planDef.Environment()); err != nil {
 		return err
 	}
 
+	version, commit := run.version, run.commit
+	if version == nil || commit == nil {
+		version, commit, err = o.versionCalculator.CalculateNextVersion(ctx, run.projectPath, false)
+		if err != nil {
+			return err
+		}
+	}
+
 	environment := planDef.Environment().String()
 
 	projectVars := o.prepareProjectVariables(project)
 	othersVars := o.prepareOthersVariables(
-		environment, o.projectPath, version.String(), commit.String())
+		environment, run.projectPath, version.String(), commit.String())
+
+	// Las variables restauradas reemplazan a las salidas guardadas, que son las de
+	// la versión que se revierte, hasta que un paso de esta ejecución las produzca.
+	restored := run.restored.Clone()
 
 	cumulativeVars := make(exeVos.VariableSet)
 	cumulativeVars.AddAll(projectVars)
 	cumulativeVars.AddAll(othersVars)
+	cumulativeVars.AddAll(restored)
+	cumulativeVars.AddAll(run.overrides)
+
+	release, err := relAgg.NewRelease(
+		environment, stepName, version.String(), commit.String(), run.kind,
+		relAgg.WithTemplateSources(o.resolveTemplateSources(ctx, project, workspace)...),
+		relAgg.WithToolVersion(o.toolVersion),
+		relAgg.WithOverrides(exeVos.NewVariableSetFromMap(opts.Variables).Redacted()))
+	if err != nil {
+		return err
+	}
+	release.MarkAsRollbackOf(run.rollbackOf)
+	release.MarkAsPromotionOf(run.promotedFrom)
+	runLog := logAgg.NewLogger(map[string]string{
+		"environment": environment, "step": stepName, "version": version.String(), "commit": commit.String(),
+	}, release.ID())
+	runLog.Start()
+	recorder := &runRecorder{
+		release:   release,
+		loggerSvc: o.loggerSvc,
+		log:       runLog,
+		names:     runLogNames(project, environment),
+	}
+	defer func() {
+		recorder.finish()
+		o.recordRelease(workspace, release, runErr, cumulativeVars)
+	}()
 
 	fmt.Println("Iniciando la ejecución del plan...")
 	fmt.Printf("  - Entorno: %s\n", environment)
@@ -111,113 +225,331 @@ func (o *ExecutionOrchestrator) ExecutePlan(ctx context.Context, stepName, envNa
 	fmt.Printf("  - Commit: %s\n", commit.String())
 
 	// 3. Bucle de Ejecución Paso a Paso
+	completedSteps := make([]completedStep, 0, len(planDef.Steps()))
 	for _, stepDef := range planDef.Steps() {
 
 		fmt.Printf("Ejecutando paso %s ...\n", stepDef.NameDef().Name())
+		recorder.startStep(stepDef.NameDef().Name())
+		if err := release.StartStep(stepDef.NameDef().Name()); err != nil {
+			return o.abortPlan(ctx, err, completedSteps, opts, recorder)
+		}
 
-		fingerprints, err := o.generateStepFingerprints(o.projectPath, environment, workspace, stepDef.NameDef())
+		if reused, isReused := run.reusedOutputs[stepDef.NameDef().Name()]; isReused {
+			fmt.Printf("  - Paso '%s' reutiliza las salidas del ambiente de origen. Omitiendo.\n", stepDef.NameDef().Name())
+			cumulativeVars.AddAll(reused)
+			cumulativeVars.AddAll(reused.Namespaced(stepDef.NameDef().Name()))
+			cumulativeVars.AddAll(run.overrides)
+			release.ReuseStep(reused.ToStringMap())
+			recorder.skipStep()
+			continue
+		}
+
+		// Se calcula antes de cargar las salidas guardadas del propio paso, que no
+		// deben decidir si el paso se vuelve a ejecutar.
+		fingerprints, err := o.generateStepFingerprints(run.projectPath, workspace, environment, stepDef, cumulativeVars, run.overrides)
 		if err != nil {
-			return fmt.Errorf("error al generar fingerprint para el paso '%s': %w", stepDef.NameDef().Name(), err)
+			return o.abortPlan(ctx, fmt.Errorf("error al generar fingerprint para el paso '%s': %w", stepDef.NameDef().Name(), err), completedSteps, opts, recorder)
 		}
+		release.RecordFingerprints(relVos.NewStepFingerprints(
+			fingerprints.Code().String(), fingerprints.Instruction().String(),
+			fingerprints.Vars().String(), fingerprints.Environment().String()))
 
 		stateTablePath, err := workspace.StateTablePath(stepDef.NameDef().Name())
 		if err != nil {
-			return fmt.Errorf("error al obtener la ruta del estado del paso '%s': %w", stepDef.NameDef().Name(), err)
+			return o.abortPlan(ctx, fmt.Errorf("error al obtener la ruta del estado del paso '%s': %w", stepDef.NameDef().Name(), err), completedSteps, opts, recorder)
 		}
+		unlock := o.locks.Lock(stateTablePath)
+		warnFailedMigration(stateTablePath, o.stateManager.MigrateState)
 		hasChanged, err := o.stateManager.HasStateChanged(stateTablePath, fingerprints, staVos.NewCachePolicy(0))
+		unlock()
 		if err != nil {
-			return fmt.Errorf("error al comprobar el estado del paso '%s': %w", stepDef.NameDef().Name(), err)
+			return o.abortPlan(ctx, fmt.Errorf("error al comprobar el estado del paso '%s': %w", stepDef.NameDef().Name(), err), completedSteps, opts, recorder)
 		}
 
 		varsStepPath := workspace.VarsFilePath(environment, stepDef.NameDef().Name())
+		unlock = o.locks.Lock(varsStepPath)
+		warnFailedMigration(varsStepPath, o.varsRepository.Migrate)
 		varsStep, err := o.varsRepository.Get(varsStepPath)
+		unlock()
 		if err != nil {
-			return fmt.Errorf("error al obtener las variables del paso '%s' en el entorno '%s': %w", stepDef.NameDef().Name(), environment, err)
+			return o.abortPlan(ctx, fmt.Errorf("error al obtener las variables del paso '%s' en el entorno '%s': %w", stepDef.NameDef().Name(), environment, err), completedSteps, opts, recorder)
 		}
-		cumulativeVars.AddAll(varsStep)
+		storedStepVars := varsStep.WithSource(
+			exeVos.NewVariableSource(exeVos.StoredSource, environment+"/"+stepDef.NameDef().Name()))
+		cumulativeVars.AddAll(storedStepVars)
+		cumulativeVars.AddAll(storedStepVars.Namespaced(stepDef.NameDef().Name()))
+		cumulativeVars.AddAll(restored)
+		cumulativeVars.AddAll(run.overrides)
 
 		varsSharedPath := workspace.VarsFilePath("shared", stepDef.NameDef().Name())
+		unlock = o.locks.Lock(varsSharedPath)
+		warnFailedMigration(varsSharedPath, o.varsRepository.Migrate)
 		varsShared, err := o.varsRepository.Get(varsSharedPath)
+		unlock()
 		if err != nil {
-			return fmt.Errorf("error al obtener las variables del paso '%s' en el entorno 'shared': %w", stepDef.NameDef().Name(), err)
+			return o.abortPlan(ctx, fmt.Errorf("error al obtener las variables del paso '%s' en el entorno 'shared': %w", stepDef.NameDef().Name(), err), completedSteps, opts, recorder)
 		}
-		cumulativeVars.AddAll(varsShared)
-
-		if !hasChanged {
+		storedSharedVars := varsShared.WithSource(
+			exeVos.NewVariableSource(exeVos.StoredSource, exeVos.SharedScope+"/"+stepDef.NameDef().Name()))
+		cumulativeVars.AddAll(storedSharedVars)
+		cumulativeVars.AddAll(storedSharedVars.Namespaced(stepDef.NameDef().Name()))
+		cumulativeVars.AddAll(restored)
+		cumulativeVars.AddAll(run.overrides)
+
+		if !hasChanged && !run.forcesExecution() {
 			fmt.Printf("  - Paso '%s' ya fue ejecutado en este entorno. Omitiendo.\n", stepDef.NameDef().Name())
+			release.SkipStep()
+			recorder.cacheStep("ya fue ejecutado en este entorno")
 			continue // Saltar al siguiente paso
 		}
 
 		envStepPath := workspace.ScopeWorkdirPath(planDef.Environment().String(), stepDef.NameDef().Name())
-		err = o.copyWorkdir.Copy(ctx, workspace.StepTemplatePath(stepDef.NameDef().FullName()), envStepPath, false)
+		sharedStepPath := workspace.ScopeWorkdirPath(exeVos.SharedScope, stepDef.NameDef().Name())
+		execStep, err := mapToExecutionStep(stepDef, envStepPath, sharedStepPath, run.overrides)
 		if err != nil {
-			return fmt.Errorf("error al copiar el paso '%s' al workspace: %w", envStepPath, err)
+			return o.abortPlan(ctx, fmt.Errorf("error al mapear la definición del paso '%s': %w", stepDef.NameDef().Name(), err), completedSteps, opts, recorder)
 		}
 
-		sharedStepPath := workspace.ScopeWorkdirPath(exeVos.SharedScope, stepDef.NameDef().Name())
-		err = o.copyWorkdir.Copy(ctx, workspace.StepTemplatePath(stepDef.NameDef().FullName()), sharedStepPath, true)
-		if err != nil {
-			return fmt.Errorf("error al copiar el paso '%s' al workspace: %w", sharedStepPath, err)
+		if err := checkSecretInputs(stepDef, run.restoredSecrets[stepDef.NameDef().Name()], cumulativeVars); err != nil {
+			return o.abortPlan(ctx, err, completedSteps, opts, recorder)
 		}
 
-		execStep, err := mapToExecutionStep(stepDef, envStepPath, sharedStepPath)
+		unlock, err = o.prepareWorkdirs(ctx, stepDef, execStep)
 		if err != nil {
-			return fmt.Errorf("error al mapear la definición del paso '%s': %w", stepDef.NameDef().Name(), err)
+			return o.abortPlan(ctx, err, completedSteps, opts, recorder)
 		}
-
+		recorder.runStep()
 		execResult, err := o.stepExecutor.Execute(ctx, execStep, cumulativeVars)
+		unlock()
 		if err != nil {
-			return fmt.Errorf("la ejecución del paso '%s' falló: %w", stepDef.NameDef().Name(), err)
+			return o.abortPlan(ctx, fmt.Errorf("la ejecución del paso '%s' falló: %w", stepDef.NameDef().Name(), err), completedSteps, opts, recorder)
 		}
+		release.RecordVariables(variableTraces(execResult.Variables))
+		recorder.record(stepDef.NameDef().Name(), execResult.Hooks)
 		if execResult.Error != nil || execResult.Status == exeVos.Failure {
 			fmt.Println("--- Logs del fallo ---")
 			fmt.Println(execResult.Logs)
 			fmt.Println("--------------------")
-			return fmt.Errorf("el paso '%s' finalizó con error: %w", stepDef.NameDef().Name(), execResult.Error)
+			return o.abortPlan(ctx, fmt.Errorf("el paso '%s' finalizó con error: %w", stepDef.NameDef().Name(), execResult.Error), completedSteps, opts, recorder)
 		}
 
 		// 3c. Actualización de Variables y Estado
 		fmt.Printf("  - Paso '%s' completado:\n%s\n", stepDef.NameDef().Name(), execResult.Logs)
 		cumulativeVars.AddAll(execResult.OutputVars)
-
+		cumulativeVars.AddAll(execResult.OutputVars.Namespaced(stepDef.NameDef().Name()))
+		cumulativeVars.AddAll(run.overrides)
+		restored = withoutOutputs(restored, stepDef.NameDef().Name(), execResult.OutputVars)
+		release.CompleteStep(execResult.OutputVars.Redacted())
+		recorder.completeStep()
+
+		completed := completedStep{
+			execStep:       execStep,
+			stateTablePath: stateTablePath,
+			fingerprints:   fingerprints,
+			variables:      execResult.Variables,
+		}
+		// Solo se guardan las variables que cambiaron respecto a las guardadas.
 		outputSharedVars := execResult.OutputVars.Filter(func(v exeVos.OutputVar) bool {
 			return v.IsShared()
 		})
 		if !outputSharedVars.Equals(varsShared) {
-			err := o.varsRepository.Save(varsSharedPath, outputSharedVars)
-			if err != nil {
-				return fmt.Errorf("error al guardar las variables del paso '%s' del entorno '%s': %w", stepName, environment, err)
-			}
+			completed.outputs = append(completed.outputs, pendingVars{path: varsSharedPath, vars: outputSharedVars, scope: exeVos.SharedScope})
 		}
-
 		outputStepVars := execResult.OutputVars.Filter(func(v exeVos.OutputVar) bool {
 			return !v.IsShared()
 		})
 		if !outputStepVars.Equals(varsStep) {
-			err := o.varsRepository.Save(varsStepPath, outputStepVars)
-			if err != nil {
-				return fmt.Errorf("error al guardar las variables del paso '%s' del entorno '%s': %w", stepName, environment, err)
+			completed.outputs = append(completed.outputs, pendingVars{path: varsStepPath, vars: outputStepVars, scope: environment})
+		}
+		completedSteps = append(completedSteps, completed)
+
+		// Con rollback habilitado las salidas y el estado se guardan al final, de modo
+		// que un paso compensado no deje salidas de recursos ya revertidos ni quede
+		// registrado como ejecutado.
+		if !opts.RollbackOnFailure {
+			if err := o.persistStep(completed); err != nil {
+				return o.abortPlan(ctx, err, completedSteps, opts, recorder)
 			}
 		}
+	}
 
-		if err := o.stateManager.UpdateState(stateTablePath, fingerprints); err != nil {
-			// Esto es una advertencia. El flujo principal fue exitoso, pero el estado no se guardó.
-			fmt.Printf("ADVERTENCIA: no se pudo guardar el estado del paso '%s'. Se re-ejecutará la próxima vez. Error: %v\n", stepDef.NameDef().Name(), err)
+	if opts.RollbackOnFailure {
+		for _, completed := range completedSteps {
+			if err := o.persistStep(completed); err != nil {
+				return o.abortPlan(ctx, err, completedSteps, opts, recorder)
+			}
 		}
 	}
 
-	if stepName == "deploy" {
+	if stepName == relVos.DeployStep && run.kind == relVos.Execution {
 		// 4. Crear el tag del commit
-		err = o.gitRepository.CreateTagForCommit(ctx, o.projectPath, commit.String(), version.String())
+		unlock := o.locks.Lock(run.projectPath)
+		err = o.gitRepository.CreateTagForCommit(ctx, run.projectPath, commit.String(), version.String())
+		unlock()
 		if err != nil {
 			fmt.Printf("ADVERTENCIA: no se pudo crear el tag del commit. Error: %v\n", err)
 		}
 	}
 
+	if stepName == relVos.DeployStep {
+		// 5. Registrar la procedencia del despliegue
+		unlock := o.locks.Lock(o.rootVexPath)
+		attestationPath, err := o.provenanceSvc.Attest(ctx, workspace, release, run.projectPath, cumulativeVars)
+		unlock()
+		if err != nil {
+			fmt.Printf("ADVERTENCIA: no se pudo registrar la procedencia del despliegue. Error: %v\n", err)
+		} else {
+			fmt.Printf("  - Procedencia registrada en %s\n", attestationPath)
+		}
+	}
+
 	fmt.Println("¡Ejecución completada con éxito!")
 	return nil
 }
 
+// forcesExecution indica si los pasos deben ejecutarse aunque su estado no haya cambiado.
+// Un rollback vuelve a desplegar una versión que, por definición, ya fue ejecutada.
+func (r runRequest) forcesExecution() bool {
+	return r.kind == relVos.Rollback
+}
+
+func (o *ExecutionOrchestrator) recordRelease(
+	workspace *worAgg.Workspace, release *relAgg.Release, runErr error, vars exeVos.VariableSet) {
+	release.Finish(runErr, vars.Redacted())
+	if err := o.releaseRepository.Save(workspace.ReleasesDirPath(release.Environment()), release); err != nil {
+		fmt.Printf("ADVERTENCIA: no se pudo registrar la ejecución. Error: %v\n", err)
+	}
+}
+
+// resolveTemplateSources identifica las capas de la plantilla usadas en la ejecución,
+// empezando por la base. Si no se puede resolver el commit de una capa, el manifiesto
+// se registra sin él. Una capa local puede tener cambios sin confirmar, así que
+// tampoco se registra su commit.
+func (o *ExecutionOrchestrator) resolveTemplateSources(
+	ctx context.Context, project *proAgg.Project, workspace *worAgg.Workspace) []relVos.TemplateSource {
+
+	templates := project.Templates()
+	sources := make([]relVos.TemplateSource, 0, len(templates))
+	for i, layer := range workspace.TemplateLayers() {
+		layerPath := workspace.LayerPath(layer)
+		if layer.IsLocal() {
+			sources = append(sources, relVos.NewTemplateSource("file://"+filepath.ToSlash(layerPath), "", ""))
+			continue
+		}
+
+		sha := ""
+		templateCommit, err := o.gitRepository.GetLastCommit(ctx, layerPath)
+		if err != nil {
+			fmt.Printf("ADVERTENCIA: no se pudo resolver el commit de la plantilla '%s'. Error: %v\n", templates[i].URL(), err)
+		} else {
+			sha = templateCommit.String()
+		}
+		sources = append(sources, relVos.NewTemplateSource(templates[i].URL(), templates[i].Ref(), sha))
+	}
+	return sources
+}
+
+// withoutOutputs quita de vars las salidas de un paso, con y sin su espacio de nombres.
+func withoutOutputs(vars exeVos.VariableSet, stepName string, outputs exeVos.VariableSet) exeVos.VariableSet {
+	namespaced := outputs.Namespaced(stepName)
+	return vars.Filter(func(v exeVos.OutputVar) bool {
+		_, produced := outputs.Get(v.Name())
+		_, producedNamespaced := namespaced.Get(v.Name())
+		return !produced && !producedNamespaced
+	})
+}
+
+// completedStep recuerda lo necesario de un paso ejecutado con éxito en esta
+// ejecución para poder guardar sus salidas y su estado o compensarlo más tarde.
+type completedStep struct {
+	execStep       *exeEnt.Step
+	stateTablePath string
+	fingerprints   staVos.CurrentStateFingerprints
+	outputs        []pendingVars
+	// variables son las que el paso tenía al terminar, con las suyas propias ya
+	// resueltas. Su rollback se ejecuta con ellas y no con las de pasos posteriores,
+	// que pueden haber reemplazado una salida con el mismo nombre.
+	variables exeVos.VariableSet
+}
+
+// pendingVars son salidas de un paso pendientes de guardar en el archivo path.
+type pendingVars struct {
+	path  string
+	vars  exeVos.VariableSet
+	scope string
+}
+
+// persistStep guarda las salidas y el estado de un paso completado. Un error al
+// guardar las salidas detiene el plan; uno al guardar el estado solo se avisa.
+func (o *ExecutionOrchestrator) persistStep(completed completedStep) error {
+	for _, output := range completed.outputs {
+		unlock := o.locks.Lock(output.path)
+		err := o.varsRepository.Save(output.path, output.vars)
+		unlock()
+		if err != nil {
+			return fmt.Errorf("error al guardar las variables del paso '%s' del entorno '%s': %w",
+				completed.execStep.Name(), output.scope, err)
+		}
+	}
+	o.updateStepState(completed)
+	return nil
+}
+
+func (o *ExecutionOrchestrator) updateStepState(completed completedStep) {
+	unlock := o.locks.Lock(completed.stateTablePath)
+	defer unlock()
+	if err := o.stateManager.UpdateState(completed.stateTablePath, completed.fingerprints); err != nil {
+		// Esto es una advertencia. El flujo principal fue exitoso, pero el estado no se guardó.
+		fmt.Printf("ADVERTENCIA: no se pudo guardar el estado del paso '%s'. Se re-ejecutará la próxima vez. Error: %v\n", completed.execStep.Name(), err)
+	}
+}
+
+// abortPlan detiene el plan por un fallo y, si así se pidió, compensa en orden
+// inverso los pasos que se completaron en esta ejecución. El estado de esos
+// pasos no se actualiza: el error original es siempre el que se devuelve.
+func (o *ExecutionOrchestrator) abortPlan(
+	ctx context.Context,
+	cause error,
+	completedSteps []completedStep,
+	opts appDto.ExecutionOptions,
+	recorder *runRecorder) error {
+
+	recorder.failStep(cause)
+	if !opts.RollbackOnFailure || len(completedSteps) == 0 {
+		return cause
+	}
+
+	fmt.Println("Iniciando el rollback de los pasos completados...")
+	failedRollbacks := 0
+	for i := len(completedSteps) - 1; i >= 0; i-- {
+		execStep := completedSteps[i].execStep
+		vars := completedSteps[i].variables
+		if len(execStep.RollbackCommands()) == 0 {
+			fmt.Printf("  - Paso '%s': sin comandos de rollback.\n", execStep.Name())
+			continue
+		}
+
+		unlock := o.lockSharedWorkdir(execStep)
+		result, err := o.stepExecutor.Rollback(ctx, execStep, vars)
+		unlock()
+		if err == nil {
+			err = result.Error
+			recorder.record(execStep.Name(), result.Hooks)
+		}
+		if err != nil {
+			failedRollbacks++
+			fmt.Printf("  - Paso '%s': rollback fallido: %v\n", execStep.Name(), err)
+			continue
+		}
+		fmt.Printf("  - Paso '%s': rollback completado.\n", execStep.Name())
+	}
+
+	if failedRollbacks > 0 {
+		return fmt.Errorf("%w (además, %d rollback(s) fallaron)", cause, failedRollbacks)
+	}
+	return fmt.Errorf("%w (los pasos completados fueron revertidos)", cause)
+}
+
 func (o *ExecutionOrchestrator) loadProject(ctx context.Context, projectPath string) (*proAgg.Project, error) {
 	// 1. Cargar el Proyecto
 	project, err := o.projectSvc.Load(ctx, projectPath)
@@ -227,10 +559,10 @@ func (o *ExecutionOrchestrator) loadProject(ctx context.Context, projectPath str
 	return project, nil
 }
 
-func (o *ExecutionOrchestrator) loadWorkspace(project *proAgg.Project, rootVexPath string) (*worAgg.Workspace, error) {
+func (o *ExecutionOrchestrator) loadWorkspace(
+	project *proAgg.Project, rootVexPath, templateDir string) (*worAgg.Workspace, error) {
 	// 2. Crear el Workspace
-	workspace, err := o.workspaceSvc.NewWorkspace(
-		rootVexPath, project.Data().Name(), project.TemplateRepo().DirName())
+	workspace, err := o.workspaceSvc.ForProject(rootVexPath, project, templateDir)
 	if err != nil {
 		return nil, fmt.Errorf("error al cargar el workspace: %w", err)
 	}
@@ -238,10 +570,11 @@ func (o *ExecutionOrchestrator) loadWorkspace(project *proAgg.Project, rootVexPa
 }
 
 func (o *ExecutionOrchestrator) cloneTemplate(
-	ctx context.Context, project *proAgg.Project, templateLocalPath string) error {
-	// 3. Asegurar que el template está clonado
-	err := o.gitCloner.EnsureCloned(ctx, project.TemplateRepo().URL(),
-		project.TemplateRepo().Ref(), templateLocalPath)
+	ctx context.Context, project *proAgg.Project, workspace *worAgg.Workspace, projectPath string) error {
+	// 3. Asegurar que cada capa de la plantilla está clonada y en el commit bloqueado
+	unlock := o.locks.Lock(workspace.WorkspacePath())
+	defer unlock()
+	err := o.templateSvc.Sync(ctx, project, workspace, projectPath)
 	if err != nil {
 		return fmt.Errorf("no se pudo clonar el repositorio de plantillas: %w", err)
 	}
@@ -249,10 +582,24 @@ func (o *ExecutionOrchestrator) cloneTemplate(
 }
 
 func (o *ExecutionOrchestrator) buildPlan(
-	ctx context.Context, templateLocalPath, stepName, envName string) (*defAgg.ExecutionPlanDefinition, error) {
+	ctx context.Context,
+	project *proAgg.Project,
+	workspace *worAgg.Workspace,
+	stepName, envName string) (*defAgg.ExecutionPlanDefinition, error) {
 
-	// 4. Cargar la definición del plan desde el template clonado
-	planDef, err := o.planBuilder.Build(ctx, templateLocalPath, stepName, envName)
+	layers, err := templateLayers(project, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := projectVariableOverrides(project)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Cargar la definición del plan combinando las capas de la plantilla y las
+	// variables del proyecto
+	planDef, err := o.planBuilder.Build(ctx, layers, stepName, envName, overrides...)
 	if err != nil {
 		return nil, fmt.Errorf("error al cargar la definición: %w", err)
 	}
@@ -260,6 +607,90 @@ func (o *ExecutionOrchestrator) buildPlan(
 	return planDef, nil
 }
 
+// projectVariableOverrides devuelve las variables del proyecto que se aplican sobre
+// las de la plantilla: primero las de vexconfig.yaml y después las de vexconfig.local.yaml.
+func projectVariableOverrides(project *proAgg.Project) ([]defVos.VariableOverridesDefinition, error) {
+	projectVariables, err := defVos.NewVariableOverridesDefinition(
+		defVos.ProjectConfigLayer, project.Variables().Values())
+	if err != nil {
+		return nil, err
+	}
+	localVariables, err := defVos.NewVariableOverridesDefinition(
+		defVos.LocalConfigLayer, project.LocalVariables().Values())
+	if err != nil {
+		return nil, err
+	}
+	return []defVos.VariableOverridesDefinition{projectVariables, localVariables}, nil
+}
+
+// templateLayers nombra cada capa de la plantilla por su repositorio y referencia,
+// o por su ruta si es local, para poder mostrar de qué capa sale cada elemento del plan.
+func templateLayers(project *proAgg.Project, workspace *worAgg.Workspace) ([]defVos.LayerDefinition, error) {
+	templates := project.Templates()
+	layers := make([]defVos.LayerDefinition, 0, len(templates))
+	for i, layer := range workspace.TemplateLayers() {
+		layerPath := workspace.LayerPath(layer)
+		name := templates[i].URL() + "@" + templates[i].Ref()
+		if layer.IsLocal() {
+			name = layerPath
+		}
+		layerDef, err := defVos.NewLayerDefinition(name, layerPath)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layerDef)
+	}
+	return layers, nil
+}
+
+// prepareWorkdirs copia las fuentes del paso a sus workdirs. El workdir compartido
+// es el mismo para todos los ambientes y sus plantillas se interpolan con las
+// variables de cada uno: si algún comando del paso lo usa, queda bloqueado desde la
+// copia hasta que se llama a la función devuelta, al terminar el paso.
+func (o *ExecutionOrchestrator) prepareWorkdirs(
+	ctx context.Context, stepDef *defEnt.StepDefinition, execStep *exeEnt.Step) (func(), error) {
+
+	unlock := o.locks.Lock(execStep.WorkspaceShared())
+	if err := o.copyStepSources(ctx, stepDef, execStep.WorkspaceStep(), execStep.WorkspaceShared()); err != nil {
+		unlock()
+		return nil, err
+	}
+	if !execStep.UsesSharedWorkdir() {
+		unlock()
+		return func() {}, nil
+	}
+	return unlock, nil
+}
+
+// lockSharedWorkdir bloquea el workdir compartido del paso mientras se ejecuta, si
+// algún comando lo usa.
+func (o *ExecutionOrchestrator) lockSharedWorkdir(execStep *exeEnt.Step) func() {
+	if !execStep.UsesSharedWorkdir() {
+		return func() {}
+	}
+	return o.locks.Lock(execStep.WorkspaceShared())
+}
+
+// copyStepSources copia al workdir los archivos del paso de cada capa, en orden, de
+// modo que los archivos de un overlay reemplazan a los de la base con la misma ruta.
+// Quien la llama debe tener bloqueado el workdir compartido.
+func (o *ExecutionOrchestrator) copyStepSources(
+	ctx context.Context, stepDef *defEnt.StepDefinition, envStepPath, sharedStepPath string) error {
+
+	for _, source := range stepDef.SourcesDef() {
+		if source.StepDir() == "" {
+			continue
+		}
+		if err := o.copyWorkdir.Copy(ctx, source.StepDir(), envStepPath, false); err != nil {
+			return fmt.Errorf("error al copiar el paso '%s' al workspace: %w", envStepPath, err)
+		}
+		if err := o.copyWorkdir.Copy(ctx, source.StepDir(), sharedStepPath, true); err != nil {
+			return fmt.Errorf("error al copiar el paso '%s' al workspace: %w", sharedStepPath, err)
+		}
+	}
+	return nil
+}
+
 func (o *ExecutionOrchestrator) prepareProjectVariables(project *proAgg.Project) exeVos.VariableSet {
 	vars := exeVos.NewVariableSetFromMap(map[string]string{
 		"project_id":           project.ID().String()[:8],
@@ -267,7 +698,7 @@ func (o *ExecutionOrchestrator) prepareProjectVariables(project *proAgg.Project)
 		"project_organization": project.Data().Organization(),
 		"project_team":         project.Data().Team(),
 	})
-	return vars
+	return vars.WithSource(exeVos.NewVariableSource(exeVos.ProjectSource, defVos.ProjectConfigLayer))
 }
 
 func (o *ExecutionOrchestrator) prepareOthersVariables(environment, projectWorkdir, version, commit string) exeVos.VariableSet {
@@ -279,59 +710,205 @@ func (o *ExecutionOrchestrator) prepareOthersVariables(environment, projectWorkd
 		"project_workdir":       projectWorkdir,
 		"tool_name":             "vex",
 	})
+	return vars.WithSource(exeVos.NewVariableSource(exeVos.RuntimeSource, ""))
+}
+
+// checkOutputCollisions avisa de las salidas de un paso que ocultan las de un paso
+// anterior. Con strict_outputs en template.yaml, una colisión impide ejecutar el plan.
+func checkOutputCollisions(planDef *defAgg.ExecutionPlanDefinition) error {
+	collisions := planDef.OutputCollisions()
+	if len(collisions) == 0 {
+		return nil
+	}
+	if planDef.Settings().StrictOutputs() {
+		messages := make([]string, 0, len(collisions))
+		for _, collision := range collisions {
+			messages = append(messages, collision.String())
+		}
+		return fmt.Errorf("la plantilla usa strict_outputs y hay salidas repetidas:\n  - %s",
+			strings.Join(messages, "\n  - "))
+	}
+	for _, collision := range collisions {
+		fmt.Printf("ADVERTENCIA: %s\n", collision)
+	}
+	return nil
+}
+
+// commandLineVariables son las variables de --var y --var-file, atribuidas al flag o
+// archivo que las fijó.
+func commandLineVariables(opts appDto.ExecutionOptions) exeVos.VariableSet {
+	vars := exeVos.NewVariableSet()
+	for name, outputVar := range exeVos.NewVariableSetFromMap(opts.Variables) {
+		vars.Add(outputVar.WithSource(
+			exeVos.NewVariableSource(exeVos.CommandLineSource, opts.VariableOrigins[name])))
+	}
 	return vars
 }
 
-func (o *ExecutionOrchestrator) generateCodeFingerprint(projectPath string) (staVos.Fingerprint, error) {
-	codeFp, err := o.fingerprintSvc.FromDirectory(projectPath)
-	if err != nil {
-		return staVos.Fingerprint{}, fmt.Errorf("no se pudo generar el fingerprint para el proyecto: %w", err)
+// variableTraces convierte las variables de un paso en su cadena de resolución para
+// el manifiesto. Los valores de las variables secretas se ocultan en toda la cadena.
+func variableTraces(vars exeVos.VariableSet) []relVos.VariableTrace {
+	traces := make([]relVos.VariableTrace, 0, len(vars))
+	for name, outputVar := range vars {
+		chain := outputVar.Chain()
+		origins := make([]relVos.VariableOrigin, 0, len(chain))
+		for _, link := range chain {
+			value := link.Value()
+			if exeVos.IsSecretName(name) {
+				value = exeVos.RedactedValue
+			}
+			origins = append(origins, relVos.NewVariableOrigin(value, link.Source().String()))
+		}
+		traces = append(traces, relVos.NewVariableTrace(name, origins))
 	}
-	return codeFp, nil
+	sort.Slice(traces, func(i, j int) bool {
+		return traces[i].Name() < traces[j].Name()
+	})
+	return traces
 }
 
-func (o *ExecutionOrchestrator) generateInstructionFingerprint(templateInstPath string) (staVos.Fingerprint, error) {
-	codeFp, err := o.fingerprintSvc.FromDirectory(templateInstPath)
+// generateCodeFingerprint devuelve el fingerprint del proyecto y el manifiesto de
+// sus archivos del que se obtiene.
+func (o *ExecutionOrchestrator) generateCodeFingerprint(
+	projectPath string, scope staVos.FingerprintScope) (staVos.Fingerprint, staVos.Manifest, error) {
+	manifest, err := o.fingerprintSvc.DirectoryManifest(projectPath, scope)
 	if err != nil {
-		return staVos.Fingerprint{}, fmt.Errorf("no se pudo generar el fingerprint para las instrucciones: %w", err)
+		return staVos.Fingerprint{}, staVos.Manifest{}, fmt.Errorf("no se pudo generar el fingerprint para el proyecto: %w", err)
 	}
-	return codeFp, nil
+	codeFp, err := o.fingerprintSvc.FromManifest(manifest)
+	if err != nil {
+		return staVos.Fingerprint{}, staVos.Manifest{}, fmt.Errorf("no se pudo generar el fingerprint para el proyecto: %w", err)
+	}
+	return codeFp, manifest, nil
 }
 
-func (o *ExecutionOrchestrator) generateVarsFingerprint(templateVarsPath string) (staVos.Fingerprint, error) {
-	codeFp, err := o.fingerprintSvc.FromFile(templateVarsPath)
+func (o *ExecutionOrchestrator) generateInstructionFingerprint(templateInstPath string) (staVos.Fingerprint, error) {
+	codeFp, err := o.fingerprintSvc.FromDirectory(templateInstPath)
 	if err != nil {
-		return staVos.Fingerprint{}, fmt.Errorf("no se pudo generar el fingerprint para las variables: %w", err)
+		return staVos.Fingerprint{}, fmt.Errorf("no se pudo generar el fingerprint para las instrucciones: %w", err)
 	}
 	return codeFp, nil
 }
 
+// generateStepFingerprints calcula las huellas de un paso. inputs son las variables
+// disponibles antes de ejecutarlo (las del proyecto, las salidas de los pasos
+// anteriores y las de la línea de comandos) y overrides, las que se imponen a las
+// del propio paso.
 func (o *ExecutionOrchestrator) generateStepFingerprints(
-	projectPath, environment string,
+	projectPath string,
 	workspace *worAgg.Workspace,
-	stepDef defVos.StepNameDefinition) (staVos.CurrentStateFingerprints, error) {
+	environment string,
+	stepDef *defEnt.StepDefinition,
+	inputs, overrides exeVos.VariableSet) (staVos.CurrentStateFingerprints, error) {
 
 	envFp, err := staVos.NewEnvironment(environment)
 	if err != nil {
 		return staVos.CurrentStateFingerprints{}, err
 	}
 
-	codeFp, err := o.generateCodeFingerprint(projectPath)
+	scopeDef := stepDef.FingerprintScopeDef()
+	codeFp, codeManifest, err := o.generateCodeFingerprint(
+		projectPath, staVos.NewFingerprintScope(scopeDef.Include(), scopeDef.Exclude()))
 	if err != nil {
 		return staVos.CurrentStateFingerprints{}, err
 	}
 
-	instructionPath := workspace.StepTemplatePath(stepDef.FullName())
-	instFp, err := o.generateInstructionFingerprint(instructionPath)
+	instFps := make([]staVos.Fingerprint, 0, len(stepDef.SourcesDef()))
+	for _, source := range stepDef.SourcesDef() {
+		if source.StepDir() != "" {
+			instFp, err := o.generateInstructionFingerprint(source.StepDir())
+			if err != nil {
+				return staVos.CurrentStateFingerprints{}, err
+			}
+			instFps = append(instFps, instFp)
+		}
+	}
+
+	secretKey, err := o.manifestKey(workspace)
 	if err != nil {
 		return staVos.CurrentStateFingerprints{}, err
 	}
+	varsManifest := variablesManifest(stepVariables(stepDef, inputs, overrides), secretKey)
+
+	return staVos.NewCurrentStateFingerprints(
+		codeFp, staVos.CombineFingerprints(instFps...), variablesFingerprint(varsManifest.Hashes()), envFp).
+		WithManifests(codeManifest, varsManifest), nil
+}
 
-	varsPath := workspace.VarsTemplatePath(environment, stepDef.Name())
-	varsFp, err := o.generateVarsFingerprint(varsPath)
+// stepVariables es la forma canónica de las variables con las que se ejecuta un paso:
+// las disponibles antes de ejecutarlo con las del paso encima y, sobre todas, las de
+// la línea de comandos, como hace la ejecución. Los valores del paso se toman sin
+// interpolar, porque lo que interpolan ya forma parte de inputs. Quedan fuera las
+// variables que vex calcula en cada ejecución, como la versión o el commit, que
+// cambian aunque el paso no lo haga.
+func stepVariables(stepDef *defEnt.StepDefinition, inputs, overrides exeVos.VariableSet) map[string]string {
+	values := make(map[string]string, len(inputs)+len(stepDef.VariablesDef()))
+	for name, variable := range inputs {
+		if variable.Source().Kind() != exeVos.RuntimeSource {
+			values[name] = variable.Value()
+		}
+	}
+	for _, variable := range stepDef.VariablesDef() {
+		values[variable.Name()] = fmt.Sprintf("%v", variable.Value())
+	}
+	for name, value := range overrides.ToStringMap() {
+		values[name] = value
+	}
+	return values
+}
+
+// manifestKey devuelve la clave del workspace para los manifiestos de variables. Las
+// ejecuciones en paralelo la comparten, así que se crea una sola vez.
+func (o *ExecutionOrchestrator) manifestKey(workspace *worAgg.Workspace) ([]byte, error) {
+	keyPath := workspace.ManifestKeyPath()
+	unlock := o.locks.Lock(keyPath)
+	defer unlock()
+	key, err := o.manifestKeys.LoadOrCreate(keyPath)
 	if err != nil {
-		return staVos.CurrentStateFingerprints{}, err
+		return nil, fmt.Errorf("no se pudo obtener la clave de los manifiestos de variables: %w", err)
 	}
+	return key, nil
+}
+
+// warnFailedMigration convierte al formato actual un archivo guardado por una versión
+// anterior de vex. Se llama con el archivo bloqueado, porque los repositorios no
+// escriben al leer. Si falla, el archivo se sigue leyendo en el formato anterior.
+func warnFailedMigration(path string, migrate func(string) (bool, error)) {
+	if _, err := migrate(path); err != nil {
+		fmt.Printf("ADVERTENCIA: no se pudo convertir '%s' al formato actual; ejecuta 'vex workspace migrate'. Error: %v\n", path, err)
+	}
+}
+
+// variablesManifest guarda el hash del valor de cada variable, nunca el valor, para
+// no dejar secretos en el estado. El de las variables secretas es un HMAC con la
+// clave del workspace: un SHA-256 sin sal permitiría comprobar valores candidatos.
+func variablesManifest(values map[string]string, secretKey []byte) staVos.Manifest {
+	hashes := make(map[string]string, len(values))
+	for name, value := range values {
+		if exeVos.IsSecretName(name) {
+			mac := hmac.New(sha256.New, secretKey)
+			mac.Write([]byte(value))
+			hashes[name] = hex.EncodeToString(mac.Sum(nil))
+			continue
+		}
+		sum := sha256.Sum256([]byte(value))
+		hashes[name] = hex.EncodeToString(sum[:])
+	}
+	return staVos.NewManifest(hashes)
+}
 
-	return staVos.NewCurrentStateFingerprints(codeFp, instFp, varsFp, envFp), nil
+// variablesFingerprint resume un conjunto de variables. Sin variables devuelve un
+// fingerprint vacío, que no altera el fingerprint combinado.
+func variablesFingerprint(values map[string]string) staVos.Fingerprint {
+	if len(values) == 0 {
+		return staVos.Fingerprint{}
+	}
+	lines := make([]string, 0, len(values))
+	for name, value := range values {
+		lines = append(lines, name+"="+value)
+	}
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	fingerprint, _ := staVos.NewFingerprint(hex.EncodeToString(sum[:]))
+	return fingerprint
 }
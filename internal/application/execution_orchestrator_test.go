@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	defEnt "github.com/jairoprogramador/vex/internal/domain/definition/entities"
+	defVos "github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	exePrt "github.com/jairoprogramador/vex/internal/domain/execution/ports"
+	exeServ "github.com/jairoprogramador/vex/internal/domain/execution/services"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRollbackPlanHarness(t *testing.T) *orchestratorHarness {
+	t.Helper()
+	h := newOrchestratorHarness(t,
+		newTestStep(t, "01-build", "echo deshacer build"),
+		newTestStep(t, "02-test"),
+		newTestStep(t, "03-publish", "echo deshacer publish"),
+		newTestStep(t, "04-verify"),
+	)
+	h.executor.outputs["build"] = exeVos.NewVariableSetFromMap(map[string]string{"image": "app:1"})
+	h.executor.outputs["publish"] = exeVos.NewVariableSetFromMap(map[string]string{"url": "https://app"})
+	return h
+}
+
+func TestExecutePlan_RollbackOnFailureCompensatesInReverseOrderAndPersistsNothing(t *testing.T) {
+	h := newRollbackPlanHarness(t)
+	h.executor.failing["verify"] = true
+
+	err := h.orchestrator.ExecutePlan(context.Background(), "verify", "sand",
+		appDto.ExecutionOptions{TemplateDir: h.templateDir, RollbackOnFailure: true})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "los pasos completados fueron revertidos")
+	assert.Equal(t, []string{"build", "test", "publish", "verify"}, h.executor.executed)
+	// Los pasos sin comandos de rollback no se compensan.
+	assert.Equal(t, []string{"publish", "build"}, h.executor.rolled)
+	assert.Empty(t, h.vars.savedFiles(), "no deben quedar salidas de pasos compensados")
+	assert.Empty(t, h.states.updatedTables(), "los pasos compensados no deben quedar registrados como ejecutados")
+}
+
+func TestExecutePlan_RollbackOnFailurePersistsEveryStepWhenThePlanSucceeds(t *testing.T) {
+	h := newRollbackPlanHarness(t)
+
+	err := h.orchestrator.ExecutePlan(context.Background(), "verify", "sand",
+		appDto.ExecutionOptions{TemplateDir: h.templateDir, RollbackOnFailure: true})
+
+	require.NoError(t, err)
+	assert.Empty(t, h.executor.rolled)
+	assert.Len(t, h.vars.savedFiles(), 2)
+	assert.Len(t, h.states.updatedTables(), 4)
+}
+
+func TestExecutePlan_WithoutRollbackPersistsCompletedStepsBeforeTheFailure(t *testing.T) {
+	h := newRollbackPlanHarness(t)
+	h.executor.failing["verify"] = true
+
+	err := h.orchestrator.ExecutePlan(context.Background(), "verify", "sand",
+		appDto.ExecutionOptions{TemplateDir: h.templateDir})
+
+	require.Error(t, err)
+	assert.Empty(t, h.executor.rolled)
+	assert.Len(t, h.vars.savedFiles(), 2)
+	assert.Len(t, h.states.updatedTables(), 3, "los pasos anteriores al fallo no deben volver a ejecutarse")
+}
+
+// recordingRunner guarda los comandos que ejecuta. 'create <id>' imprime su id y
+// 'fail' termina con error.
+type recordingRunner struct {
+	commands []string
+}
+
+func (r *recordingRunner) Run(_ context.Context, command, _ string) (*exeVos.CommandResult, error) {
+	r.commands = append(r.commands, command)
+	if command == "fail" {
+		return &exeVos.CommandResult{ExitCode: 1}, nil
+	}
+	if id, isCreate := strings.CutPrefix(command, "create "); isCreate {
+		return &exeVos.CommandResult{NormalizedStdout: "id=" + id}, nil
+	}
+	return &exeVos.CommandResult{}, nil
+}
+
+func newRollbackStep(
+	t *testing.T, dirName, cmd string, variables []defVos.VariableDefinition, rollbackCmd string) *defEnt.StepDefinition {
+	t.Helper()
+	name, err := defVos.NewStepNameDefinition(dirName)
+	require.NoError(t, err)
+	output, err := defVos.NewOutputDefinition("id", "", `id=(\S+)`)
+	require.NoError(t, err)
+	opts := []defVos.CommandOption{}
+	if strings.HasPrefix(cmd, "create ") {
+		opts = append(opts, defVos.WithOutputs([]defVos.OutputDefinition{output}))
+	}
+	command, err := defVos.NewCommandDefinition("run", cmd, opts...)
+	require.NoError(t, err)
+	rollback := []defVos.CommandDefinition{}
+	if rollbackCmd != "" {
+		undo, err := defVos.NewCommandDefinition("undo", rollbackCmd)
+		require.NoError(t, err)
+		rollback = append(rollback, undo)
+	}
+	step, err := defEnt.NewStepDefinition(name, []defVos.CommandDefinition{command}, variables,
+		defEnt.WithHooks(defVos.NewStepHooksDefinition(defVos.WithRollback(rollback))))
+	require.NoError(t, err)
+	return step
+}
+
+func TestExecutePlan_RollbackRunsWithTheVariablesOfItsOwnStep(t *testing.T) {
+	region, err := defVos.NewVariableDefinition("region", "eu-west")
+	require.NoError(t, err)
+	runner := &recordingRunner{}
+	interpolator := exeServ.NewInterpolator()
+	stepExecutor := exeServ.NewStepExecutor(func() exePrt.CommandExecutor {
+		return exeServ.NewCommandExecutor(
+			runner, exeServ.NewFileProcessor(&templateFileSystem{written: map[string]string{}}, interpolator),
+			interpolator, exeServ.NewOutputExtractor())
+	}, exeServ.NewVariableResolver(interpolator))
+	h := newOrchestratorHarnessWithExecutor(t, stepExecutor,
+		newRollbackStep(t, "01-network", "create net", []defVos.VariableDefinition{region},
+			"delete ${var.id} in ${var.region}"),
+		// Su salida 'id' reemplaza a la de network en las variables del plan.
+		newRollbackStep(t, "02-server", "create srv", nil, ""),
+		newRollbackStep(t, "03-verify", "fail", nil, ""),
+	)
+
+	err = h.orchestrator.ExecutePlan(context.Background(), "verify", "sand",
+		appDto.ExecutionOptions{TemplateDir: h.templateDir, RollbackOnFailure: true})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "los pasos completados fueron revertidos")
+	assert.Equal(t, []string{"create net", "create srv", "fail", "delete net in eu-west"}, runner.commands)
+}
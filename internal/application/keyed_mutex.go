@@ -0,0 +1,28 @@
+package application
+
+import "sync"
+
+// keyedMutex serializa el acceso a recursos identificados por una clave, como un
+// archivo, cuando varias ejecuciones del plan corren en paralelo.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*sync.Mutex)}
+}
+
+// Lock bloquea la clave y devuelve la función que la libera.
+func (k *keyedMutex) Lock(key string) func() {
+	k.mu.Lock()
+	lock, exists := k.locks[key]
+	if !exists {
+		lock = &sync.Mutex{}
+		k.locks[key] = lock
+	}
+	k.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
@@ -0,0 +1,43 @@
+package application
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedMutex(t *testing.T) {
+	t.Run("should serialize the holders of the same key", func(t *testing.T) {
+		locks := newKeyedMutex()
+		counter := 0
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				unlock := locks.Lock("state.tb")
+				defer unlock()
+				current := counter
+				counter = current + 1
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 50, counter)
+	})
+
+	t.Run("should not block different keys", func(t *testing.T) {
+		locks := newKeyedMutex()
+		unlockA := locks.Lock("a")
+		defer unlockA()
+
+		done := make(chan struct{})
+		go func() {
+			unlock := locks.Lock("b")
+			unlock()
+			close(done)
+		}()
+		<-done
+	})
+}
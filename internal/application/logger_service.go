@@ -2,11 +2,16 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
 
 	appDto "github.com/jairoprogramador/vex/internal/application/dto"
 	appPor "github.com/jairoprogramador/vex/internal/application/ports"
 
+	proAgg "github.com/jairoprogramador/vex/internal/domain/project/aggregates"
 	proPor "github.com/jairoprogramador/vex/internal/domain/project/ports"
+	proVos "github.com/jairoprogramador/vex/internal/domain/project/vos"
 
 	"github.com/jairoprogramador/vex/internal/domain/logger/aggregates"
 	"github.com/jairoprogramador/vex/internal/domain/logger/entities"
@@ -30,17 +35,26 @@ func NewLoggerService(
 	}
 }
 
-func (l *LoggerService) ShowLog(pathProject string) error {
+// runLogNames ubica el log de las ejecuciones de un proyecto en un ambiente, dentro
+// del workspace de su pila de plantillas. La ejecución lo escribe y ShowLog lo lee
+// con los mismos nombres.
+func runLogNames(project *proAgg.Project, environment string) appDto.NamesParams {
+	return appDto.NewNamesParams(project.Data().Name(), proVos.TemplateStackKey(project.Templates())).
+		WithEnvironment(environment)
+}
 
-	configProject, err := l.configRepository.Load(context.Background(), pathProject)
+// ShowLog muestra el log de la última ejecución del proyecto en un ambiente.
+func (l *LoggerService) ShowLog(pathProject, environment string) error {
+	project, err := NewProjectService(l.configRepository).Load(context.Background(), pathProject)
 	if err != nil {
 		return err
 	}
 
-	namesParams := appDto.NewNamesParams(configProject.Name, configProject.TemplateURL)
-
-	logger, err := l.loggerRepository.Find(namesParams)
+	logger, err := l.loggerRepository.Find(runLogNames(project, environment))
 	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no hay ejecuciones registradas en el ambiente '%s'", environment)
+		}
 		return err
 	}
 
@@ -50,6 +64,9 @@ func (l *LoggerService) ShowLog(pathProject string) error {
 		for _, task := range step.Tasks() {
 			l.presenter.Task(task, step)
 		}
+		for _, hook := range step.Hooks() {
+			l.presenter.Hook(hook, step)
+		}
 	}
 	l.presenter.FinalSummary(&logger)
 
@@ -204,6 +221,33 @@ func (l *LoggerService) AddOutputToTask(namesParams appDto.NamesParams, logger *
 	return l.loggerRepository.Save(namesParams, logger)
 }
 
+// RecordHooks añade al paso stepName del log los comandos de hooks ejecutados, creando
+// el registro del paso si aún no existe, y los muestra.
+func (l *LoggerService) RecordHooks(namesParams appDto.NamesParams, logger *aggregates.Logger, stepName string, hooks []*entities.TaskRecord) error {
+	step, err := logger.GetStep(stepName)
+	if err != nil {
+		if step, err = entities.NewStepRecord(stepName); err != nil {
+			return err
+		}
+		if err := logger.AddStep(step); err != nil {
+			return err
+		}
+	}
+
+	for _, hook := range hooks {
+		step.AddHook(hook)
+		if l.presenter != nil {
+			l.presenter.Hook(hook, step)
+		}
+	}
+	return l.loggerRepository.Save(namesParams, logger)
+}
+
+// SaveLog guarda el log sin mostrarlo. La ejecución de un plan muestra su propio progreso.
+func (l *LoggerService) SaveLog(namesParams appDto.NamesParams, logger *aggregates.Logger) error {
+	return l.loggerRepository.Save(namesParams, logger)
+}
+
 func (l *LoggerService) FinishExecution(namesParams appDto.NamesParams, logger *aggregates.Logger) error {
 	logger.RecalculateStatus()
 	if l.presenter != nil {
@@ -9,7 +9,8 @@ import (
 	execVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
 )
 
-func mapToExecutionStep(defStep *defEnt.StepDefinition, workspaceStep, workspaceShared string) (*execEnt.Step, error) {
+func mapToExecutionStep(
+	defStep *defEnt.StepDefinition, workspaceStep, workspaceShared string, overrides execVos.VariableSet) (*execEnt.Step, error) {
 	execCmds, err := mapToExecutionCommands(defStep.CommandsDef())
 	if err != nil {
 		return nil, fmt.Errorf("error al mapear los comandos para el paso '%s': %w", defStep.NameDef().Name(), err)
@@ -19,10 +20,29 @@ func mapToExecutionStep(defStep *defEnt.StepDefinition, workspaceStep, workspace
 	if err != nil {
 		return nil, fmt.Errorf("error al mapear las variables para el paso '%s': %w", defStep.NameDef().Name(), err)
 	}
+	execVars.AddAll(overrides)
+
+	onFailureCmds, err := mapToExecutionCommands(defStep.HooksDef().OnFailure())
+	if err != nil {
+		return nil, fmt.Errorf("error al mapear el hook on_failure para el paso '%s': %w", defStep.NameDef().Name(), err)
+	}
+
+	finallyCmds, err := mapToExecutionCommands(defStep.HooksDef().Finally())
+	if err != nil {
+		return nil, fmt.Errorf("error al mapear el hook finally para el paso '%s': %w", defStep.NameDef().Name(), err)
+	}
+
+	rollbackCmds, err := mapToExecutionCommands(defStep.HooksDef().Rollback())
+	if err != nil {
+		return nil, fmt.Errorf("error al mapear los comandos de rollback para el paso '%s': %w", defStep.NameDef().Name(), err)
+	}
 
 	execStep, err := execEnt.NewStep(
 		defStep.NameDef().Name(),
 		execEnt.WithCommands(execCmds),
+		execEnt.WithOnFailureCommands(onFailureCmds),
+		execEnt.WithFinallyCommands(finallyCmds),
+		execEnt.WithRollbackCommands(rollbackCmds),
 		execEnt.WithVariables(execVars),
 		execEnt.WithWorkspaceStep(workspaceStep),
 		execEnt.WithWorkspaceShared(workspaceShared),
@@ -34,18 +54,35 @@ func mapToExecutionStep(defStep *defEnt.StepDefinition, workspaceStep, workspace
 	return &execStep, nil
 }
 
+// mapToExecutionVariables convierte las variables de la definición. Cada una conserva,
+// como cadena de resolución, las definiciones de otras capas o archivos que sobrescribe.
 func mapToExecutionVariables(defVars []defVos.VariableDefinition) (execVos.VariableSet, error) {
 	execVars := execVos.NewVariableSet()
 	for _, defVar := range defVars {
-		outputVar, err := execVos.NewOutputVar(defVar.Name(), fmt.Sprintf("%v", defVar.Value()), false)
-		if err != nil {
-			return execVos.NewVariableSet(), err
+		chain := defVar.Chain()
+		for i := len(chain) - 1; i >= 0; i-- {
+			outputVar, err := execVos.NewOutputVar(chain[i].Name(), fmt.Sprintf("%v", chain[i].Value()), false)
+			if err != nil && i == 0 {
+				return execVos.NewVariableSet(), err
+			}
+			if err != nil {
+				// Un valor sobrescrito que no es válido no impide usar el vigente.
+				continue
+			}
+			execVars.Add(outputVar.WithSource(definitionSource(chain[i])))
 		}
-		execVars.Add(outputVar)
 	}
 	return execVars, nil
 }
 
+func definitionSource(defVar defVos.VariableDefinition) execVos.VariableSource {
+	origin := defVar.Layer()
+	if defVar.File() != "" {
+		origin = defVar.Layer() + ": " + defVar.File()
+	}
+	return execVos.NewVariableSource(execVos.DefinitionSource, origin)
+}
+
 func mapToExecutionCommands(defCmds []defVos.CommandDefinition) ([]execVos.Command, error) {
 	execCmds := make([]execVos.Command, 0, len(defCmds))
 	for _, defCmd := range defCmds {
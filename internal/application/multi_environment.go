@@ -0,0 +1,96 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+)
+
+// ExecutePlanInEnvironments ejecuta el plan en varios ambientes a la vez, con como
+// máximo parallel ejecuciones simultáneas. Cada ambiente tiene su propio workspace y
+// sus variables; los archivos compartidos entre ambientes se protegen con locks. Los
+// resultados se devuelven en el orden de envNames.
+func (o *ExecutionOrchestrator) ExecutePlanInEnvironments(
+	ctx context.Context, stepName string, envNames []string, parallel int,
+	opts appDto.ExecutionOptions) []appDto.EnvironmentRunResult {
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	opts.Confirm = serializedConfirm(opts.Confirm)
+
+	results := make([]appDto.EnvironmentRunResult, len(envNames))
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, envName := range envNames {
+		wg.Add(1)
+		go func(i int, envName string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			startedAt := time.Now()
+			err := o.ExecutePlan(ctx, stepName, envName, opts)
+			results[i] = appDto.EnvironmentRunResult{
+				Environment: envName,
+				Duration:    time.Since(startedAt),
+				Err:         err,
+			}
+		}(i, envName)
+	}
+	wg.Wait()
+	return results
+}
+
+// EnvironmentGroup devuelve, en el orden de environments.yaml, los ambientes de un grupo.
+func (o *ExecutionOrchestrator) EnvironmentGroup(
+	ctx context.Context, group string, opts appDto.ExecutionOptions) ([]string, error) {
+
+	project, err := o.loadProject(ctx, o.projectPath)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := o.loadWorkspace(project, o.rootVexPath, opts.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.cloneTemplate(ctx, project, workspace, o.projectPath); err != nil {
+		return nil, err
+	}
+	layers, err := templateLayers(project, workspace)
+	if err != nil {
+		return nil, err
+	}
+	environments, err := o.planBuilder.Environments(ctx, layers)
+	if err != nil {
+		return nil, err
+	}
+
+	envNames := make([]string, 0)
+	for _, env := range environments {
+		if env.Group() == group {
+			envNames = append(envNames, env.String())
+		}
+	}
+	if len(envNames) == 0 {
+		return nil, fmt.Errorf("no hay ambientes en el grupo '%s'", group)
+	}
+	return envNames, nil
+}
+
+// serializedConfirm evita que las confirmaciones de varios ambientes protegidos se
+// pidan al mismo tiempo en la terminal.
+func serializedConfirm(confirm func(string) (string, error)) func(string) (string, error) {
+	if confirm == nil {
+		return nil
+	}
+	var mu sync.Mutex
+	return func(environment string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return confirm(environment)
+	}
+}
@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	defEnt "github.com/jairoprogramador/vex/internal/domain/definition/entities"
+	defVos "github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	exePrt "github.com/jairoprogramador/vex/internal/domain/execution/ports"
+	exeServ "github.com/jairoprogramador/vex/internal/domain/execution/services"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// templateFileSystem devuelve siempre la plantilla original al leer, como si el
+// workdir se acabara de copiar, y guarda lo que se escribe en cada ruta.
+type templateFileSystem struct {
+	mu       sync.Mutex
+	template string
+	written  map[string]string
+}
+
+func (fs *templateFileSystem) ReadFile(string) ([]byte, error) {
+	return []byte(fs.template), nil
+}
+
+func (fs *templateFileSystem) WriteFile(path string, data []byte) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.written[path] = string(data)
+	return nil
+}
+
+func (fs *templateFileSystem) content(path string) string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.written[path]
+}
+
+// sharedWorkdirRunner comprueba que, mientras corre un comando, su plantilla tiene
+// los valores del ambiente que lo ejecuta y ningún otro comando usa el mismo workdir.
+type sharedWorkdirRunner struct {
+	mu       sync.Mutex
+	files    *templateFileSystem
+	active   map[string]int
+	failures []string
+}
+
+func (r *sharedWorkdirRunner) Run(_ context.Context, command, workDir string) (*exeVos.CommandResult, error) {
+	r.mu.Lock()
+	r.active[workDir]++
+	if r.active[workDir] > 1 {
+		r.failures = append(r.failures, fmt.Sprintf("'%s' se ejecutó en paralelo en %s", command, workDir))
+	}
+	r.mu.Unlock()
+
+	environment := strings.TrimPrefix(command, "deploy ")
+	for i := 0; i < 5; i++ {
+		if content := r.files.content(filepath.Join(workDir, "config.yaml")); content != "environment: "+environment {
+			r.mu.Lock()
+			r.failures = append(r.failures, fmt.Sprintf("'%s' leyó la plantilla '%s'", command, content))
+			r.mu.Unlock()
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	r.mu.Lock()
+	r.active[workDir]--
+	r.mu.Unlock()
+	return &exeVos.CommandResult{ExitCode: 0}, nil
+}
+
+func newSharedDeployStep(t *testing.T) *defEnt.StepDefinition {
+	t.Helper()
+	name, err := defVos.NewStepNameDefinition("01-deploy")
+	require.NoError(t, err)
+	cmd, err := defVos.NewCommandDefinition("deploy", "deploy ${var.environment}",
+		defVos.WithWorkdir(exeVos.SharedScope), defVos.WithTemplateFiles([]string{"config.yaml"}))
+	require.NoError(t, err)
+	step, err := defEnt.NewStepDefinition(name, []defVos.CommandDefinition{cmd}, nil)
+	require.NoError(t, err)
+	return step
+}
+
+func TestExecutePlanInEnvironments_SerializesTheSharedWorkdir(t *testing.T) {
+	files := &templateFileSystem{template: "environment: ${var.environment}", written: make(map[string]string)}
+	runner := &sharedWorkdirRunner{files: files, active: make(map[string]int)}
+	interpolator := exeServ.NewInterpolator()
+	stepExecutor := exeServ.NewStepExecutor(func() exePrt.CommandExecutor {
+		return exeServ.NewCommandExecutor(
+			runner, exeServ.NewFileProcessor(files, interpolator), interpolator, exeServ.NewOutputExtractor())
+	}, exeServ.NewVariableResolver(interpolator))
+	h := newOrchestratorHarnessWithExecutor(t, stepExecutor, newSharedDeployStep(t))
+
+	environments := []string{"eu", "us", "apac"}
+	results := h.orchestrator.ExecutePlanInEnvironments(context.Background(), "deploy", environments,
+		len(environments), appDto.ExecutionOptions{TemplateDir: h.templateDir})
+
+	require.Len(t, results, len(environments))
+	for i, result := range results {
+		assert.Equal(t, environments[i], result.Environment)
+		assert.NoError(t, result.Err)
+	}
+	assert.Empty(t, runner.failures)
+}
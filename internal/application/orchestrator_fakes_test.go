@@ -0,0 +1,448 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	defAgg "github.com/jairoprogramador/vex/internal/domain/definition/aggregates"
+	defEnt "github.com/jairoprogramador/vex/internal/domain/definition/entities"
+	defVos "github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	exeEnt "github.com/jairoprogramador/vex/internal/domain/execution/entities"
+	exePrt "github.com/jairoprogramador/vex/internal/domain/execution/ports"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	proPrt "github.com/jairoprogramador/vex/internal/domain/project/ports"
+	proVos "github.com/jairoprogramador/vex/internal/domain/project/vos"
+	pvnAgg "github.com/jairoprogramador/vex/internal/domain/provenance/aggregates"
+	pvnVos "github.com/jairoprogramador/vex/internal/domain/provenance/vos"
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	staAgg "github.com/jairoprogramador/vex/internal/domain/state/aggregates"
+	staVos "github.com/jairoprogramador/vex/internal/domain/state/vos"
+	verVos "github.com/jairoprogramador/vex/internal/domain/versioning/vos"
+	worAgg "github.com/jairoprogramador/vex/internal/domain/workspace/aggregates"
+	"github.com/stretchr/testify/require"
+)
+
+// orchestratorHarness arma un ExecutionOrchestrator con dobles en memoria y una
+// plantilla local vacía, para probar el flujo del plan sin git ni comandos reales.
+type orchestratorHarness struct {
+	orchestrator *ExecutionOrchestrator
+	executor     *fakeStepExecutor
+	vars         *fakeVarsRepository
+	states       *fakeStateManager
+	releases     *fakeReleaseRepository
+	git          *fakeGitRepository
+	provenance   *ProvenanceService
+	attestations *fakeAttestationStore
+	projectPath  string
+	rootVexPath  string
+	templateDir  string
+}
+
+func newOrchestratorHarness(t *testing.T, steps ...*defEnt.StepDefinition) *orchestratorHarness {
+	t.Helper()
+	executor := newFakeStepExecutor()
+	h := newOrchestratorHarnessWithExecutor(t, executor, steps...)
+	h.executor = executor
+	return h
+}
+
+// newOrchestratorHarnessWithExecutor arma el harness con otro ejecutor de pasos,
+// p. ej. el real del dominio con un runner de comandos en memoria.
+func newOrchestratorHarnessWithExecutor(
+	t *testing.T, stepExecutor exePrt.StepExecutor, steps ...*defEnt.StepDefinition) *orchestratorHarness {
+	t.Helper()
+	h := &orchestratorHarness{
+		vars:         newFakeVarsRepository(),
+		states:       newFakeStateManager(),
+		releases:     &fakeReleaseRepository{},
+		git:          &fakeGitRepository{},
+		attestations: &fakeAttestationStore{signature: pvnVos.NewSignatureCheck(true, "clave local")},
+		projectPath:  t.TempDir(),
+		rootVexPath:  t.TempDir(),
+		templateDir:  t.TempDir(),
+	}
+	projectSvc := NewProjectService(&fakeProjectConfigRepository{})
+	workspaceSvc := NewWorkspaceService()
+	h.provenance = NewProvenanceService(h.projectPath, h.rootVexPath, projectSvc, workspaceSvc,
+		h.git, fakeFingerprintService{}, h.attestations)
+	h.orchestrator = NewExecutionOrchestrator(
+		h.projectPath, h.rootVexPath, projectSvc, workspaceSvc,
+		NewTemplateService(h.projectPath, h.rootVexPath, projectSvc, workspaceSvc, nil, &fakeTemplateLockRepository{}),
+		fakeVersionCalculator{}, &fakePlanBuilder{steps: steps}, fakeFingerprintService{},
+		h.states, fakeManifestKeyRepository{}, stepExecutor, fakeCopyWorkdir{}, h.vars, h.git, h.releases, h.provenance, nil, "test")
+	return h
+}
+
+// workspace devuelve el workspace que usan las ejecuciones del harness.
+func (h *orchestratorHarness) workspace(t *testing.T) *worAgg.Workspace {
+	t.Helper()
+	project, err := h.orchestrator.loadProject(context.Background(), h.projectPath)
+	require.NoError(t, err)
+	workspace, err := h.orchestrator.loadWorkspace(project, h.rootVexPath, h.templateDir)
+	require.NoError(t, err)
+	return workspace
+}
+
+// newTestStep crea la definición de un paso con un comando y, si se indican, los
+// comandos de rollback.
+func newTestStep(t *testing.T, dirName string, rollbackCmds ...string) *defEnt.StepDefinition {
+	t.Helper()
+	name, err := defVos.NewStepNameDefinition(dirName)
+	require.NoError(t, err)
+	cmd, err := defVos.NewCommandDefinition("run", "echo "+dirName)
+	require.NoError(t, err)
+
+	rollback := make([]defVos.CommandDefinition, 0, len(rollbackCmds))
+	for i, rollbackCmd := range rollbackCmds {
+		command, err := defVos.NewCommandDefinition("rollback-"+string(rune('a'+i)), rollbackCmd)
+		require.NoError(t, err)
+		rollback = append(rollback, command)
+	}
+	step, err := defEnt.NewStepDefinition(name, []defVos.CommandDefinition{cmd}, nil,
+		defEnt.WithHooks(defVos.NewStepHooksDefinition(defVos.WithRollback(rollback))))
+	require.NoError(t, err)
+	return step
+}
+
+type fakeProjectConfigRepository struct{}
+
+func (r *fakeProjectConfigRepository) Load(context.Context, string) (*proPrt.ProjectConfigDTO, error) {
+	return &proPrt.ProjectConfigDTO{
+		ID:           proVos.GenerateProjectID("demo", "vex", "core").String(),
+		Name:         "demo",
+		Organization: "vex",
+		Team:         "core",
+		Version:      "1.0.0",
+		TemplateURL:  "https://github.com/jairo/template.git",
+		TemplateRef:  "main",
+	}, nil
+}
+
+func (r *fakeProjectConfigRepository) Save(context.Context, string, *proPrt.ProjectConfigDTO) error {
+	return nil
+}
+
+func (r *fakeProjectConfigRepository) LoadVariables(context.Context, string) (proPrt.VariablesConfigDTO, error) {
+	return nil, nil
+}
+
+type fakeTemplateLockRepository struct{}
+
+func (r *fakeTemplateLockRepository) Load(context.Context, string) ([]proVos.TemplateLock, error) {
+	return nil, nil
+}
+
+func (r *fakeTemplateLockRepository) Save(context.Context, string, []proVos.TemplateLock) error {
+	return nil
+}
+
+type fakeVersionCalculator struct{}
+
+func (fakeVersionCalculator) CalculateNextVersion(context.Context, string, bool) (*verVos.Version, *verVos.Commit, error) {
+	return &verVos.Version{Major: 1, Raw: "v1.0.0"}, &verVos.Commit{Hash: "0123456789abcdef0123456789abcdef01234567"}, nil
+}
+
+// fakePlanBuilder devuelve un plan con los pasos indicados en el ambiente pedido.
+type fakePlanBuilder struct {
+	steps []*defEnt.StepDefinition
+}
+
+func (b *fakePlanBuilder) Build(
+	_ context.Context, _ []defVos.LayerDefinition, _, envName string,
+	_ ...defVos.VariableOverridesDefinition) (*defAgg.ExecutionPlanDefinition, error) {
+	env, err := defVos.NewEnvironment(envName, envName)
+	if err != nil {
+		return nil, err
+	}
+	return defAgg.NewExecutionPlanDefinition(env, b.steps)
+}
+
+func (b *fakePlanBuilder) Environments(context.Context, []defVos.LayerDefinition) ([]defVos.EnvironmentDefinition, error) {
+	return nil, nil
+}
+
+type fakeManifestKeyRepository struct{}
+
+func (fakeManifestKeyRepository) LoadOrCreate(string) ([]byte, error) {
+	return []byte("workspace-key"), nil
+}
+
+type fakeFingerprintService struct{}
+
+func (fakeFingerprintService) FromFile(string) (staVos.Fingerprint, error) {
+	return staVos.NewFingerprint("file")
+}
+
+func (fakeFingerprintService) FromDirectory(string) (staVos.Fingerprint, error) {
+	return staVos.NewFingerprint("directory")
+}
+
+func (fakeFingerprintService) DirectoryManifest(string, staVos.FingerprintScope) (staVos.Manifest, error) {
+	return staVos.Manifest{}, nil
+}
+
+func (fakeFingerprintService) FromManifest(staVos.Manifest) (staVos.Fingerprint, error) {
+	return staVos.NewFingerprint("code")
+}
+
+// fakeStateManager considera que todos los pasos cambiaron y recuerda qué tablas
+// de estado se actualizaron.
+type fakeStateManager struct {
+	mu      sync.Mutex
+	updated []string
+}
+
+func newFakeStateManager() *fakeStateManager {
+	return &fakeStateManager{}
+}
+
+func (m *fakeStateManager) HasStateChanged(string, staVos.CurrentStateFingerprints, staVos.CachePolicy) (bool, error) {
+	return true, nil
+}
+
+func (m *fakeStateManager) UpdateState(stateTablePath string, _ staVos.CurrentStateFingerprints) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.updated = append(m.updated, stateTablePath)
+	return nil
+}
+
+func (m *fakeStateManager) GetState(string) (*staAgg.StateTable, error) {
+	return nil, nil
+}
+
+func (m *fakeStateManager) MigrateState(string) (bool, error) {
+	return false, nil
+}
+
+func (m *fakeStateManager) ListStates(string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *fakeStateManager) ClearState(string, string) (int, error) {
+	return 0, nil
+}
+
+func (m *fakeStateManager) PruneState(string, time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *fakeStateManager) updatedTables() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.updated...)
+}
+
+// fakeStepExecutor ejecuta cada paso devolviendo las salidas configuradas para él, o
+// un fallo si está en failing, y registra el orden de ejecuciones y rollbacks.
+type fakeStepExecutor struct {
+	mu        sync.Mutex
+	outputs   map[string]exeVos.VariableSet
+	failing   map[string]bool
+	hooks     map[string][]exeVos.HookResult
+	executed  []string
+	rolled    []string
+	inputsFor map[string]exeVos.VariableSet
+}
+
+func newFakeStepExecutor() *fakeStepExecutor {
+	return &fakeStepExecutor{
+		outputs:   map[string]exeVos.VariableSet{},
+		failing:   map[string]bool{},
+		hooks:     map[string][]exeVos.HookResult{},
+		inputsFor: map[string]exeVos.VariableSet{},
+	}
+}
+
+func (e *fakeStepExecutor) Execute(
+	_ context.Context, step *exeEnt.Step, initialVars exeVos.VariableSet) (*exeVos.ExecutionResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.executed = append(e.executed, step.Name())
+	e.inputsFor[step.Name()] = initialVars.Clone()
+	if e.failing[step.Name()] {
+		return &exeVos.ExecutionResult{
+			Status: exeVos.Failure, Hooks: e.hooks[step.Name()], Error: context.DeadlineExceeded}, nil
+	}
+	outputs := e.outputs[step.Name()]
+	if outputs == nil {
+		outputs = exeVos.NewVariableSet()
+	}
+	return &exeVos.ExecutionResult{
+		Status: exeVos.Success, OutputVars: outputs.Clone(), Hooks: e.hooks[step.Name()]}, nil
+}
+
+func (e *fakeStepExecutor) Rollback(
+	_ context.Context, step *exeEnt.Step, _ exeVos.VariableSet) (*exeVos.ExecutionResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rolled = append(e.rolled, step.Name())
+	return &exeVos.ExecutionResult{Status: exeVos.Success}, nil
+}
+
+// fakeVarsRepository guarda los archivos de variables en memoria.
+type fakeVarsRepository struct {
+	mu    sync.Mutex
+	files map[string]exeVos.VariableSet
+	saves []string
+}
+
+func newFakeVarsRepository() *fakeVarsRepository {
+	return &fakeVarsRepository{files: map[string]exeVos.VariableSet{}}
+}
+
+func (r *fakeVarsRepository) Get(filePath string) (exeVos.VariableSet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if vars, ok := r.files[filePath]; ok {
+		return vars.Clone(), nil
+	}
+	return exeVos.NewVariableSet(), nil
+}
+
+func (r *fakeVarsRepository) Save(filePath string, vars exeVos.VariableSet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(vars) == 0 {
+		return nil
+	}
+	r.files[filePath] = vars.Clone()
+	r.saves = append(r.saves, filePath)
+	return nil
+}
+
+func (r *fakeVarsRepository) Delete(filePath string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.files, filePath)
+	return nil
+}
+
+func (r *fakeVarsRepository) Update(
+	filePath string, update func(exeVos.VariableSet) (exeVos.VariableSet, error)) error {
+	vars, err := r.Get(filePath)
+	if err != nil {
+		return err
+	}
+	updated, err := update(vars)
+	if err != nil {
+		return err
+	}
+	if len(updated) == 0 {
+		return r.Delete(filePath)
+	}
+	return r.Save(filePath, updated)
+}
+
+func (r *fakeVarsRepository) List(string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeVarsRepository) Migrate(string) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeVarsRepository) savedFiles() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.saves...)
+}
+
+type fakeReleaseRepository struct {
+	mu    sync.Mutex
+	saved []*relAgg.Release
+}
+
+func (r *fakeReleaseRepository) Save(_ string, release *relAgg.Release) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saved = append(r.saved, release)
+	return nil
+}
+
+func (r *fakeReleaseRepository) FindAll(string) ([]*relAgg.Release, []error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*relAgg.Release(nil), r.saved...), nil, nil
+}
+
+// fakeGitRepository solo exporta revisiones: el resto de operaciones no se usan en
+// los planes de prueba.
+type fakeGitRepository struct{}
+
+func (fakeGitRepository) GetLastCommit(context.Context, string) (*verVos.Commit, error) {
+	return &verVos.Commit{Hash: "0123456789abcdef0123456789abcdef01234567"}, nil
+}
+
+func (fakeGitRepository) GetCommitsSinceTag(context.Context, string, string) ([]*verVos.Commit, error) {
+	return nil, nil
+}
+
+func (fakeGitRepository) GetLastSemverTag(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func (fakeGitRepository) CreateTagForCommit(context.Context, string, string, string) error {
+	return nil
+}
+
+func (fakeGitRepository) ExportRevision(context.Context, string, string, string) (*verVos.Commit, error) {
+	return &verVos.Commit{Hash: "fedcba9876543210fedcba9876543210fedcba98"}, nil
+}
+
+func (fakeGitRepository) GetRemoteURL(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func (fakeGitRepository) GetWorkingTreeStatus(context.Context, string, string) (*verVos.WorkingTreeStatus, error) {
+	return &verVos.WorkingTreeStatus{}, nil
+}
+
+type fakeCopyWorkdir struct{}
+
+func (fakeCopyWorkdir) Copy(context.Context, string, string, bool) error {
+	return nil
+}
+
+// fakeAttestationStore guarda las declaraciones en memoria, sin firmarlas. Load
+// devuelve signature como resultado de comprobar la firma.
+type fakeAttestationStore struct {
+	mu         sync.Mutex
+	statements map[string]*pvnAgg.Statement
+	latest     string
+	signature  pvnVos.SignatureCheck
+}
+
+func (s *fakeAttestationStore) Save(dirPath string, statement *pvnAgg.Statement) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.statements == nil {
+		s.statements = map[string]*pvnAgg.Statement{}
+	}
+	filePath := filepath.Join(dirPath, statement.InvocationID()+".json")
+	s.statements[filePath] = statement
+	s.latest = filePath
+	return filePath, nil
+}
+
+func (s *fakeAttestationStore) Load(filePath string) (*pvnAgg.Statement, pvnVos.SignatureCheck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if statement, ok := s.statements[filePath]; ok {
+		return statement, s.signature, nil
+	}
+	return nil, pvnVos.SignatureCheck{}, errors.New("declaración no encontrada")
+}
+
+func (s *fakeAttestationStore) FindLatest(string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latest == "" {
+		return "", errors.New("sin declaraciones")
+	}
+	return s.latest, nil
+}
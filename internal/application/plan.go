@@ -0,0 +1,27 @@
+package application
+
+import (
+	"context"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	defAgg "github.com/jairoprogramador/vex/internal/domain/definition/aggregates"
+)
+
+// Plan ensambla, sin ejecutarlo, el plan de un paso en un ambiente combinando las
+// capas de la plantilla. Si stepName está vacío, el plan incluye todos los pasos.
+func (o *ExecutionOrchestrator) Plan(
+	ctx context.Context, stepName, envName string, opts appDto.ExecutionOptions) (*defAgg.ExecutionPlanDefinition, error) {
+
+	project, err := o.loadProject(ctx, o.projectPath)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := o.loadWorkspace(project, o.rootVexPath, opts.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.cloneTemplate(ctx, project, workspace, o.projectPath); err != nil {
+		return nil, err
+	}
+	return o.buildPlan(ctx, project, workspace, stepName, envName)
+}
@@ -10,5 +10,6 @@ type PresenterService interface {
 	Header(log *aggregates.Logger, revision string)
 	Step(step *entities.StepRecord)
 	Task(task *entities.TaskRecord, step *entities.StepRecord)
+	Hook(hook *entities.TaskRecord, step *entities.StepRecord)
 	FinalSummary(log *aggregates.Logger)
 }
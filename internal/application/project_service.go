@@ -10,6 +10,10 @@ import (
 	"github.com/jairoprogramador/vex/internal/domain/project/vos"
 )
 
+// LocalConfigFile es el archivo, fuera del control de versiones, con las variables
+// propias de cada desarrollador. Tiene el mismo esquema que vexconfig.yaml.
+const LocalConfigFile = "vexconfig.local.yaml"
+
 type ProjectService struct {
 	projectRepo ports.ProjectRepository
 }
@@ -42,9 +46,32 @@ func (s *ProjectService) Load(
 	if err != nil {
 		return nil, fmt.Errorf("datos del repositorio de plantillas inválidos: %w", err)
 	}
+	overlays := make([]vos.TemplateRepository, 0, len(projectDTO.TemplateOverlays))
+	for _, overlayDTO := range projectDTO.TemplateOverlays {
+		overlay, err := vos.NewTemplateRepository(overlayDTO.URL, overlayDTO.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("datos del overlay de plantilla '%s' inválidos: %w", overlayDTO.URL, err)
+		}
+		overlays = append(overlays, overlay)
+	}
+	variables, err := vos.NewVariableOverrides(projectDTO.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("variables inválidas en vexconfig.yaml: %w", err)
+	}
+	localVariablesDTO, err := s.projectRepo.LoadVariables(ctx, filepath.Join(projectLocalPath, LocalConfigFile))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo cargar %s: %w", LocalConfigFile, err)
+	}
+	localVariables, err := vos.NewVariableOverrides(localVariablesDTO)
+	if err != nil {
+		return nil, fmt.Errorf("variables inválidas en %s: %w", LocalConfigFile, err)
+	}
 	projectID := vos.NewProjectID(projectDTO.ID)
 
-	project := aggregates.NewProject(projectID, projectData, templateRepo, projectLocalPath)
+	project := aggregates.NewProject(
+		projectID, projectData, templateRepo, projectLocalPath,
+		aggregates.WithTemplateOverlays(overlays...),
+		aggregates.WithVariables(variables, localVariables))
 
 	if project.SyncID() {
 		fmt.Println("El ID del proyecto ha cambiado. Actualizando vexconfig.yaml...")
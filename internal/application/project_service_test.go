@@ -16,6 +16,8 @@ import (
 type fakeProjectRepository struct {
 	LoadFunc func(ctx context.Context, path string) (*ports.ProjectConfigDTO, error)
 	SaveFunc func(ctx context.Context, path string, data *ports.ProjectConfigDTO) error
+	// LocalVariables son las variables que devuelve LoadVariables.
+	LocalVariables ports.VariablesConfigDTO
 
 	saveCalled bool
 }
@@ -35,6 +37,10 @@ func (f *fakeProjectRepository) Save(ctx context.Context, path string, data *por
 	return nil
 }
 
+func (f *fakeProjectRepository) LoadVariables(ctx context.Context, path string) (ports.VariablesConfigDTO, error) {
+	return f.LocalVariables, nil
+}
+
 func newValidMockDTO(modifiers ...func(*ports.ProjectConfigDTO)) *ports.ProjectConfigDTO {
 	// 1. Define los datos base y consistentes
 	projectName := "test-project"
@@ -156,3 +162,24 @@ func TestProjectService_Load_SaveFailsAfterIDChange(t *testing.T) {
 	assert.True(t, mockRepo.saveCalled)
 	assert.Contains(t, err.Error(), expectedError.Error())
 }
+
+func TestProjectService_Load_Variables(t *testing.T) {
+	// --- Arrange ---
+	mockRepo := &fakeProjectRepository{
+		LoadFunc: func(ctx context.Context, path string) (*ports.ProjectConfigDTO, error) {
+			return newValidMockDTO(func(dto *ports.ProjectConfigDTO) {
+				dto.Variables = ports.VariablesConfigDTO{"prod": {"deploy": {"replicas": 3}}}
+			}), nil
+		},
+		LocalVariables: ports.VariablesConfigDTO{"*": {"*": {"debug": true}}},
+	}
+	service := application.NewProjectService(mockRepo)
+
+	// --- Act ---
+	project, err := service.Load(context.Background(), "/fake/path")
+
+	// --- Assert ---
+	require.NoError(t, err)
+	assert.Equal(t, 3, project.Variables().Scope("prod", "deploy")["replicas"])
+	assert.Equal(t, true, project.LocalVariables().Scope(vos.AnyScope, vos.AnyScope)["debug"])
+}
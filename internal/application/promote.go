@@ -0,0 +1,137 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	defSvc "github.com/jairoprogramador/vex/internal/domain/definition/services"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	relVos "github.com/jairoprogramador/vex/internal/domain/release/vos"
+	verVos "github.com/jairoprogramador/vex/internal/domain/versioning/vos"
+	worAgg "github.com/jairoprogramador/vex/internal/domain/workspace/aggregates"
+)
+
+// Promote despliega en targetEnv la versión ya desplegada con éxito en sourceEnv. El
+// destino debe estar en el nivel siguiente al de origen según el 'order' de
+// environments.yaml. El paso de empaquetado no se vuelve a ejecutar: sus salidas,
+// como la etiqueta de la imagen o el digest del artefacto, se toman del origen.
+// Si version está vacía se promueve el último despliegue del origen.
+func (o *ExecutionOrchestrator) Promote(
+	ctx context.Context, sourceEnv, targetEnv, version string, opts appDto.ExecutionOptions) error {
+
+	project, err := o.loadProject(ctx, o.projectPath)
+	if err != nil {
+		return err
+	}
+	workspace, err := o.loadWorkspace(project, o.rootVexPath, opts.TemplateDir)
+	if err != nil {
+		return err
+	}
+	if err := o.cloneTemplate(ctx, project, workspace, o.projectPath); err != nil {
+		return err
+	}
+	layers, err := templateLayers(project, workspace)
+	if err != nil {
+		return err
+	}
+	environments, err := o.planBuilder.Environments(ctx, layers)
+	if err != nil {
+		return err
+	}
+	if _, err := defSvc.ValidatePromotion(environments, sourceEnv, targetEnv); err != nil {
+		return err
+	}
+
+	source, err := o.promotionSource(workspace, sourceEnv, version)
+	if err != nil {
+		return err
+	}
+	reused, err := o.promotedOutputs(workspace, source)
+	if err != nil {
+		return err
+	}
+
+	worktreePath, err := os.MkdirTemp("", "vex-promote-")
+	if err != nil {
+		return fmt.Errorf("no se pudo crear el directorio temporal para la promoción: %w", err)
+	}
+	defer os.RemoveAll(worktreePath)
+
+	commit, err := o.gitRepository.ExportRevision(ctx, o.projectPath, source.Commit(), worktreePath)
+	if err != nil {
+		return fmt.Errorf("no se pudo obtener la revisión '%s' (%s): %w", source.Version(), source.Commit(), err)
+	}
+
+	fmt.Printf("Promoviendo la versión %s de '%s' a '%s'\n", source.Version(), sourceEnv, targetEnv)
+
+	return o.executePlan(ctx, runRequest{
+		projectPath:   worktreePath,
+		stepName:      relVos.DeployStep,
+		envName:       targetEnv,
+		opts:          opts,
+		kind:          relVos.Promotion,
+		version:       &verVos.Version{Raw: source.Version()},
+		commit:        commit,
+		promotedFrom:  source.ID(),
+		reusedOutputs: reused,
+	})
+}
+
+// promotionSource busca el despliegue exitoso del entorno de origen que se promueve.
+func (o *ExecutionOrchestrator) promotionSource(
+	workspace *worAgg.Workspace, sourceEnv, version string) (*relAgg.Release, error) {
+
+	releases, err := findReleases(o.releaseRepository, workspace.ReleasesDirPath(sourceEnv))
+	if err != nil {
+		return nil, err
+	}
+	for i := len(releases) - 1; i >= 0; i-- {
+		release := releases[i]
+		if !release.IsSuccessfulDeployment() {
+			continue
+		}
+		if version == "" || release.Version() == version {
+			return release, nil
+		}
+	}
+	if version == "" {
+		return nil, fmt.Errorf("no hay despliegues exitosos registrados en el ambiente '%s'", sourceEnv)
+	}
+	return nil, fmt.Errorf("la versión '%s' no se ha desplegado con éxito en el ambiente '%s'", version, sourceEnv)
+}
+
+// promotedOutputs recupera las salidas del paso de empaquetado para el commit del
+// despliegue de origen. El paso pudo ejecutarse en una ejecución anterior del mismo
+// commit y omitirse en el despliegue, así que se buscan en todo el historial del origen.
+func (o *ExecutionOrchestrator) promotedOutputs(
+	workspace *worAgg.Workspace, source *relAgg.Release) (map[string]exeVos.VariableSet, error) {
+
+	if !source.HasStep(relVos.PackageStep) {
+		return nil, nil
+	}
+
+	releases, err := findReleases(o.releaseRepository, workspace.ReleasesDirPath(source.Environment()))
+	if err != nil {
+		return nil, err
+	}
+	for i := len(releases) - 1; i >= 0; i-- {
+		if releases[i].Commit() != source.Commit() {
+			continue
+		}
+		outputs, found := releases[i].StepOutputs(relVos.PackageStep)
+		if !found {
+			continue
+		}
+		reusable := restorableVariables(outputs).WithSource(
+			exeVos.NewVariableSource(exeVos.PromotedSource, releases[i].ID()))
+		if len(reusable) < len(outputs) {
+			fmt.Printf("ADVERTENCIA: las salidas secretas del paso '%s' no se registran y no se reutilizan\n", relVos.PackageStep)
+		}
+		return map[string]exeVos.VariableSet{relVos.PackageStep: reusable}, nil
+	}
+	return nil, fmt.Errorf("no hay salidas registradas del paso '%s' para la versión '%s' en el ambiente '%s'",
+		relVos.PackageStep, source.Version(), source.Environment())
+}
@@ -0,0 +1,300 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	pvnAgg "github.com/jairoprogramador/vex/internal/domain/provenance/aggregates"
+	pvnPrt "github.com/jairoprogramador/vex/internal/domain/provenance/ports"
+	pvnVos "github.com/jairoprogramador/vex/internal/domain/provenance/vos"
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	relVos "github.com/jairoprogramador/vex/internal/domain/release/vos"
+	staPrt "github.com/jairoprogramador/vex/internal/domain/state/ports"
+	verPrt "github.com/jairoprogramador/vex/internal/domain/versioning/ports"
+	worAgg "github.com/jairoprogramador/vex/internal/domain/workspace/aggregates"
+)
+
+const sha256DigestPrefix = pvnVos.SHA256Digest + ":"
+
+// ProvenanceService genera y verifica las declaraciones de procedencia de los despliegues.
+type ProvenanceService struct {
+	projectPath      string
+	rootVexPath      string
+	projectSvc       *ProjectService
+	workspaceSvc     *WorkspaceService
+	gitRepository    verPrt.GitRepository
+	fingerprintSvc   staPrt.FingerprintService
+	attestationStore pvnPrt.AttestationStore
+}
+
+// NewProvenanceService crea una nueva instancia de ProvenanceService.
+func NewProvenanceService(
+	projectPath string,
+	rootVexPath string,
+	projectSvc *ProjectService,
+	workspaceSvc *WorkspaceService,
+	gitRepository verPrt.GitRepository,
+	fingerprintSvc staPrt.FingerprintService,
+	attestationStore pvnPrt.AttestationStore,
+) *ProvenanceService {
+	return &ProvenanceService{
+		projectPath:      projectPath,
+		rootVexPath:      rootVexPath,
+		projectSvc:       projectSvc,
+		workspaceSvc:     workspaceSvc,
+		gitRepository:    gitRepository,
+		fingerprintSvc:   fingerprintSvc,
+		attestationStore: attestationStore,
+	}
+}
+
+// Attest firma y guarda la declaración de procedencia de un despliegue.
+// sourcePath es el directorio desplegado, donde se buscan los artefactos declarados.
+func (s *ProvenanceService) Attest(
+	ctx context.Context,
+	workspace *worAgg.Workspace,
+	release *relAgg.Release,
+	sourcePath string,
+	outputs exeVos.VariableSet,
+) (string, error) {
+	sourceURI, err := s.sourceURI(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	dependencies, err := s.resolvedDependencies(release, sourceURI)
+	if err != nil {
+		return "", err
+	}
+
+	subjects, err := s.artifactSubjects(sourcePath, outputs)
+	if err != nil {
+		return "", err
+	}
+	if len(subjects) == 0 {
+		// Sin artefactos declarados, lo desplegado es el propio commit del proyecto.
+		subjects = append(subjects, dependencies[0])
+	}
+
+	statement, err := pvnAgg.NewStatement(
+		subjects, dependencies,
+		release.Environment(), release.FinalStep(), release.Version(),
+		release.ID(), release.ToolVersion(),
+		release.StartedAt(), time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+	return s.attestationStore.Save(workspace.AttestationsDirPath(release.Environment()), statement)
+}
+
+// Verify comprueba una declaración de procedencia contra el checkout actual.
+// reference es la ruta de una declaración o el nombre de un ambiente, en cuyo caso
+// se verifica su declaración más reciente.
+func (s *ProvenanceService) Verify(ctx context.Context, reference string) (*appDto.VerificationReport, error) {
+	project, err := s.projectSvc.Load(ctx, s.projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar el proyecto: %w", err)
+	}
+	workspace, err := s.workspaceSvc.ForProject(s.rootVexPath, project, "")
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar el workspace: %w", err)
+	}
+
+	statementPath := reference
+	if info, err := os.Stat(reference); err != nil || info.IsDir() {
+		statementPath, err = s.attestationStore.FindLatest(workspace.AttestationsDirPath(reference))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	statement, signature, err := s.attestationStore.Load(statementPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &appDto.VerificationReport{
+		StatementPath: statementPath,
+		Environment:   statement.Environment(),
+		Version:       statement.Version(),
+		Checks: []appDto.VerificationCheck{{
+			Name:   "firma",
+			Passed: signature.IsValid(),
+			Detail: signature.Detail(),
+		}},
+	}
+	report.Checks = append(report.Checks,
+		s.verifyCommit(ctx, "commit del proyecto", s.projectPath, statement, pvnVos.SourceDependency))
+	for i, layer := range workspace.TemplateLayers() {
+		if layer.IsLocal() {
+			continue
+		}
+		checkName := "commit de la plantilla"
+		if i > 0 {
+			checkName = fmt.Sprintf("commit del overlay %d de la plantilla", i)
+		}
+		report.Checks = append(report.Checks,
+			s.verifyCommit(ctx, checkName, workspace.LayerPath(layer), statement, pvnVos.TemplateLayerDependency(i)))
+	}
+	for _, subject := range statement.Subjects() {
+		if _, isCommit := subject.Digest()[pvnVos.GitCommitDigest]; isCommit {
+			continue
+		}
+		report.Checks = append(report.Checks, s.verifyArtifact(subject))
+	}
+	return report, nil
+}
+
+func (s *ProvenanceService) sourceURI(ctx context.Context) (string, error) {
+	remoteURL, err := s.gitRepository.GetRemoteURL(ctx, s.projectPath)
+	if err != nil {
+		return "", err
+	}
+	if remoteURL == "" {
+		return "file://" + filepath.ToSlash(s.projectPath), nil
+	}
+	return remoteURL, nil
+}
+
+// resolvedDependencies devuelve el commit del proyecto, el de cada capa de la plantilla y el
+// digest de las instrucciones de cada paso. El commit del proyecto va siempre primero.
+func (s *ProvenanceService) resolvedDependencies(
+	release *relAgg.Release, sourceURI string) ([]pvnVos.ResourceDescriptor, error) {
+
+	source, err := pvnVos.NewResourceDescriptor(pvnVos.SourceDependency, sourceURI,
+		map[string]string{pvnVos.GitCommitDigest: release.Commit()})
+	if err != nil {
+		return nil, err
+	}
+	dependencies := []pvnVos.ResourceDescriptor{source}
+
+	templates := append([]relVos.TemplateSource{release.Template()}, release.TemplateOverlays()...)
+	for i, template := range templates {
+		if template.SHA() == "" {
+			continue
+		}
+		templateDependency, err := pvnVos.NewResourceDescriptor(pvnVos.TemplateLayerDependency(i), template.URL(),
+			map[string]string{pvnVos.GitCommitDigest: template.SHA()})
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, templateDependency)
+	}
+
+	for _, step := range release.Steps() {
+		instruction := step.Fingerprints().Instruction()
+		if instruction == "" {
+			continue
+		}
+		instructionDependency, err := pvnVos.NewResourceDescriptor(pvnVos.InstructionDependency+step.Name(), "",
+			map[string]string{pvnVos.SHA256Digest: instruction})
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, instructionDependency)
+	}
+	return dependencies, nil
+}
+
+// artifactSubjects convierte las variables 'artifact_*' en sujetos de la declaración.
+func (s *ProvenanceService) artifactSubjects(
+	sourcePath string, outputs exeVos.VariableSet) ([]pvnVos.ResourceDescriptor, error) {
+
+	names := make([]string, 0)
+	for name := range outputs {
+		if strings.HasPrefix(name, pvnVos.ArtifactVarPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	subjects := make([]pvnVos.ResourceDescriptor, 0, len(names))
+	for _, name := range names {
+		artifactVar := outputs[name]
+		value := artifactVar.Value()
+		if strings.HasPrefix(value, sha256DigestPrefix) {
+			subject, err := pvnVos.NewResourceDescriptor(strings.TrimPrefix(name, pvnVos.ArtifactVarPrefix), "",
+				map[string]string{pvnVos.SHA256Digest: strings.TrimPrefix(value, sha256DigestPrefix)})
+			if err != nil {
+				return nil, fmt.Errorf("artefacto '%s' inválido: %w", name, err)
+			}
+			subjects = append(subjects, subject)
+			continue
+		}
+
+		fingerprint, err := s.fingerprintSvc.FromFile(s.artifactPath(sourcePath, value))
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo calcular el digest del artefacto '%s': %w", name, err)
+		}
+		if fingerprint.String() == "" {
+			return nil, fmt.Errorf("el artefacto '%s' declarado en '%s' no existe", value, name)
+		}
+		subject, err := pvnVos.NewResourceDescriptor(value, "",
+			map[string]string{pvnVos.SHA256Digest: fingerprint.String()})
+		if err != nil {
+			return nil, fmt.Errorf("artefacto '%s' inválido: %w", name, err)
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects, nil
+}
+
+func (s *ProvenanceService) artifactPath(basePath, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(basePath, path)
+}
+
+func (s *ProvenanceService) verifyCommit(
+	ctx context.Context, checkName, repoPath string, statement *pvnAgg.Statement, dependencyName string) appDto.VerificationCheck {
+
+	dependency, exists := statement.Dependency(dependencyName)
+	if !exists {
+		return appDto.VerificationCheck{Name: checkName, Passed: false, Detail: "la declaración no lo registra"}
+	}
+	expected := dependency.Digest()[pvnVos.GitCommitDigest]
+
+	current, err := s.gitRepository.GetLastCommit(ctx, repoPath)
+	if err != nil {
+		return appDto.VerificationCheck{Name: checkName, Passed: false, Detail: err.Error()}
+	}
+	if current.String() != expected {
+		return appDto.VerificationCheck{
+			Name:   checkName,
+			Passed: false,
+			Detail: fmt.Sprintf("declarado %s, actual %s", expected, current.String()),
+		}
+	}
+	return appDto.VerificationCheck{Name: checkName, Passed: true, Detail: expected}
+}
+
+func (s *ProvenanceService) verifyArtifact(subject pvnVos.ResourceDescriptor) appDto.VerificationCheck {
+	checkName := "artefacto " + subject.Name()
+	expected := subject.Digest()[pvnVos.SHA256Digest]
+
+	path := s.artifactPath(s.projectPath, subject.Name())
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return appDto.VerificationCheck{Name: checkName, Skipped: true, Detail: "sin copia local; no se comprobó"}
+	}
+	fingerprint, err := s.fingerprintSvc.FromFile(path)
+	if err != nil {
+		return appDto.VerificationCheck{Name: checkName, Passed: false, Detail: err.Error()}
+	}
+	if fingerprint.String() != expected {
+		return appDto.VerificationCheck{
+			Name:   checkName,
+			Passed: false,
+			Detail: fmt.Sprintf("declarado %s, actual %s", expected, fingerprint.String()),
+		}
+	}
+	return appDto.VerificationCheck{Name: checkName, Passed: true, Detail: expected}
+}
@@ -0,0 +1,88 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	pvnAgg "github.com/jairoprogramador/vex/internal/domain/provenance/aggregates"
+	pvnVos "github.com/jairoprogramador/vex/internal/domain/provenance/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saveStatement guarda una declaración cuyos commits coinciden con los del checkout
+// del harness y que declara el artefacto dist/app.tar.gz.
+func saveStatement(t *testing.T, h *orchestratorHarness) {
+	t.Helper()
+	commit := map[string]string{pvnVos.GitCommitDigest: "0123456789abcdef0123456789abcdef01234567"}
+	source, err := pvnVos.NewResourceDescriptor(pvnVos.SourceDependency, "file://proyecto", commit)
+	require.NoError(t, err)
+	template, err := pvnVos.NewResourceDescriptor(pvnVos.TemplateDependency, "https://github.com/jairo/template.git", commit)
+	require.NoError(t, err)
+	// fakeFingerprintService devuelve 'file' como digest de cualquier archivo.
+	artifact, err := pvnVos.NewResourceDescriptor("dist/app.tar.gz", "", map[string]string{pvnVos.SHA256Digest: "file"})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	statement, err := pvnAgg.NewStatement([]pvnVos.ResourceDescriptor{artifact},
+		[]pvnVos.ResourceDescriptor{source, template}, "sand", "deploy", "v1.0.0", "release-id", "test", now, now)
+	require.NoError(t, err)
+	_, err = h.attestations.Save(t.TempDir(), statement)
+	require.NoError(t, err)
+}
+
+func findCheck(t *testing.T, checks []appDto.VerificationCheck, name string) appDto.VerificationCheck {
+	t.Helper()
+	for _, check := range checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("no hay una comprobación '%s'", name)
+	return appDto.VerificationCheck{}
+}
+
+func TestVerify_PassesWhenEveryCheckMatches(t *testing.T) {
+	h := newOrchestratorHarness(t)
+	saveStatement(t, h)
+	artifactPath := filepath.Join(h.projectPath, "dist", "app.tar.gz")
+	require.NoError(t, os.MkdirAll(filepath.Dir(artifactPath), 0755))
+	require.NoError(t, os.WriteFile(artifactPath, []byte("app"), 0644))
+
+	report, err := h.provenance.Verify(context.Background(), "sand")
+
+	require.NoError(t, err)
+	assert.True(t, report.Passed(false))
+}
+
+func TestVerify_SkipsMissingArtifactsWithoutPassingThem(t *testing.T) {
+	h := newOrchestratorHarness(t)
+	saveStatement(t, h)
+
+	report, err := h.provenance.Verify(context.Background(), "sand")
+
+	require.NoError(t, err)
+	artifact := findCheck(t, report.Checks, "artefacto dist/app.tar.gz")
+	assert.True(t, artifact.Skipped)
+	assert.False(t, artifact.Passed)
+	assert.False(t, report.Passed(false), "un artefacto sin comprobar no debe aceptarse por defecto")
+	assert.True(t, report.Passed(true))
+}
+
+func TestVerify_ReportsTheSignatureCheckOfTheStore(t *testing.T) {
+	h := newOrchestratorHarness(t)
+	saveStatement(t, h)
+	h.attestations.signature = pvnVos.NewSignatureCheck(false, "ninguna firma corresponde a la clave local")
+
+	report, err := h.provenance.Verify(context.Background(), "sand")
+
+	require.NoError(t, err)
+	signature := findCheck(t, report.Checks, "firma")
+	assert.False(t, signature.Passed)
+	assert.Equal(t, "ninguna firma corresponde a la clave local", signature.Detail)
+	assert.False(t, report.Passed(true))
+}
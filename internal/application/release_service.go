@@ -0,0 +1,100 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	relPrt "github.com/jairoprogramador/vex/internal/domain/release/ports"
+)
+
+// ReleaseService consulta los manifiestos de las ejecuciones registradas de un proyecto.
+type ReleaseService struct {
+	projectPath       string
+	rootVexPath       string
+	projectSvc        *ProjectService
+	workspaceSvc      *WorkspaceService
+	releaseRepository relPrt.ReleaseRepository
+}
+
+// NewReleaseService crea una nueva instancia de ReleaseService.
+func NewReleaseService(
+	projectPath string,
+	rootVexPath string,
+	projectSvc *ProjectService,
+	workspaceSvc *WorkspaceService,
+	releaseRepository relPrt.ReleaseRepository,
+) *ReleaseService {
+	return &ReleaseService{
+		projectPath:       projectPath,
+		rootVexPath:       rootVexPath,
+		projectSvc:        projectSvc,
+		workspaceSvc:      workspaceSvc,
+		releaseRepository: releaseRepository,
+	}
+}
+
+// List devuelve las ejecuciones registradas para un ambiente, de la más reciente a la más antigua.
+func (s *ReleaseService) List(ctx context.Context, envName string) ([]*relAgg.Release, error) {
+	project, err := s.projectSvc.Load(ctx, s.projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar el proyecto: %w", err)
+	}
+	workspace, err := s.workspaceSvc.ForProject(s.rootVexPath, project, "")
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar el workspace: %w", err)
+	}
+
+	releases, err := findReleases(s.releaseRepository, workspace.ReleasesDirPath(envName))
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(releases)-1; i < j; i, j = i+1, j-1 {
+		releases[i], releases[j] = releases[j], releases[i]
+	}
+	return releases, nil
+}
+
+// Find busca una ejecución por su id (o un prefijo de él) o por su versión.
+// Si varias ejecuciones tienen la misma versión, devuelve la más reciente.
+func (s *ReleaseService) Find(ctx context.Context, envName, reference string) (*relAgg.Release, error) {
+	releases, err := s.List(ctx, envName)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		if release.Version() == reference || strings.HasPrefix(release.ID(), reference) {
+			return release, nil
+		}
+	}
+	return nil, fmt.Errorf("no hay una ejecución '%s' registrada en el ambiente '%s'", reference, envName)
+}
+
+// LatestWithVariables devuelve la ejecución más reciente del ambiente que registró
+// la resolución de las variables de algún paso.
+func (s *ReleaseService) LatestWithVariables(ctx context.Context, envName string) (*relAgg.Release, error) {
+	releases, err := s.List(ctx, envName)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		if release.HasVariableTraces() {
+			return release, nil
+		}
+	}
+	return nil, fmt.Errorf("no hay ejecuciones con pasos ejecutados registradas en el ambiente '%s'", envName)
+}
+
+// findReleases lee las ejecuciones registradas en dirPath y avisa de los registros
+// que no se pudieron leer, que se omiten.
+func findReleases(releaseRepository relPrt.ReleaseRepository, dirPath string) ([]*relAgg.Release, error) {
+	releases, skipped, err := releaseRepository.FindAll(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, skippedErr := range skipped {
+		fmt.Printf("ADVERTENCIA: se omitió un registro de ejecución. Error: %v\n", skippedErr)
+	}
+	return releases, nil
+}
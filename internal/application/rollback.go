@@ -0,0 +1,166 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	defEnt "github.com/jairoprogramador/vex/internal/domain/definition/entities"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	relVos "github.com/jairoprogramador/vex/internal/domain/release/vos"
+	verVos "github.com/jairoprogramador/vex/internal/domain/versioning/vos"
+)
+
+// runtimeVariables son las variables que el orquestador calcula en cada ejecución.
+// No se restauran desde el registro de una ejecución anterior porque dependen
+// del directorio de trabajo o de la revisión que se está desplegando.
+var runtimeVariables = map[string]struct{}{
+	"project_workdir":       {},
+	"project_version":       {},
+	"project_revision":      {},
+	"project_revision_full": {},
+	"environment":           {},
+	"step_workdir":          {},
+	"shared_workdir":        {},
+	exeVos.FailedCommandVar: {},
+	exeVos.FailureReasonVar: {},
+}
+
+// ListDeployments devuelve los despliegues exitosos registrados para un ambiente,
+// del más reciente al más antiguo.
+func (o *ExecutionOrchestrator) ListDeployments(ctx context.Context, envName string) ([]*relAgg.Release, error) {
+	project, err := o.loadProject(ctx, o.projectPath)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := o.loadWorkspace(project, o.rootVexPath, "")
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := findReleases(o.releaseRepository, workspace.ReleasesDirPath(envName))
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := make([]*relAgg.Release, 0, len(releases))
+	for i := len(releases) - 1; i >= 0; i-- {
+		if releases[i].IsSuccessfulDeployment() {
+			deployments = append(deployments, releases[i])
+		}
+	}
+	return deployments, nil
+}
+
+// RollbackTo vuelve a desplegar la revisión de una ejecución anterior.
+// La revisión se exporta a un directorio temporal y el plan de despliegue se
+// ejecuta contra ella partiendo de las variables que estaban vigentes en esa
+// ejecución. Los secretos no se registran: si un paso los usó, hay que darlos
+// de nuevo con --var o --var-file.
+func (o *ExecutionOrchestrator) RollbackTo(
+	ctx context.Context, release *relAgg.Release, opts appDto.ExecutionOptions) error {
+	if !release.IsSuccessfulDeployment() {
+		return fmt.Errorf("la ejecución '%s' no es un despliegue exitoso", release.ID())
+	}
+
+	worktreePath, err := os.MkdirTemp("", "vex-rollback-")
+	if err != nil {
+		return fmt.Errorf("no se pudo crear el directorio temporal para el rollback: %w", err)
+	}
+	defer os.RemoveAll(worktreePath)
+
+	commit, err := o.gitRepository.ExportRevision(ctx, o.projectPath, release.Version(), worktreePath)
+	if err != nil {
+		commit, err = o.gitRepository.ExportRevision(ctx, o.projectPath, release.Commit(), worktreePath)
+		if err != nil {
+			return fmt.Errorf("no se pudo obtener la revisión '%s' (%s): %w", release.Version(), release.Commit(), err)
+		}
+	}
+
+	fmt.Printf("Revirtiendo el ambiente '%s' a la versión %s\n", release.Environment(), release.Version())
+
+	return o.executePlan(ctx, runRequest{
+		projectPath: worktreePath,
+		stepName:    release.FinalStep(),
+		envName:     release.Environment(),
+		opts:        opts,
+		kind:        relVos.Rollback,
+		version:     &verVos.Version{Raw: release.Version()},
+		commit:      commit,
+		rollbackOf:  release.ID(),
+		restored: restorableVariables(release.Variables()).WithSource(
+			exeVos.NewVariableSource(exeVos.RestoredSource, release.ID())),
+		restoredSecrets: secretInputs(release),
+	})
+}
+
+func restorableVariables(recorded map[string]string) exeVos.VariableSet {
+	restorable := make(map[string]string, len(recorded))
+	for name, value := range recorded {
+		if _, isRuntime := runtimeVariables[name]; isRuntime {
+			continue
+		}
+		// Los secretos no se guardan en el manifiesto: se resuelven de nuevo desde su origen.
+		if value == exeVos.RedactedValue {
+			continue
+		}
+		restorable[name] = value
+	}
+	return exeVos.NewVariableSetFromMap(restorable)
+}
+
+// secretInputs devuelve, por paso, las variables secretas con las que se ejecutó cada
+// paso de una ejecución, sin contar las que produjo el propio paso.
+func secretInputs(release *relAgg.Release) map[string][]string {
+	secrets := make(map[string][]string)
+	for _, step := range release.Steps() {
+		outputs := step.Outputs()
+		for _, trace := range step.Variables() {
+			if trace.Value() != exeVos.RedactedValue {
+				continue
+			}
+			if _, isRuntime := runtimeVariables[trace.Name()]; isRuntime {
+				continue
+			}
+			if isStepOutput(step.Name(), trace.Name(), outputs) {
+				continue
+			}
+			secrets[step.Name()] = append(secrets[step.Name()], trace.Name())
+		}
+	}
+	return secrets
+}
+
+func isStepOutput(stepName, name string, outputs map[string]string) bool {
+	for output := range outputs {
+		if name == output || name == exeVos.StepOutputName(stepName, output) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSecretInputs falla si alguna de las variables secretas con las que se ejecutó
+// el paso en la ejecución que se revierte no tiene valor en esta ejecución.
+func checkSecretInputs(stepDef *defEnt.StepDefinition, secrets []string, vars exeVos.VariableSet) error {
+	defined := make(map[string]bool, len(stepDef.VariablesDef()))
+	for _, variable := range stepDef.VariablesDef() {
+		defined[variable.Name()] = true
+	}
+
+	missing := make([]string, 0, len(secrets))
+	for _, name := range secrets {
+		if _, found := vars.Get(name); found || defined[name] {
+			continue
+		}
+		missing = append(missing, name)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("el paso '%s' se ejecutó con variables secretas que el historial no guarda: %s; indica su valor con --var o --var-file",
+		stepDef.NameDef().Name(), strings.Join(missing, ", "))
+}
@@ -0,0 +1,135 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	relVos "github.com/jairoprogramador/vex/internal/domain/release/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedStep es un paso de un despliegue registrado: las variables con las que se
+// ejecutó y sus salidas, con los secretos ya ocultos.
+type recordedStep struct {
+	name      string
+	variables map[string]string
+	outputs   map[string]string
+}
+
+func newRecordedDeployment(t *testing.T, variables map[string]string, steps ...recordedStep) *relAgg.Release {
+	t.Helper()
+	release, err := relAgg.NewRelease("sand", relVos.DeployStep, "v0.9.0",
+		"fedcba9876543210fedcba9876543210fedcba98", relVos.Execution)
+	require.NoError(t, err)
+	for _, step := range steps {
+		require.NoError(t, release.StartStep(step.name))
+		traces := make([]relVos.VariableTrace, 0, len(step.variables))
+		for name, value := range step.variables {
+			traces = append(traces, relVos.NewVariableTrace(name, []relVos.VariableOrigin{relVos.NewVariableOrigin(value, "--var")}))
+		}
+		release.RecordVariables(traces)
+		release.CompleteStep(step.outputs)
+	}
+	release.Finish(nil, variables)
+	return release
+}
+
+func newRollbackHarness(t *testing.T) *orchestratorHarness {
+	t.Helper()
+	return newOrchestratorHarness(t, newTestStep(t, "01-build"), newTestStep(t, "02-deploy"))
+}
+
+func TestRollbackTo_RestoresRecordedVariablesOnlyAsInitialInputs(t *testing.T) {
+	h := newRollbackHarness(t)
+	// La salida guardada es la de la versión que se revierte.
+	workspace := h.workspace(t)
+	require.NoError(t, h.vars.Save(workspace.VarsFilePath("sand", "build"),
+		exeVos.NewVariableSetFromMap(map[string]string{"image": "app:current"})))
+	h.executor.outputs["build"] = exeVos.NewVariableSetFromMap(map[string]string{"image": "app:rebuilt"})
+	release := newRecordedDeployment(t,
+		map[string]string{"image": "app:old", "step.build.image": "app:old", "replicas": "2"},
+		recordedStep{name: "build", outputs: map[string]string{"image": "app:old"}},
+		recordedStep{name: "deploy"})
+
+	err := h.orchestrator.RollbackTo(context.Background(), release, appDto.ExecutionOptions{TemplateDir: h.templateDir})
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"build", "deploy"}, h.executor.executed)
+	buildImage, _ := h.executor.inputsFor["build"].Get("image")
+	assert.Equal(t, "app:old", buildImage.Value(), "el valor registrado debe reemplazar a la salida guardada")
+
+	deployInputs := h.executor.inputsFor["deploy"]
+	image, _ := deployInputs.Get("image")
+	assert.Equal(t, "app:rebuilt", image.Value(), "la salida del paso debe reemplazar al valor registrado")
+	namespacedImage, _ := deployInputs.Get("step.build.image")
+	assert.Equal(t, "app:rebuilt", namespacedImage.Value())
+	replicas, found := deployInputs.Get("replicas")
+	require.True(t, found)
+	assert.Equal(t, "2", replicas.Value())
+}
+
+func TestRollbackTo_FailsWhenASecretTheStepUsedIsMissing(t *testing.T) {
+	h := newRollbackHarness(t)
+	release := newRecordedDeployment(t,
+		map[string]string{"api_token": exeVos.RedactedValue},
+		recordedStep{name: "build", variables: map[string]string{"api_token": exeVos.RedactedValue}},
+		recordedStep{name: "deploy"})
+
+	err := h.orchestrator.RollbackTo(context.Background(), release, appDto.ExecutionOptions{TemplateDir: h.templateDir})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "api_token")
+	assert.Empty(t, h.executor.executed)
+}
+
+func TestRollbackTo_UsesSecretsGivenOnTheCommandLine(t *testing.T) {
+	h := newRollbackHarness(t)
+	release := newRecordedDeployment(t,
+		map[string]string{"api_token": exeVos.RedactedValue},
+		recordedStep{name: "build", variables: map[string]string{"api_token": exeVos.RedactedValue}},
+		recordedStep{name: "deploy"})
+
+	err := h.orchestrator.RollbackTo(context.Background(), release, appDto.ExecutionOptions{
+		TemplateDir: h.templateDir,
+		Variables:   map[string]string{"api_token": "s3cr3t"},
+	})
+
+	require.NoError(t, err)
+	token, _ := h.executor.inputsFor["build"].Get("api_token")
+	assert.Equal(t, "s3cr3t", token.Value())
+}
+
+func TestRollbackTo_DoesNotRequireSecretsTheStepProduces(t *testing.T) {
+	h := newRollbackHarness(t)
+	h.executor.outputs["build"] = exeVos.NewVariableSetFromMap(map[string]string{"db_password": "nueva"})
+	release := newRecordedDeployment(t,
+		map[string]string{"db_password": exeVos.RedactedValue},
+		recordedStep{
+			name:      "build",
+			variables: map[string]string{"db_password": exeVos.RedactedValue, "step.build.db_password": exeVos.RedactedValue},
+			outputs:   map[string]string{"db_password": exeVos.RedactedValue},
+		},
+		recordedStep{name: "deploy", variables: map[string]string{"db_password": exeVos.RedactedValue}})
+
+	err := h.orchestrator.RollbackTo(context.Background(), release, appDto.ExecutionOptions{TemplateDir: h.templateDir})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build", "deploy"}, h.executor.executed)
+}
+
+func TestRollbackTo_RejectsAReleaseThatIsNotASuccessfulDeployment(t *testing.T) {
+	h := newRollbackHarness(t)
+	release, err := relAgg.NewRelease("sand", "build", "v0.9.0",
+		"fedcba9876543210fedcba9876543210fedcba98", relVos.Execution)
+	require.NoError(t, err)
+	release.Finish(nil, nil)
+
+	err = h.orchestrator.RollbackTo(context.Background(), release, appDto.ExecutionOptions{TemplateDir: h.templateDir})
+
+	require.Error(t, err)
+	assert.Empty(t, h.executor.executed)
+}
@@ -0,0 +1,151 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	logAgg "github.com/jairoprogramador/vex/internal/domain/logger/aggregates"
+	logEnt "github.com/jairoprogramador/vex/internal/domain/logger/entities"
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	relVos "github.com/jairoprogramador/vex/internal/domain/release/vos"
+)
+
+// runRecorder registra una ejecución en el log del ambiente, paso a paso, y los hooks
+// de cada paso también en su manifiesto. La ejecución muestra su propio progreso, así
+// que el log solo se guarda; los hooks se muestran con el presentador del log.
+type runRecorder struct {
+	release   *relAgg.Release
+	loggerSvc *LoggerService
+	log       *logAgg.Logger
+	names     appDto.NamesParams
+	current   *logEnt.StepRecord
+}
+
+// startStep añade un paso al log. Queda pendiente hasta que se ejecuta o se omite.
+func (r *runRecorder) startStep(stepName string) {
+	r.current = nil
+	if r.loggerSvc == nil {
+		return
+	}
+	step, err := logEnt.NewStepRecord(stepName)
+	if err == nil {
+		err = r.log.AddStep(step)
+	}
+	if err != nil {
+		fmt.Printf("ADVERTENCIA: no se pudo registrar el paso '%s' en el log. Error: %v\n", stepName, err)
+		return
+	}
+	r.current = step
+	r.save()
+}
+
+// cacheStep marca el paso actual como omitido porque su estado no cambió.
+func (r *runRecorder) cacheStep(reason string) {
+	r.updateStep(func(step *logEnt.StepRecord) { step.MarkAsCached(reason) })
+}
+
+// skipStep marca el paso actual como omitido porque reutiliza salidas de otro ambiente.
+func (r *runRecorder) skipStep() {
+	r.updateStep(func(step *logEnt.StepRecord) { step.MarkAsSkipped() })
+}
+
+func (r *runRecorder) runStep() {
+	r.updateStep(func(step *logEnt.StepRecord) { step.MarkAsRunning() })
+}
+
+func (r *runRecorder) completeStep() {
+	r.updateStep(func(step *logEnt.StepRecord) { step.MarkAsSuccess() })
+}
+
+// failStep marca como fallido el paso actual, aunque el fallo ocurra antes de ejecutarlo.
+// Un paso que ya terminó no cambia.
+func (r *runRecorder) failStep(cause error) {
+	r.updateStep(func(step *logEnt.StepRecord) {
+		step.MarkAsRunning()
+		step.MarkAsFailure(cause)
+	})
+}
+
+// finish recalcula el estado del log con el de sus pasos y lo guarda.
+func (r *runRecorder) finish() {
+	if r.loggerSvc == nil {
+		return
+	}
+	r.log.RecalculateStatus()
+	r.save()
+}
+
+func (r *runRecorder) updateStep(update func(*logEnt.StepRecord)) {
+	if r.current == nil {
+		return
+	}
+	update(r.current)
+	r.save()
+}
+
+func (r *runRecorder) save() {
+	if err := r.loggerSvc.SaveLog(r.names, r.log); err != nil {
+		fmt.Printf("ADVERTENCIA: no se pudo guardar el log de la ejecución. Error: %v\n", err)
+	}
+}
+
+func (r *runRecorder) record(stepName string, hooks []exeVos.HookResult) {
+	if len(hooks) == 0 {
+		return
+	}
+
+	hookRuns := make([]relVos.HookRun, 0, len(hooks))
+	for _, hook := range hooks {
+		errorMsg := ""
+		if hook.Error != nil {
+			errorMsg = hook.Error.Error()
+		}
+		hookRuns = append(hookRuns, relVos.NewHookRun(
+			string(hook.Kind), hook.Command, hookSucceeded(hook), errorMsg))
+	}
+	r.release.RecordHooks(stepName, hookRuns)
+
+	if r.loggerSvc == nil {
+		return
+	}
+	records := make([]*logEnt.TaskRecord, 0, len(hooks))
+	for _, hook := range hooks {
+		record, err := hookTaskRecord(hook)
+		if err != nil {
+			fmt.Printf("ADVERTENCIA: no se pudo registrar el hook del paso '%s'. Error: %v\n", stepName, err)
+			return
+		}
+		records = append(records, record)
+	}
+	if err := r.loggerSvc.RecordHooks(r.names, r.log, stepName, records); err != nil {
+		fmt.Printf("ADVERTENCIA: no se pudieron guardar los hooks del paso '%s' en el log. Error: %v\n", stepName, err)
+	}
+}
+
+func hookSucceeded(hook exeVos.HookResult) bool {
+	return hook.Error == nil && hook.Status != exeVos.Failure
+}
+
+// hookTaskRecord representa un comando de un hook como una tarea del log cuyo
+// nombre es el tipo de hook.
+func hookTaskRecord(hook exeVos.HookResult) (*logEnt.TaskRecord, error) {
+	record, err := logEnt.NewTaskRecord(string(hook.Kind))
+	if err != nil {
+		return nil, err
+	}
+	record.SetCommand(hook.Command)
+	record.MarkAsRunning()
+	for _, line := range strings.Split(strings.TrimRight(hook.Logs, "\n"), "\n") {
+		if line != "" {
+			record.AddOutput(line)
+		}
+	}
+	if hookSucceeded(hook) {
+		record.MarkAsSuccess()
+	} else {
+		record.MarkAsFailure(hook.Error)
+	}
+	return record, nil
+}
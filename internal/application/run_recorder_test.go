@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	logAgg "github.com/jairoprogramador/vex/internal/domain/logger/aggregates"
+	logEnt "github.com/jairoprogramador/vex/internal/domain/logger/entities"
+	logVos "github.com/jairoprogramador/vex/internal/domain/logger/vos"
+	relAgg "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	relVos "github.com/jairoprogramador/vex/internal/domain/release/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLoggerRepository guarda cada log bajo sus nombres, como el repositorio de archivos.
+type memoryLoggerRepository struct {
+	logs map[appDto.NamesParams]logAgg.Logger
+}
+
+func newMemoryLoggerRepository() *memoryLoggerRepository {
+	return &memoryLoggerRepository{logs: map[appDto.NamesParams]logAgg.Logger{}}
+}
+
+func (r *memoryLoggerRepository) Save(names appDto.NamesParams, log *logAgg.Logger) error {
+	r.logs[names] = *log
+	return nil
+}
+
+func (r *memoryLoggerRepository) Find(names appDto.NamesParams) (logAgg.Logger, error) {
+	log, exists := r.logs[names]
+	if !exists {
+		return logAgg.Logger{}, fmt.Errorf("no se pudo leer el log: %w", os.ErrNotExist)
+	}
+	return log, nil
+}
+
+type fakePresenter struct {
+	steps []string
+	hooks []string
+}
+
+func (p *fakePresenter) Line()                                       {}
+func (p *fakePresenter) Header(*logAgg.Logger, string)               {}
+func (p *fakePresenter) Task(*logEnt.TaskRecord, *logEnt.StepRecord) {}
+func (p *fakePresenter) FinalSummary(*logAgg.Logger)                 {}
+func (p *fakePresenter) Step(step *logEnt.StepRecord) {
+	p.steps = append(p.steps, step.Name()+":"+step.Status().String())
+}
+func (p *fakePresenter) Hook(hook *logEnt.TaskRecord, _ *logEnt.StepRecord) {
+	p.hooks = append(p.hooks, hook.Name()+":"+hook.Command())
+}
+
+func TestRunRecorder(t *testing.T) {
+	release, err := relAgg.NewRelease("sand", relVos.DeployStep, "v1.0.0", "abc123", relVos.Execution)
+	require.NoError(t, err)
+	require.NoError(t, release.StartStep("deploy"))
+	repository := newMemoryLoggerRepository()
+	presenter := &fakePresenter{}
+	log := logAgg.NewLogger(nil, release.ID())
+	log.Start()
+	recorder := &runRecorder{
+		release:   release,
+		loggerSvc: NewLoggerService(repository, nil, presenter),
+		log:       log,
+		names:     appDto.NewNamesParams("app", "tpl").WithEnvironment("sand"),
+	}
+
+	recorder.startStep("build")
+	recorder.cacheStep("sin cambios")
+	recorder.startStep("deploy")
+	recorder.runStep()
+	recorder.record("deploy", []exeVos.HookResult{
+		{Kind: exeVos.OnFailureHook, Command: "unlock", Status: exeVos.Success, Logs: "released\n"},
+		{Kind: exeVos.FinallyHook, Command: "notify", Status: exeVos.Failure, Error: errors.New("timeout")},
+	})
+	recorder.failStep(errors.New("deploy falló"))
+	recorder.finish()
+
+	t.Run("should record the hooks in the release step", func(t *testing.T) {
+		hooks := release.Steps()[0].Hooks()
+		require.Len(t, hooks, 2)
+		assert.Equal(t, "on_failure", hooks[0].Kind())
+		assert.True(t, hooks[0].Succeeded())
+		assert.False(t, hooks[1].Succeeded())
+		assert.Equal(t, "timeout", hooks[1].ErrorMessage())
+	})
+
+	t.Run("should record the hooks in the run log and present them", func(t *testing.T) {
+		step, err := log.GetStep("deploy")
+		require.NoError(t, err)
+		require.Len(t, step.Hooks(), 2)
+		assert.Empty(t, step.Tasks())
+		assert.Equal(t, "released\n", step.Hooks()[0].OutputString())
+		assert.Equal(t, logVos.Failure, step.Hooks()[1].Status())
+		assert.Equal(t, []string{"on_failure:unlock", "finally:notify"}, presenter.hooks)
+	})
+
+	t.Run("should record every step with its status", func(t *testing.T) {
+		build, err := log.GetStep("build")
+		require.NoError(t, err)
+		assert.Equal(t, logVos.Cached, build.Status())
+		deploy, err := log.GetStep("deploy")
+		require.NoError(t, err)
+		assert.Equal(t, logVos.Failure, deploy.Status())
+		assert.Equal(t, logVos.Failure, log.Status())
+		assert.Empty(t, presenter.steps, "la ejecución muestra su propio progreso")
+	})
+}
+
+func TestExecutePlan_RunLogCanBeReadWithShowLog(t *testing.T) {
+	h := newOrchestratorHarness(t,
+		newTestStep(t, "01-build"),
+		newTestStep(t, "02-deploy"),
+	)
+	h.executor.hooks["deploy"] = []exeVos.HookResult{
+		{Kind: exeVos.FinallyHook, Command: "notify", Status: exeVos.Success, Logs: "enviado\n"},
+	}
+	repository := newMemoryLoggerRepository()
+	h.orchestrator.loggerSvc = NewLoggerService(repository, &fakeProjectConfigRepository{}, nil)
+
+	err := h.orchestrator.ExecutePlan(context.Background(), "deploy", "sand",
+		appDto.ExecutionOptions{TemplateDir: h.templateDir})
+	require.NoError(t, err)
+
+	presenter := &fakePresenter{}
+	logService := NewLoggerService(repository, &fakeProjectConfigRepository{}, presenter)
+
+	require.NoError(t, logService.ShowLog(h.projectPath, "sand"))
+	assert.Equal(t, []string{"build:Success", "deploy:Success"}, presenter.steps)
+	assert.Equal(t, []string{"finally:notify"}, presenter.hooks)
+
+	err = logService.ShowLog(h.projectPath, "prod")
+	assert.ErrorContains(t, err, "no hay ejecuciones registradas en el ambiente 'prod'")
+}
@@ -0,0 +1,294 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	defAgg "github.com/jairoprogramador/vex/internal/domain/definition/aggregates"
+	defEnt "github.com/jairoprogramador/vex/internal/domain/definition/entities"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	proAgg "github.com/jairoprogramador/vex/internal/domain/project/aggregates"
+	staVos "github.com/jairoprogramador/vex/internal/domain/state/vos"
+	worAgg "github.com/jairoprogramador/vex/internal/domain/workspace/aggregates"
+)
+
+// InspectState devuelve las tablas de estado del workspace, o solo la de stepName si
+// no está vacío, comparando cada entrada con las huellas actuales del paso en su
+// entorno. Las huellas actuales no incluyen variables de la línea de comandos y toman
+// las salidas de los pasos anteriores de las variables guardadas.
+func (o *ExecutionOrchestrator) InspectState(
+	ctx context.Context, stepName string, opts appDto.ExecutionOptions) ([]appDto.StepState, error) {
+
+	project, err := o.loadProject(ctx, o.projectPath)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := o.loadWorkspace(project, o.rootVexPath, opts.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.cloneTemplate(ctx, project, workspace, o.projectPath); err != nil {
+		return nil, err
+	}
+
+	stateTablePaths, err := o.stateTablePaths(workspace, stepName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un plan por entorno basta para comparar todas las tablas.
+	plans := make(map[string]*defAgg.ExecutionPlanDefinition)
+	planErrors := make(map[string]error)
+	planOf := func(environment string) (*defAgg.ExecutionPlanDefinition, error) {
+		if _, built := plans[environment]; !built && planErrors[environment] == nil {
+			plans[environment], planErrors[environment] = o.buildPlan(ctx, project, workspace, "", environment)
+		}
+		return plans[environment], planErrors[environment]
+	}
+
+	states := make([]appDto.StepState, 0, len(stateTablePaths))
+	for step, stateTablePath := range stateTablePaths {
+		stateTable, err := o.stateManager.GetState(stateTablePath)
+		if err != nil {
+			return nil, err
+		}
+		if stateTable == nil || len(stateTable.Entries()) == 0 {
+			continue
+		}
+
+		state := appDto.StepState{Step: step}
+		for _, entry := range stateTable.Entries() {
+			environment := entry.Environment().String()
+			status := appDto.StateEntryStatus{
+				Environment: environment,
+				CreatedAt:   entry.CreatedAt(),
+				Code:        entry.Code().String(),
+				Instruction: entry.Instruction().String(),
+				Vars:        entry.Vars().String(),
+			}
+
+			current, err := o.currentStepFingerprints(project, workspace, planOf, environment, step)
+			if err != nil {
+				status.CompareError = err.Error()
+			} else {
+				status.Compared = true
+				for _, kind := range entry.ChangedFingerprints(current) {
+					status.Changed = append(status.Changed, string(kind))
+				}
+			}
+			state.Entries = append(state.Entries, status)
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Step < states[j].Step })
+	return states, nil
+}
+
+func (o *ExecutionOrchestrator) currentStepFingerprints(
+	project *proAgg.Project, workspace *worAgg.Workspace,
+	planOf func(string) (*defAgg.ExecutionPlanDefinition, error),
+	environment, step string) (staVos.CurrentStateFingerprints, error) {
+
+	planDef, err := planOf(environment)
+	if err != nil {
+		return staVos.CurrentStateFingerprints{}, err
+	}
+	stepDef := findStepDefinition(planDef, step)
+	if stepDef == nil {
+		return staVos.CurrentStateFingerprints{}, fmt.Errorf("el paso '%s' ya no existe en la plantilla", step)
+	}
+	overrides := exeVos.NewVariableSet()
+	inputs, err := o.stepInputs(project, workspace, planDef, step, overrides)
+	if err != nil {
+		return staVos.CurrentStateFingerprints{}, err
+	}
+	return o.generateStepFingerprints(o.projectPath, workspace, environment, stepDef, inputs, overrides)
+}
+
+// stepInputs reconstruye, sin ejecutar nada, las variables disponibles antes de
+// ejecutar un paso: las del proyecto y las guardadas de los pasos anteriores del
+// plan, con overrides por encima, en el mismo orden que la ejecución.
+func (o *ExecutionOrchestrator) stepInputs(
+	project *proAgg.Project, workspace *worAgg.Workspace,
+	planDef *defAgg.ExecutionPlanDefinition,
+	step string, overrides exeVos.VariableSet) (exeVos.VariableSet, error) {
+
+	environment := planDef.Environment().String()
+	inputs := make(exeVos.VariableSet)
+	inputs.AddAll(o.prepareProjectVariables(project))
+	inputs.AddAll(overrides)
+	for _, stepDef := range planDef.Steps() {
+		name := stepDef.NameDef().Name()
+		if name == step {
+			break
+		}
+		for _, scope := range []string{environment, exeVos.SharedScope} {
+			stored, err := o.varsRepository.Get(workspace.VarsFilePath(scope, name))
+			if err != nil {
+				return nil, fmt.Errorf("error al obtener las variables del paso '%s' en el entorno '%s': %w", name, scope, err)
+			}
+			stored = stored.WithSource(exeVos.NewVariableSource(exeVos.StoredSource, scope+"/"+name))
+			inputs.AddAll(stored)
+			inputs.AddAll(stored.Namespaced(name))
+			inputs.AddAll(overrides)
+		}
+	}
+	return inputs, nil
+}
+
+func findStepDefinition(planDef *defAgg.ExecutionPlanDefinition, step string) *defEnt.StepDefinition {
+	for _, stepDef := range planDef.Steps() {
+		if stepDef.NameDef().Name() == step {
+			return stepDef
+		}
+	}
+	return nil
+}
+
+// Why explica por qué un paso se volverá a ejecutar en un entorno: qué huellas,
+// archivos del proyecto y variables cambiaron respecto a la entrada de estado más
+// reciente del entorno.
+func (o *ExecutionOrchestrator) Why(
+	ctx context.Context, stepName, envName string, opts appDto.ExecutionOptions) (appDto.StepDiagnosis, error) {
+
+	diagnosis := appDto.StepDiagnosis{Step: stepName, Environment: envName}
+	project, err := o.loadProject(ctx, o.projectPath)
+	if err != nil {
+		return diagnosis, err
+	}
+	workspace, err := o.loadWorkspace(project, o.rootVexPath, opts.TemplateDir)
+	if err != nil {
+		return diagnosis, err
+	}
+	if err := o.cloneTemplate(ctx, project, workspace, o.projectPath); err != nil {
+		return diagnosis, err
+	}
+	planDef, err := o.buildPlan(ctx, project, workspace, "", envName)
+	if err != nil {
+		return diagnosis, err
+	}
+	stepDef := findStepDefinition(planDef, stepName)
+	if stepDef == nil {
+		return diagnosis, fmt.Errorf("el paso '%s' no existe en la plantilla", stepName)
+	}
+
+	overrides := commandLineVariables(opts)
+	inputs, err := o.stepInputs(project, workspace, planDef, stepName, overrides)
+	if err != nil {
+		return diagnosis, err
+	}
+	current, err := o.generateStepFingerprints(o.projectPath, workspace, envName, stepDef, inputs, overrides)
+	if err != nil {
+		return diagnosis, fmt.Errorf("error al generar fingerprint para el paso '%s': %w", stepName, err)
+	}
+	stateTablePath, err := workspace.StateTablePath(stepName)
+	if err != nil {
+		return diagnosis, err
+	}
+	diagnosis.Rerun, err = o.stateManager.HasStateChanged(stateTablePath, current, staVos.NewCachePolicy(0))
+	if err != nil {
+		return diagnosis, err
+	}
+	stateTable, err := o.stateManager.GetState(stateTablePath)
+	if err != nil || stateTable == nil {
+		return diagnosis, err
+	}
+	baseline := stateTable.LatestFor(envName)
+	if baseline == nil {
+		return diagnosis, nil
+	}
+
+	diagnosis.HasBaseline = true
+	diagnosis.BaselineEnvironment = baseline.Environment().String()
+	diagnosis.BaselineCreatedAt = baseline.CreatedAt()
+	for _, kind := range baseline.ChangedFingerprints(current) {
+		diagnosis.Changed = append(diagnosis.Changed, string(kind))
+	}
+	if !baseline.CodeManifest().IsEmpty() {
+		diagnosis.Files = manifestChanges(current.CodeManifest().Diff(baseline.CodeManifest()))
+	}
+	// Un paso sin variables guarda un manifiesto vacío; solo falta el manifiesto
+	// en las entradas anteriores a que se guardara.
+	if !baseline.VarsManifest().IsEmpty() || baseline.Vars().String() == "" {
+		diagnosis.Variables = manifestChanges(current.VarsManifest().Diff(baseline.VarsManifest()))
+	}
+	return diagnosis, nil
+}
+
+func manifestChanges(diff staVos.ManifestDiff) *appDto.ManifestChanges {
+	return &appDto.ManifestChanges{
+		Added:    diff.Added(),
+		Removed:  diff.Removed(),
+		Modified: diff.Modified(),
+	}
+}
+
+// ClearState borra las entradas de estado de un paso en un entorno, o en todos si
+// envName está vacío, para que la siguiente ejecución no lo omita. Devuelve cuántas
+// entradas borró.
+func (o *ExecutionOrchestrator) ClearState(ctx context.Context, stepName, envName string) (int, error) {
+	workspace, err := o.stateWorkspace(ctx)
+	if err != nil {
+		return 0, err
+	}
+	stateTablePath, err := workspace.StateTablePath(stepName)
+	if err != nil {
+		return 0, err
+	}
+	unlock := o.locks.Lock(stateTablePath)
+	defer unlock()
+	return o.stateManager.ClearState(stateTablePath, envName)
+}
+
+// PruneState borra de todas las tablas de estado las entradas creadas antes de
+// cutoff. Devuelve cuántas entradas borró.
+func (o *ExecutionOrchestrator) PruneState(ctx context.Context, cutoff time.Time) (int, error) {
+	workspace, err := o.stateWorkspace(ctx)
+	if err != nil {
+		return 0, err
+	}
+	stateTablePaths, err := o.stateTablePaths(workspace, "")
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	for _, stateTablePath := range stateTablePaths {
+		unlock := o.locks.Lock(stateTablePath)
+		removed, err := o.stateManager.PruneState(stateTablePath, cutoff)
+		unlock()
+		if err != nil {
+			return pruned, err
+		}
+		pruned += removed
+	}
+	return pruned, nil
+}
+
+func (o *ExecutionOrchestrator) stateWorkspace(ctx context.Context) (*worAgg.Workspace, error) {
+	project, err := o.loadProject(ctx, o.projectPath)
+	if err != nil {
+		return nil, err
+	}
+	return o.loadWorkspace(project, o.rootVexPath, "")
+}
+
+// stateTablePaths devuelve, por paso, las tablas de estado del workspace. Si stepName
+// no está vacío, solo la de ese paso.
+func (o *ExecutionOrchestrator) stateTablePaths(workspace *worAgg.Workspace, stepName string) (map[string]string, error) {
+	filePaths, err := o.stateManager.ListStates(workspace.StateDirPath())
+	if err != nil {
+		return nil, err
+	}
+	stateTablePaths := make(map[string]string, len(filePaths))
+	for _, filePath := range filePaths {
+		step, isStateTable := workspace.StateStepName(filePath)
+		if !isStateTable || (stepName != "" && step != stepName) {
+			continue
+		}
+		stateTablePaths[step] = filePath
+	}
+	return stateTablePaths, nil
+}
@@ -0,0 +1,80 @@
+package application
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	defEnt "github.com/jairoprogramador/vex/internal/domain/definition/entities"
+	defVos "github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testManifestKey = []byte("workspace-key")
+
+func deployStep(t *testing.T, variables map[string]interface{}) *defEnt.StepDefinition {
+	name, err := defVos.NewStepNameDefinition("02-deploy")
+	require.NoError(t, err)
+	command, err := defVos.NewCommandDefinition("deploy", "make deploy")
+	require.NoError(t, err)
+	stepVars := make([]defVos.VariableDefinition, 0, len(variables))
+	for key, value := range variables {
+		variable, err := defVos.NewVariableDefinition(key, value)
+		require.NoError(t, err)
+		stepVars = append(stepVars, variable)
+	}
+	stepDef, err := defEnt.NewStepDefinition(name, []defVos.CommandDefinition{command}, stepVars)
+	require.NoError(t, err)
+	return stepDef
+}
+
+func TestStepVariables(t *testing.T) {
+	inputs := exeVos.NewVariableSetFromMap(map[string]string{"image": "app:1", "region": "us-east-1"})
+	inputs.AddAll(exeVos.NewVariableSetFromMap(map[string]string{"project_version": "1.2.0"}).
+		WithSource(exeVos.NewVariableSource(exeVos.RuntimeSource, "")))
+
+	t.Run("should combine inputs, step variables and overrides, without runtime variables", func(t *testing.T) {
+		stepDef := deployStep(t, map[string]interface{}{"region": "eu-west-1", "replicas": 2})
+		overrides := exeVos.NewVariableSetFromMap(map[string]string{"replicas": "3"})
+
+		values := stepVariables(stepDef, inputs, overrides)
+
+		assert.Equal(t, map[string]string{"image": "app:1", "region": "eu-west-1", "replicas": "3"}, values)
+	})
+
+	t.Run("should change when an upstream output changes", func(t *testing.T) {
+		stepDef := deployStep(t, nil)
+		changed := inputs.Clone()
+		changed.AddAll(exeVos.NewVariableSetFromMap(map[string]string{"image": "app:2"}))
+
+		before := variablesManifest(stepVariables(stepDef, inputs, exeVos.NewVariableSet()), testManifestKey)
+		after := variablesManifest(stepVariables(stepDef, changed, exeVos.NewVariableSet()), testManifestKey)
+
+		assert.Equal(t, []string{"image"}, after.Diff(before).Modified())
+		assert.NotEqual(t, variablesFingerprint(before.Hashes()), variablesFingerprint(after.Hashes()))
+	})
+
+	t.Run("should not keep the values in the manifest", func(t *testing.T) {
+		stepDef := deployStep(t, map[string]interface{}{"api_token": "s3cr3t"})
+
+		manifest := variablesManifest(stepVariables(stepDef, exeVos.NewVariableSet(), exeVos.NewVariableSet()), testManifestKey)
+
+		assert.NotContains(t, manifest.Hashes()["api_token"], "s3cr3t")
+		assert.Len(t, manifest.Hashes()["api_token"], 64)
+	})
+
+	t.Run("should key the hash of secret values with the workspace key", func(t *testing.T) {
+		values := map[string]string{"api_token": "s3cr3t", "region": "eu-west-1"}
+		plain := sha256.Sum256([]byte("s3cr3t"))
+
+		manifest := variablesManifest(values, testManifestKey)
+		other := variablesManifest(values, []byte("other-workspace-key"))
+
+		assert.NotEqual(t, hex.EncodeToString(plain[:]), manifest.Hashes()["api_token"])
+		assert.NotEqual(t, manifest.Hashes()["api_token"], other.Hashes()["api_token"])
+		assert.Equal(t, manifest.Hashes()["region"], other.Hashes()["region"])
+		assert.Empty(t, other.Diff(variablesManifest(values, []byte("other-workspace-key"))).Modified())
+	})
+}
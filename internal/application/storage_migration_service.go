@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	exePrt "github.com/jairoprogramador/vex/internal/domain/execution/ports"
+	staPrt "github.com/jairoprogramador/vex/internal/domain/state/ports"
+	worAgg "github.com/jairoprogramador/vex/internal/domain/workspace/aggregates"
+)
+
+// StorageMigrationService convierte al formato actual las tablas de estado y los
+// archivos de variables de todos los workspaces de la raíz de vex.
+type StorageMigrationService struct {
+	rootVexPath     string
+	stateRepository staPrt.StateRepository
+	varsRepository  exePrt.VarsRepository
+}
+
+// NewStorageMigrationService crea una nueva instancia de StorageMigrationService.
+func NewStorageMigrationService(
+	rootVexPath string,
+	stateRepository staPrt.StateRepository,
+	varsRepository exePrt.VarsRepository,
+) *StorageMigrationService {
+	return &StorageMigrationService{
+		rootVexPath:     rootVexPath,
+		stateRepository: stateRepository,
+		varsRepository:  varsRepository,
+	}
+}
+
+// Migrate recorre la raíz de vex y convierte cada archivo que aún use un formato
+// anterior. Un archivo que no se puede convertir no detiene la migración: se
+// informa en el resultado.
+func (s *StorageMigrationService) Migrate(ctx context.Context) (appDto.StorageMigration, error) {
+	result := appDto.StorageMigration{}
+	err := filepath.WalkDir(s.rootVexPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(s.rootVexPath, path)
+		if err != nil {
+			return err
+		}
+
+		var migrated bool
+		switch {
+		case worAgg.IsStateTablePath(relPath):
+			migrated, err = s.stateRepository.Migrate(path)
+		case worAgg.IsVarsFilePath(relPath):
+			migrated, err = s.varsRepository.Migrate(path)
+		default:
+			return nil
+		}
+
+		switch {
+		case err != nil:
+			result.Failed = append(result.Failed, appDto.StorageMigrationFailure{Path: path, Error: err.Error()})
+		case migrated:
+			result.Migrated = append(result.Migrated, path)
+		default:
+			result.Current++
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return result, fmt.Errorf("no se pudo recorrer '%s': %w", s.rootVexPath, err)
+	}
+	return result, nil
+}
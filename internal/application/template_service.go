@@ -0,0 +1,299 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	proAgg "github.com/jairoprogramador/vex/internal/domain/project/aggregates"
+	proPrt "github.com/jairoprogramador/vex/internal/domain/project/ports"
+	proVos "github.com/jairoprogramador/vex/internal/domain/project/vos"
+	worAgg "github.com/jairoprogramador/vex/internal/domain/workspace/aggregates"
+)
+
+// TemplateLockFileName es el archivo, junto a vexconfig.yaml, que fija el commit de la plantilla.
+const TemplateLockFileName = "vexconfig.lock"
+
+// TemplateService mantiene el clon local de la plantilla en el commit bloqueado del proyecto.
+type TemplateService struct {
+	projectPath    string
+	rootVexPath    string
+	projectSvc     *ProjectService
+	workspaceSvc   *WorkspaceService
+	gitCloner      proPrt.ClonerTemplate
+	lockRepository proPrt.TemplateLockRepository
+}
+
+// NewTemplateService crea una nueva instancia de TemplateService.
+func NewTemplateService(
+	projectPath string,
+	rootVexPath string,
+	projectSvc *ProjectService,
+	workspaceSvc *WorkspaceService,
+	gitCloner proPrt.ClonerTemplate,
+	lockRepository proPrt.TemplateLockRepository,
+) *TemplateService {
+	return &TemplateService{
+		projectPath:    projectPath,
+		rootVexPath:    rootVexPath,
+		projectSvc:     projectSvc,
+		workspaceSvc:   workspaceSvc,
+		gitCloner:      gitCloner,
+		lockRepository: lockRepository,
+	}
+}
+
+// Sync deja cada capa de la plantilla en el commit bloqueado en vexconfig.lock. Si una
+// capa no está bloqueada, o su referencia configurada cambió, resuelve la referencia
+// en el remoto y la bloquea. Las capas locales se usan tal cual.
+func (s *TemplateService) Sync(
+	ctx context.Context, project *proAgg.Project, workspace *worAgg.Workspace, projectPath string) error {
+
+	lockPath := filepath.Join(projectPath, TemplateLockFileName)
+	locks, err := s.lockRepository.Load(ctx, lockPath)
+	if err != nil {
+		return err
+	}
+
+	templates := project.Templates()
+	relocked := false
+	for i, layer := range workspace.TemplateLayers() {
+		repo := templates[i]
+		templateLocalPath := workspace.LayerPath(layer)
+		if layer.IsLocal() {
+			if err := checkLocalTemplate(templateLocalPath); err != nil {
+				return err
+			}
+			fmt.Printf("Usando la plantilla local %s\n", templateLocalPath)
+			continue
+		}
+
+		err := s.gitCloner.EnsureCloned(ctx, repo.URL(), repo.Ref(), workspace.LayerRepositoryPath(layer), templateLocalPath)
+		if err != nil {
+			return err
+		}
+
+		current, locked := findTemplateLock(locks, repo)
+		if locked {
+			if !s.gitCloner.HasCommit(ctx, templateLocalPath, current.Commit()) {
+				if err := s.gitCloner.FetchCommit(ctx, templateLocalPath, current.Commit()); err != nil {
+					return err
+				}
+			}
+			if err := s.gitCloner.Checkout(ctx, templateLocalPath, current.Commit()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Un bloqueo del mismo repositorio con una referencia que ya no usa ninguna capa
+		// es el de la referencia anterior: sirve para mostrar qué cambió.
+		previous, changedRef := staleTemplateLock(locks, repo, templates)
+		update, newLock, err := s.resolveLatest(ctx, repo, templateLocalPath, previous, changedRef)
+		if err != nil {
+			return err
+		}
+		locks = replaceTemplateLock(locks, newLock)
+		relocked = true
+		if changedRef {
+			fmt.Printf("La referencia de la plantilla '%s' cambió de '%s' a '%s'.\n", repo.URL(), previous.Ref(), repo.Ref())
+			printTemplateUpdate(update)
+		}
+	}
+
+	configured := configuredTemplateLocks(locks, templates)
+	if !relocked && len(configured) == len(locks) {
+		return nil
+	}
+	return s.lockRepository.Save(ctx, lockPath, configured)
+}
+
+// Update resuelve de nuevo la referencia configurada de cada capa remota de la
+// plantilla, actualiza el bloqueo y muestra los commits incorporados.
+func (s *TemplateService) Update(ctx context.Context) ([]*appDto.TemplateUpdate, error) {
+	project, err := s.projectSvc.Load(ctx, s.projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar el proyecto: %w", err)
+	}
+	workspace, err := s.workspaceSvc.ForProject(s.rootVexPath, project, "")
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar el workspace: %w", err)
+	}
+
+	lockPath := filepath.Join(s.projectPath, TemplateLockFileName)
+	locks, err := s.lockRepository.Load(ctx, lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := project.Templates()
+	updates := make([]*appDto.TemplateUpdate, 0, len(templates))
+	for i, layer := range workspace.TemplateLayers() {
+		repo := templates[i]
+		templateLocalPath := workspace.LayerPath(layer)
+		if layer.IsLocal() {
+			fmt.Printf("La plantilla '%s' es un directorio local; no hay nada que actualizar.\n", templateLocalPath)
+			continue
+		}
+
+		err := s.gitCloner.EnsureCloned(ctx, repo.URL(), repo.Ref(), workspace.LayerRepositoryPath(layer), templateLocalPath)
+		if err != nil {
+			return nil, err
+		}
+		previous, locked := findTemplateLock(locks, repo)
+		update, newLock, err := s.resolveLatest(ctx, repo, templateLocalPath, previous, locked)
+		if err != nil {
+			return nil, err
+		}
+		locks = replaceTemplateLock(locks, newLock)
+		if len(templates) > 1 {
+			fmt.Printf("%s@%s:\n", repo.URL(), repo.Ref())
+		}
+		printTemplateUpdate(update)
+		updates = append(updates, update)
+	}
+
+	if len(updates) == 0 {
+		return updates, nil
+	}
+	if err := s.lockRepository.Save(ctx, lockPath, configuredTemplateLocks(locks, templates)); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// resolveLatest trae los cambios del remoto, resuelve la referencia configurada y deja
+// el árbol de trabajo en ese commit. Si hay un bloqueo previo, el resumen incluye los
+// commits incorporados desde él. Devuelve el bloqueo nuevo sin guardarlo.
+func (s *TemplateService) resolveLatest(
+	ctx context.Context,
+	repo proVos.TemplateRepository,
+	templateLocalPath string,
+	previous proVos.TemplateLock,
+	locked bool,
+) (*appDto.TemplateUpdate, proVos.TemplateLock, error) {
+
+	if err := s.gitCloner.Fetch(ctx, templateLocalPath); err != nil {
+		return nil, proVos.TemplateLock{}, err
+	}
+	newCommit, err := s.gitCloner.ResolveRef(ctx, templateLocalPath, repo.Ref())
+	if err != nil {
+		return nil, proVos.TemplateLock{}, err
+	}
+
+	update := &appDto.TemplateUpdate{URL: repo.URL(), Ref: repo.Ref(), NewCommit: newCommit, Commits: []string{}}
+	if locked {
+		update.OldCommit = previous.Commit()
+		if update.Changed() && s.gitCloner.HasCommit(ctx, templateLocalPath, previous.Commit()) {
+			update.Commits, err = s.gitCloner.CommitRange(ctx, templateLocalPath, previous.Commit(), newCommit)
+			if err != nil {
+				return nil, proVos.TemplateLock{}, err
+			}
+		}
+	}
+
+	if err := s.gitCloner.Checkout(ctx, templateLocalPath, newCommit); err != nil {
+		return nil, proVos.TemplateLock{}, err
+	}
+
+	newLock, err := proVos.NewTemplateLock(repo.URL(), repo.Ref(), newCommit)
+	if err != nil {
+		return nil, proVos.TemplateLock{}, err
+	}
+	return update, newLock, nil
+}
+
+func checkLocalTemplate(templateLocalPath string) error {
+	info, err := os.Stat(templateLocalPath)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("la plantilla local '%s' no existe o no es un directorio", templateLocalPath)
+	}
+	return nil
+}
+
+// findTemplateLock busca el bloqueo de un repositorio y referencia. Dos capas pueden
+// usar el mismo repositorio con referencias distintas, cada una con su bloqueo.
+func findTemplateLock(locks []proVos.TemplateLock, repo proVos.TemplateRepository) (proVos.TemplateLock, bool) {
+	for _, lock := range locks {
+		if lock.Matches(repo) {
+			return lock, true
+		}
+	}
+	return proVos.TemplateLock{}, false
+}
+
+// staleTemplateLock busca un bloqueo del mismo repositorio cuya referencia ya no
+// configura ninguna capa del proyecto.
+func staleTemplateLock(
+	locks []proVos.TemplateLock,
+	repo proVos.TemplateRepository,
+	templates []proVos.TemplateRepository,
+) (proVos.TemplateLock, bool) {
+	for _, lock := range locks {
+		if lock.URL() == repo.URL() && !isConfiguredTemplateLock(lock, templates) {
+			return lock, true
+		}
+	}
+	return proVos.TemplateLock{}, false
+}
+
+// configuredTemplateLocks descarta los bloqueos que no corresponden a ninguna capa
+// configurada, como los de una referencia que se cambió.
+func configuredTemplateLocks(locks []proVos.TemplateLock, templates []proVos.TemplateRepository) []proVos.TemplateLock {
+	configured := make([]proVos.TemplateLock, 0, len(locks))
+	for _, lock := range locks {
+		if isConfiguredTemplateLock(lock, templates) {
+			configured = append(configured, lock)
+		}
+	}
+	return configured
+}
+
+func isConfiguredTemplateLock(lock proVos.TemplateLock, templates []proVos.TemplateRepository) bool {
+	for _, repo := range templates {
+		if lock.Matches(repo) {
+			return true
+		}
+	}
+	return false
+}
+
+func replaceTemplateLock(locks []proVos.TemplateLock, newLock proVos.TemplateLock) []proVos.TemplateLock {
+	updated := make([]proVos.TemplateLock, 0, len(locks)+1)
+	replaced := false
+	for _, lock := range locks {
+		if lock.URL() == newLock.URL() && lock.Ref() == newLock.Ref() {
+			updated = append(updated, newLock)
+			replaced = true
+			continue
+		}
+		updated = append(updated, lock)
+	}
+	if !replaced {
+		updated = append(updated, newLock)
+	}
+	return updated
+}
+
+func printTemplateUpdate(update *appDto.TemplateUpdate) {
+	if !update.Changed() {
+		fmt.Printf("La plantilla ya está en el último commit de '%s' (%s).\n", update.Ref, shortCommit(update.NewCommit))
+		return
+	}
+	fmt.Printf("Plantilla actualizada: %s → %s\n", shortCommit(update.OldCommit), shortCommit(update.NewCommit))
+	for _, commit := range update.Commits {
+		fmt.Printf("  %s\n", commit)
+	}
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	if commit == "" {
+		return "(ninguno)"
+	}
+	return commit
+}
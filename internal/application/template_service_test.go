@@ -0,0 +1,71 @@
+package application
+
+import (
+	"testing"
+
+	proVos "github.com/jairoprogramador/vex/internal/domain/project/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const templateURL = "https://example.com/templates.git"
+
+func newTestTemplate(t *testing.T, ref string) proVos.TemplateRepository {
+	t.Helper()
+	repo, err := proVos.NewTemplateRepository(templateURL, ref)
+	require.NoError(t, err)
+	return repo
+}
+
+func newTestTemplateLock(t *testing.T, ref, commit string) proVos.TemplateLock {
+	t.Helper()
+	lock, err := proVos.NewTemplateLock(templateURL, ref, commit)
+	require.NoError(t, err)
+	return lock
+}
+
+func TestTemplateLocks(t *testing.T) {
+	base := newTestTemplate(t, "main")
+	overlay := newTestTemplate(t, "prod")
+	templates := []proVos.TemplateRepository{base, overlay}
+
+	locks := []proVos.TemplateLock{
+		newTestTemplateLock(t, "main", "aaa"),
+		newTestTemplateLock(t, "prod", "bbb"),
+	}
+
+	t.Run("debería distinguir las capas del mismo repositorio por su referencia", func(t *testing.T) {
+		lock, found := findTemplateLock(locks, base)
+		require.True(t, found)
+		assert.Equal(t, "aaa", lock.Commit())
+
+		lock, found = findTemplateLock(locks, overlay)
+		require.True(t, found)
+		assert.Equal(t, "bbb", lock.Commit())
+	})
+
+	t.Run("debería reemplazar solo el bloqueo de la misma referencia", func(t *testing.T) {
+		updated := replaceTemplateLock(locks, newTestTemplateLock(t, "prod", "ccc"))
+		require.Len(t, updated, 2)
+		assert.Equal(t, "aaa", updated[0].Commit())
+		assert.Equal(t, "ccc", updated[1].Commit())
+	})
+
+	t.Run("debería reconocer el bloqueo de una referencia que se cambió", func(t *testing.T) {
+		release := newTestTemplate(t, "release")
+		changed := []proVos.TemplateRepository{base, release}
+
+		_, found := findTemplateLock(locks, release)
+		assert.False(t, found)
+		previous, stale := staleTemplateLock(locks, release, changed)
+		require.True(t, stale)
+		assert.Equal(t, "prod", previous.Ref())
+
+		_, stale = staleTemplateLock(locks, overlay, templates)
+		assert.False(t, stale)
+
+		configured := configuredTemplateLocks(locks, changed)
+		require.Len(t, configured, 1)
+		assert.Equal(t, "main", configured[0].Ref())
+	})
+}
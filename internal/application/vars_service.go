@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	appDto "github.com/jairoprogramador/vex/internal/application/dto"
+	exePrt "github.com/jairoprogramador/vex/internal/domain/execution/ports"
+	exeVos "github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	worAgg "github.com/jairoprogramador/vex/internal/domain/workspace/aggregates"
+)
+
+// VarsService consulta y corrige las salidas de los pasos guardadas en el workspace.
+type VarsService struct {
+	projectPath    string
+	rootVexPath    string
+	projectSvc     *ProjectService
+	workspaceSvc   *WorkspaceService
+	varsRepository exePrt.VarsRepository
+}
+
+// NewVarsService crea una nueva instancia de VarsService.
+func NewVarsService(
+	projectPath string,
+	rootVexPath string,
+	projectSvc *ProjectService,
+	workspaceSvc *WorkspaceService,
+	varsRepository exePrt.VarsRepository,
+) *VarsService {
+	return &VarsService{
+		projectPath:    projectPath,
+		rootVexPath:    rootVexPath,
+		projectSvc:     projectSvc,
+		workspaceSvc:   workspaceSvc,
+		varsRepository: varsRepository,
+	}
+}
+
+// List devuelve las variables guardadas de un entorno y las compartidas, por paso. Si
+// stepName no está vacío, solo las de ese paso. Los secretos se ocultan salvo que
+// reveal sea true.
+func (s *VarsService) List(
+	ctx context.Context, envName, stepName string, reveal bool) ([]appDto.StoredVariables, error) {
+
+	workspace, err := s.loadWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make([]appDto.StoredVariables, 0)
+	for _, scope := range []string{envName, exeVos.SharedScope} {
+		filePaths, err := s.varsRepository.List(workspace.VarsScopeDirPath(scope))
+		if err != nil {
+			return nil, err
+		}
+		for _, filePath := range filePaths {
+			step, isVarsFile := workspace.VarsStepName(filePath)
+			if !isVarsFile || (stepName != "" && step != stepName) {
+				continue
+			}
+			vars, err := s.varsRepository.Get(filePath)
+			if err != nil {
+				return nil, err
+			}
+			if len(vars) == 0 {
+				continue
+			}
+			stored = append(stored, appDto.StoredVariables{
+				Scope:     scope,
+				Step:      step,
+				Variables: visibleValues(vars, reveal),
+			})
+		}
+	}
+	sort.SliceStable(stored, func(i, j int) bool {
+		if stored[i].Scope != stored[j].Scope {
+			return stored[i].Scope != exeVos.SharedScope
+		}
+		return stored[i].Step < stored[j].Step
+	})
+	return stored, nil
+}
+
+// Get devuelve el valor guardado de una variable de un paso.
+func (s *VarsService) Get(
+	ctx context.Context, envName, stepName, name string, shared, reveal bool) (string, error) {
+
+	vars, _, err := s.load(ctx, envName, stepName, shared)
+	if err != nil {
+		return "", err
+	}
+	if _, exists := vars.Get(name); !exists {
+		return "", fmt.Errorf("el paso '%s' no tiene una variable '%s' guardada en '%s'",
+			stepName, name, scopeOf(envName, shared))
+	}
+	return visibleValues(vars, reveal)[name], nil
+}
+
+// Set guarda el valor de una variable de un paso, p. ej. para corregir una salida
+// que la sonda capturó mal. El paso no se vuelve a ejecutar por ello: el valor se usa
+// en las siguientes ejecuciones de los pasos posteriores. El archivo se lee y se
+// escribe bloqueado, para no perder lo que guarde una ejecución simultánea.
+func (s *VarsService) Set(ctx context.Context, envName, stepName, name, value string, shared bool) error {
+	filePath, err := s.filePath(ctx, envName, stepName, shared)
+	if err != nil {
+		return err
+	}
+	outputVar, err := exeVos.NewOutputVar(name, value, shared)
+	if err != nil {
+		return err
+	}
+	return s.varsRepository.Update(filePath, func(vars exeVos.VariableSet) (exeVos.VariableSet, error) {
+		vars.Add(outputVar)
+		return vars, nil
+	})
+}
+
+// Unset borra una variable guardada de un paso. Si era la última, borra el archivo.
+func (s *VarsService) Unset(ctx context.Context, envName, stepName, name string, shared bool) error {
+	filePath, err := s.filePath(ctx, envName, stepName, shared)
+	if err != nil {
+		return err
+	}
+	return s.varsRepository.Update(filePath, func(vars exeVos.VariableSet) (exeVos.VariableSet, error) {
+		if _, exists := vars.Get(name); !exists {
+			return nil, fmt.Errorf("el paso '%s' no tiene una variable '%s' guardada en '%s'",
+				stepName, name, scopeOf(envName, shared))
+		}
+		return vars.Filter(func(v exeVos.OutputVar) bool {
+			return v.Name() != name
+		}), nil
+	})
+}
+
+func (s *VarsService) load(
+	ctx context.Context, envName, stepName string, shared bool) (exeVos.VariableSet, string, error) {
+
+	filePath, err := s.filePath(ctx, envName, stepName, shared)
+	if err != nil {
+		return nil, "", err
+	}
+	vars, err := s.varsRepository.Get(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	return vars, filePath, nil
+}
+
+func (s *VarsService) filePath(ctx context.Context, envName, stepName string, shared bool) (string, error) {
+	if stepName == "" {
+		return "", errors.New("el nombre del paso no puede estar vacío")
+	}
+	workspace, err := s.loadWorkspace(ctx)
+	if err != nil {
+		return "", err
+	}
+	return workspace.VarsFilePath(scopeOf(envName, shared), stepName), nil
+}
+
+func (s *VarsService) loadWorkspace(ctx context.Context) (*worAgg.Workspace, error) {
+	project, err := s.projectSvc.Load(ctx, s.projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar el proyecto: %w", err)
+	}
+	workspace, err := s.workspaceSvc.ForProject(s.rootVexPath, project, "")
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar el workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+func scopeOf(envName string, shared bool) string {
+	if shared {
+		return exeVos.SharedScope
+	}
+	return envName
+}
+
+func visibleValues(vars exeVos.VariableSet, reveal bool) map[string]string {
+	if reveal {
+		return vars.ToStringMap()
+	}
+	return vars.Redacted()
+}
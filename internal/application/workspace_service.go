@@ -1,12 +1,23 @@
 package application
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	proAgg "github.com/jairoprogramador/vex/internal/domain/project/aggregates"
+	proVos "github.com/jairoprogramador/vex/internal/domain/project/vos"
 	"github.com/jairoprogramador/vex/internal/domain/workspace/aggregates"
 	"github.com/jairoprogramador/vex/internal/domain/workspace/vos"
 )
 
 // WorkspaceService es responsable de crear y gestionar el agregado Workspace.
-type WorkspaceService struct{}
+type WorkspaceService struct {
+	// migrationMu serializa la migración de los workspaces antiguos: las ejecuciones
+	// en varios ambientes cargan a la vez el workspace del mismo proyecto.
+	migrationMu sync.Mutex
+}
 
 // NewWorkspaceService crea una nueva instancia de WorkspaceService.
 func NewWorkspaceService() *WorkspaceService {
@@ -14,7 +25,10 @@ func NewWorkspaceService() *WorkspaceService {
 }
 
 // Load crea una instancia del agregado Workspace a partir de los datos proporcionados.
-func (s *WorkspaceService) NewWorkspace(rootVexPath, projectName, templateName string) (*aggregates.Workspace, error) {
+func (s *WorkspaceService) NewWorkspace(
+	rootVexPath, projectName, templateName string,
+	layers []vos.TemplateLayer,
+	opts ...aggregates.WorkspaceOption) (*aggregates.Workspace, error) {
 	wsRootPath, err := vos.NewRootPath(rootVexPath)
 	if err != nil {
 		return nil, err
@@ -30,5 +44,97 @@ func (s *WorkspaceService) NewWorkspace(rootVexPath, projectName, templateName s
 		return nil, err
 	}
 
-	return aggregates.NewWorkspace(wsRootPath, wsProjectName, wsTemplateName)
+	return aggregates.NewWorkspace(wsRootPath, wsProjectName, wsTemplateName, layers, opts...)
+}
+
+// ForProject crea el Workspace de un proyecto con una capa por cada plantilla de
+// vexconfig.yaml. templateDir, si no está vacío, reemplaza la capa superior por un
+// directorio local; las capas que vexconfig.yaml apunta a un directorio se leen de él.
+func (s *WorkspaceService) ForProject(
+	rootVexPath string, project *proAgg.Project, templateDir string) (*aggregates.Workspace, error) {
+
+	templates := project.Templates()
+	layers := make([]vos.TemplateLayer, 0, len(templates))
+	for _, repo := range templates {
+		layer, err := templateLayer(project, repo)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	opts := make([]aggregates.WorkspaceOption, 0, 1)
+	if templateDir != "" {
+		absTemplateDir, err := filepath.Abs(templateDir)
+		if err != nil {
+			return nil, fmt.Errorf("ruta de plantilla inválida '%s': %w", templateDir, err)
+		}
+		opts = append(opts, aggregates.WithLocalTemplate(absTemplateDir))
+	}
+
+	workspace, err := s.NewWorkspace(
+		rootVexPath, project.Data().Name(), proVos.TemplateStackKey(templates), layers, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) > 1 {
+		return workspace, nil
+	}
+
+	repo := project.TemplateRepo()
+	legacyPath := filepath.Join(workspace.ProjectDirPath(), repo.DirName())
+	s.migrationMu.Lock()
+	defer s.migrationMu.Unlock()
+	if err := migrateLegacyWorkspace(legacyPath, workspace.WorkspacePath()); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+func templateLayer(project *proAgg.Project, repo proVos.TemplateRepository) (vos.TemplateLayer, error) {
+	name, err := vos.NewTemplateName(repo.CacheKey())
+	if err != nil {
+		return vos.TemplateLayer{}, err
+	}
+	if localPath, isLocal := project.LocalTemplatePath(repo); isLocal {
+		return vos.NewLocalTemplateLayer(name, localPath), nil
+	}
+	repository, err := vos.NewTemplateName(repo.RepositoryKey())
+	if err != nil {
+		return vos.TemplateLayer{}, err
+	}
+	return vos.NewTemplateLayer(name, repository), nil
+}
+
+// migrateLegacyWorkspace mueve el workspace que las versiones anteriores guardaban
+// bajo el nombre del directorio de la plantilla, sin URL ni referencia, a su nueva
+// ubicación. Solo se migra si la nueva ubicación aún no existe. Si otro proceso lo
+// movió entre la comprobación y el renombrado, la migración ya está hecha.
+func migrateLegacyWorkspace(legacyPath, workspacePath string) error {
+	info, err := os.Stat(legacyPath)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+	if _, err := os.Stat(workspacePath); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("no se pudo verificar el workspace '%s': %w", workspacePath, err)
+	}
+
+	if err := os.Rename(legacyPath, workspacePath); err != nil {
+		if isMigrated(legacyPath, workspacePath) {
+			return nil
+		}
+		return fmt.Errorf("no se pudo migrar el workspace '%s' a '%s': %w", legacyPath, workspacePath, err)
+	}
+	fmt.Printf("Workspace migrado de '%s' a '%s'\n", legacyPath, workspacePath)
+	return nil
+}
+
+func isMigrated(legacyPath, workspacePath string) bool {
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		return false
+	}
+	info, err := os.Stat(workspacePath)
+	return err == nil && info.IsDir()
 }
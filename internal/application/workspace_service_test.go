@@ -0,0 +1,51 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceService_ForProject_MigratesTheLegacyWorkspaceOnce(t *testing.T) {
+	project, err := NewProjectService(&fakeProjectConfigRepository{}).Load(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	rootVexPath := t.TempDir()
+	legacyPath := filepath.Join(rootVexPath, project.Data().Name(), project.TemplateRepo().DirName())
+	require.NoError(t, os.MkdirAll(filepath.Join(legacyPath, "state"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(legacyPath, "state", "deploy.tb"), []byte("{}"), 0644))
+
+	service := NewWorkspaceService()
+	environments := 8
+	errs := make([]error, environments)
+	var wg sync.WaitGroup
+	for i := 0; i < environments; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = service.ForProject(rootVexPath, project, "")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	workspace, err := service.ForProject(rootVexPath, project, "")
+	require.NoError(t, err)
+	assert.NoDirExists(t, legacyPath)
+	assert.FileExists(t, filepath.Join(workspace.WorkspacePath(), "state", "deploy.tb"))
+}
+
+func TestMigrateLegacyWorkspace_AcceptsAWorkspaceAnotherProcessMoved(t *testing.T) {
+	root := t.TempDir()
+	workspacePath := filepath.Join(root, "workspace")
+	require.NoError(t, os.Mkdir(workspacePath, 0755))
+
+	assert.True(t, isMigrated(filepath.Join(root, "legacy"), workspacePath))
+	assert.False(t, isMigrated(filepath.Join(root, "legacy"), filepath.Join(root, "missing")))
+}
@@ -10,19 +10,34 @@ import (
 type ExecutionPlanDefinition struct {
 	environment vos.EnvironmentDefinition
 	steps       []*entities.StepDefinition
+	settings    vos.TemplateSettingsDefinition
+}
+
+type ExecutionPlanOption func(*ExecutionPlanDefinition)
+
+// WithTemplateSettings asocia al plan las opciones de template.yaml.
+func WithTemplateSettings(settings vos.TemplateSettingsDefinition) ExecutionPlanOption {
+	return func(p *ExecutionPlanDefinition) {
+		p.settings = settings
+	}
 }
 
 func NewExecutionPlanDefinition(
 	env vos.EnvironmentDefinition,
-	steps []*entities.StepDefinition) (*ExecutionPlanDefinition, error) {
+	steps []*entities.StepDefinition,
+	opts ...ExecutionPlanOption) (*ExecutionPlanDefinition, error) {
 
 	if len(steps) == 0 {
 		return nil, errors.New("el plan de ejecución debe contener al menos un paso")
 	}
-	return &ExecutionPlanDefinition{
+	plan := &ExecutionPlanDefinition{
 		environment: env,
 		steps:       steps,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(plan)
+	}
+	return plan, nil
 }
 
 func (p *ExecutionPlanDefinition) Environment() vos.EnvironmentDefinition {
@@ -32,3 +47,35 @@ func (p *ExecutionPlanDefinition) Environment() vos.EnvironmentDefinition {
 func (p *ExecutionPlanDefinition) Steps() []*entities.StepDefinition {
 	return p.steps
 }
+
+func (p *ExecutionPlanDefinition) Settings() vos.TemplateSettingsDefinition {
+	return p.settings
+}
+
+// OutputCollisions devuelve las salidas de cada paso que tienen el mismo nombre que
+// la salida de un paso anterior. Con el nombre sin prefijo, los pasos siguientes solo
+// ven la última; la anterior sigue disponible como ${step.<paso>.<salida>}.
+func (p *ExecutionPlanDefinition) OutputCollisions() []vos.OutputCollision {
+	var collisions []vos.OutputCollision
+	producers := make(map[string]string)
+	for _, step := range p.steps {
+		stepName := step.NameDef().Name()
+		stepOutputs := make(map[string]struct{})
+		for _, command := range step.CommandsDef() {
+			for _, output := range command.Outputs() {
+				if output.Name() == "" {
+					continue
+				}
+				if _, seen := stepOutputs[output.Name()]; seen {
+					continue
+				}
+				stepOutputs[output.Name()] = struct{}{}
+				if producer, exists := producers[output.Name()]; exists && producer != stepName {
+					collisions = append(collisions, vos.NewOutputCollision(output.Name(), stepName, producer))
+				}
+				producers[output.Name()] = stepName
+			}
+		}
+	}
+	return collisions
+}
@@ -12,12 +12,19 @@ type StepDefinition struct {
 	name      vos.StepNameDefinition
 	commands  []vos.CommandDefinition
 	variables []vos.VariableDefinition
+	hooks     vos.StepHooksDefinition
+	sources   []vos.StepSourceDefinition
+	// fingerprint limita los archivos del proyecto que cuentan para el paso; vacío, cuentan todos.
+	fingerprint vos.FingerprintScopeDefinition
 }
 
+type StepDefinitionOption func(*StepDefinition)
+
 func NewStepDefinition(
 	name vos.StepNameDefinition,
 	commands []vos.CommandDefinition,
-	variables []vos.VariableDefinition) (*StepDefinition, error) {
+	variables []vos.VariableDefinition,
+	opts ...StepDefinitionOption) (*StepDefinition, error) {
 
 	if len(commands) == 0 {
 		return nil, errors.New("un paso debe tener al menos un comando")
@@ -45,11 +52,38 @@ func NewStepDefinition(
 		variablesNames[name] = true
 	}
 
-	return &StepDefinition{
+	step := &StepDefinition{
 		name:      name,
 		commands:  commands,
 		variables: variables,
-	}, nil
+	}
+
+	for _, opt := range opts {
+		opt(step)
+	}
+
+	return step, nil
+}
+
+func WithHooks(hooks vos.StepHooksDefinition) StepDefinitionOption {
+	return func(s *StepDefinition) {
+		s.hooks = hooks
+	}
+}
+
+// WithSources registra los archivos que cada capa de la plantilla aporta al paso,
+// en orden de aplicación.
+func WithSources(sources []vos.StepSourceDefinition) StepDefinitionOption {
+	return func(s *StepDefinition) {
+		s.sources = sources
+	}
+}
+
+// WithFingerprintScope limita los archivos del proyecto que cuentan para el fingerprint del paso.
+func WithFingerprintScope(scope vos.FingerprintScopeDefinition) StepDefinitionOption {
+	return func(s *StepDefinition) {
+		s.fingerprint = scope
+	}
 }
 
 func (s *StepDefinition) NameDef() vos.StepNameDefinition {
@@ -63,3 +97,15 @@ func (s *StepDefinition) CommandsDef() []vos.CommandDefinition {
 func (s *StepDefinition) VariablesDef() []vos.VariableDefinition {
 	return s.variables
 }
+
+func (s *StepDefinition) HooksDef() vos.StepHooksDefinition {
+	return s.hooks
+}
+
+func (s *StepDefinition) SourcesDef() []vos.StepSourceDefinition {
+	return s.sources
+}
+
+func (s *StepDefinition) FingerprintScopeDef() vos.FingerprintScopeDefinition {
+	return s.fingerprint
+}
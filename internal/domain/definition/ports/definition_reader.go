@@ -9,6 +9,11 @@ import (
 type DefinitionReader interface {
 	ReadEnvironments(ctx context.Context, sourcePath string) ([]vos.EnvironmentDefinition, error)
 	ReadStepNames(ctx context.Context, stepsDir string) ([]vos.StepNameDefinition, error)
-	ReadCommands(ctx context.Context, commandsFilePath string) ([]vos.CommandDefinition, error)
+	// ReadStepFile lee los comandos y hooks de un archivo commands.yaml. Si el archivo
+	// no existe, devuelve un paso vacío.
+	ReadStepFile(ctx context.Context, commandsFilePath string) (vos.StepFileDefinition, error)
 	ReadVariables(ctx context.Context, variablesFilePath string) ([]vos.VariableDefinition, error)
+	// ReadTemplateSettings lee las opciones de un archivo template.yaml. Si el archivo
+	// no existe, devuelve las opciones por defecto.
+	ReadTemplateSettings(ctx context.Context, settingsFilePath string) (vos.TemplateSettingsDefinition, error)
 }
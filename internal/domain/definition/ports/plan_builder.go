@@ -4,9 +4,15 @@ import (
 	"context"
 
 	"github.com/jairoprogramador/vex/internal/domain/definition/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/definition/vos"
 )
 
 // PlanBuilder es responsable de cargar y ensamblar una definición de plan de ejecución completa.
 type PlanBuilder interface {
-	Build(ctx context.Context, templatePath, stepName, envName string) (*aggregates.ExecutionPlanDefinition, error)
+	// Build ensambla el plan de las capas de la plantilla. Las overrides se aplican, en
+	// orden, sobre las variables de la plantilla.
+	Build(ctx context.Context, layers []vos.LayerDefinition, stepName, envName string,
+		overrides ...vos.VariableOverridesDefinition) (*aggregates.ExecutionPlanDefinition, error)
+	// Environments devuelve los entornos que resultan de combinar las capas de la plantilla.
+	Environments(ctx context.Context, layers []vos.LayerDefinition) ([]vos.EnvironmentDefinition, error)
 }
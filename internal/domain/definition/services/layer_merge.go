@@ -0,0 +1,139 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/jairoprogramador/vex/internal/domain/definition/vos"
+)
+
+// mergeStepFile combina los comandos y hooks de una capa con los de las capas anteriores.
+func mergeStepFile(base, overlay vos.StepFileDefinition, layer string) (vos.StepFileDefinition, error) {
+	commands, err := mergeCommands(base.Commands(), overlay.Commands(), layer)
+	if err != nil {
+		return vos.StepFileDefinition{}, err
+	}
+	onFailure, err := mergeCommands(base.Hooks().OnFailure(), overlay.Hooks().OnFailure(), layer)
+	if err != nil {
+		return vos.StepFileDefinition{}, fmt.Errorf("hook on_failure: %w", err)
+	}
+	finally, err := mergeCommands(base.Hooks().Finally(), overlay.Hooks().Finally(), layer)
+	if err != nil {
+		return vos.StepFileDefinition{}, fmt.Errorf("hook finally: %w", err)
+	}
+	rollback, err := mergeCommands(base.Hooks().Rollback(), overlay.Hooks().Rollback(), layer)
+	if err != nil {
+		return vos.StepFileDefinition{}, fmt.Errorf("rollback: %w", err)
+	}
+
+	hooks := vos.NewStepHooksDefinition(
+		vos.WithOnFailure(onFailure),
+		vos.WithFinally(finally),
+		vos.WithRollback(rollback),
+	)
+	merged := vos.NewStepFileDefinition(commands, hooks, false)
+	// El bloque fingerprint de una capa reemplaza al de las anteriores.
+	if scope, declared := overlay.Fingerprint(); declared {
+		return merged.WithFingerprint(scope), nil
+	}
+	if scope, declared := base.Fingerprint(); declared {
+		return merged.WithFingerprint(scope), nil
+	}
+	return merged, nil
+}
+
+// mergeCommands aplica los comandos de un overlay por nombre: un comando con el
+// nombre de uno anterior lo reemplaza en su posición, uno nuevo se añade al final
+// y una marca de eliminación quita el comando anterior.
+func mergeCommands(base, overlay []vos.CommandDefinition, layer string) ([]vos.CommandDefinition, error) {
+	merged := make([]vos.CommandDefinition, len(base))
+	copy(merged, base)
+
+	for _, command := range overlay {
+		index := indexOfCommand(merged, command.Name())
+		if command.IsRemoved() {
+			if index == -1 {
+				return nil, fmt.Errorf("no existe el comando '%s' que se quiere eliminar", command.Name())
+			}
+			merged = append(merged[:index], merged[index+1:]...)
+			continue
+		}
+		if index == -1 {
+			merged = append(merged, command.FromLayer(layer))
+			continue
+		}
+		merged[index] = command.FromLayer(layer)
+	}
+	return merged, nil
+}
+
+func indexOfCommand(commands []vos.CommandDefinition, name string) int {
+	for i, command := range commands {
+		if command.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeVariables combina las variables por nombre. Si ambos valores son mapas se
+// combinan en profundidad; en cualquier otro caso prevalece el valor del overlay.
+func mergeVariables(base, overlay []vos.VariableDefinition, layer string) []vos.VariableDefinition {
+	merged := make([]vos.VariableDefinition, len(base))
+	copy(merged, base)
+
+	for _, variable := range overlay {
+		index := -1
+		for i, existing := range merged {
+			if existing.Name() == variable.Name() {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			merged = append(merged, variable.FromLayer(layer))
+			continue
+		}
+		value := deepMerge(merged[index].Value(), variable.Value())
+		merged[index] = variable.WithValue(value).FromLayer(layer).Overriding(merged[index])
+	}
+	return merged
+}
+
+func deepMerge(base, overlay interface{}) interface{} {
+	baseMap, baseIsMap := base.(map[string]interface{})
+	overlayMap, overlayIsMap := overlay.(map[string]interface{})
+	if !baseIsMap || !overlayIsMap {
+		return overlay
+	}
+
+	merged := make(map[string]interface{}, len(baseMap)+len(overlayMap))
+	for key, value := range baseMap {
+		merged[key] = value
+	}
+	for key, value := range overlayMap {
+		merged[key] = deepMerge(merged[key], value)
+	}
+	return merged
+}
+
+// mergeEnvironments combina los entornos por su valor: un overlay cambia solo los
+// campos que declara de un entorno existente o añade entornos nuevos al final.
+func mergeEnvironments(base, overlay []vos.EnvironmentDefinition, layer string) []vos.EnvironmentDefinition {
+	merged := make([]vos.EnvironmentDefinition, len(base))
+	copy(merged, base)
+
+	for _, env := range overlay {
+		replaced := false
+		for i, existing := range merged {
+			if existing.Equals(env) {
+				merged[i] = existing.MergedWith(env.FromLayer(layer))
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, env.FromLayer(layer))
+		}
+	}
+	return merged
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 
@@ -22,69 +21,177 @@ func NewPlanBuilder(reader ports.DefinitionReader) *PlanBuilder {
 	return &PlanBuilder{reader: reader}
 }
 
+// layeredStep es un paso junto con el directorio que lo define en cada capa.
+// El orden del paso es el de la última capa que lo declara.
+type layeredStep struct {
+	name vos.StepNameDefinition
+	dirs map[string]string
+}
+
+// Build ensambla el plan combinando las capas de la plantilla en orden: cada overlay
+// añade pasos o modifica los de las capas anteriores, y sus entornos y variables se
+// combinan con los anteriores. Si finalStepName está vacío, el plan incluye todos los pasos.
+//
+// Las variables de cada paso se combinan de menor a mayor prioridad:
+//  1. los valores por defecto de la plantilla, variables/<paso>.yaml;
+//  2. los archivos del entorno, variables/<entorno>/<paso>.yaml, empezando por los
+//     entornos de los que hereda;
+//  3. las overrides, en el orden recibido, p. ej. vexconfig.yaml y vexconfig.local.yaml.
 func (b *PlanBuilder) Build(
 	ctx context.Context,
-	templatePath, finalStepName, envName string) (*aggregates.ExecutionPlanDefinition, error) {
-	// 1. Validar y obtener el entorno
-	environment, err := b.resolveEnvironment(ctx, templatePath, envName)
+	layers []vos.LayerDefinition, finalStepName, envName string,
+	overrides ...vos.VariableOverridesDefinition) (*aggregates.ExecutionPlanDefinition, error) {
+	if len(layers) == 0 {
+		return nil, errors.New("la plantilla no tiene capas")
+	}
+
+	// 1. Validar y obtener el entorno junto con los entornos de los que hereda
+	lineage, err := b.resolveEnvironment(ctx, layers, envName)
 	if err != nil {
 		return nil, err
 	}
+	environment := lineage[len(lineage)-1]
 	// 2. Obtener y filtrar los pasos
-	stepsToExecute, err := b.resolveSteps(ctx, templatePath, finalStepName)
+	stepsToExecute, err := b.resolveSteps(ctx, layers, finalStepName)
 	if err != nil {
 		return nil, err
 	}
 
 	// 3. Ensamblar cada paso con sus comandos y variables
 	assembledSteps := make([]*entities.StepDefinition, 0, len(stepsToExecute))
-	for _, stepName := range stepsToExecute {
-		step, err := b.assembleStep(ctx, templatePath, stepName, environment)
+	for _, step := range stepsToExecute {
+		stepDef, err := b.assembleStep(ctx, layers, step, lineage, overrides)
 		if err != nil {
-			return nil, fmt.Errorf("error al ensamblar el paso '%s': %w", stepName.Name(), err)
+			return nil, fmt.Errorf("error al ensamblar el paso '%s': %w", step.name.Name(), err)
 		}
-		assembledSteps = append(assembledSteps, step)
+		assembledSteps = append(assembledSteps, stepDef)
+	}
+
+	settings, err := b.templateSettings(ctx, layers)
+	if err != nil {
+		return nil, err
 	}
 
 	// 4. Crear y devolver el agregado raíz
-	return aggregates.NewExecutionPlanDefinition(environment, assembledSteps)
+	return aggregates.NewExecutionPlanDefinition(environment, assembledSteps,
+		aggregates.WithTemplateSettings(settings))
 }
 
-func (b *PlanBuilder) resolveEnvironment(ctx context.Context, templatePath, envName string) (vos.EnvironmentDefinition, error) {
-	environments, err := b.reader.ReadEnvironments(ctx, filepath.Join(templatePath, "environments.yaml"))
-	if err != nil {
-		return vos.EnvironmentDefinition{}, fmt.Errorf("no se pudieron leer los entornos: %w", err)
+// templateSettings combina las opciones de template.yaml de todas las capas.
+func (b *PlanBuilder) templateSettings(
+	ctx context.Context, layers []vos.LayerDefinition) (vos.TemplateSettingsDefinition, error) {
+
+	settings := vos.NewTemplateSettingsDefinition()
+	for _, layer := range layers {
+		layerSettings, err := b.reader.ReadTemplateSettings(ctx, filepath.Join(layer.Path(), "template.yaml"))
+		if err != nil {
+			return vos.TemplateSettingsDefinition{}, fmt.Errorf("no se pudieron leer las opciones de la capa '%s': %w", layer.Name(), err)
+		}
+		settings = settings.Merge(layerSettings)
+	}
+	return settings, nil
+}
+
+// Environments combina los entornos de todas las capas de la plantilla.
+func (b *PlanBuilder) Environments(
+	ctx context.Context, layers []vos.LayerDefinition) ([]vos.EnvironmentDefinition, error) {
+
+	environments := make([]vos.EnvironmentDefinition, 0)
+	for _, layer := range layers {
+		layerEnvironments, err := b.reader.ReadEnvironments(ctx, filepath.Join(layer.Path(), "environments.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("no se pudieron leer los entornos de la capa '%s': %w", layer.Name(), err)
+		}
+		environments = mergeEnvironments(environments, layerEnvironments, layer.Name())
 	}
 	if len(environments) == 0 {
-		return vos.EnvironmentDefinition{}, errors.New("no hay entornos definidos en environments.yaml")
+		return nil, errors.New("no hay entornos definidos en environments.yaml")
+	}
+	return environments, nil
+}
+
+// resolveEnvironment devuelve el entorno del plan precedido por los entornos de los que hereda.
+func (b *PlanBuilder) resolveEnvironment(
+	ctx context.Context, layers []vos.LayerDefinition, envName string) ([]vos.EnvironmentDefinition, error) {
+
+	environments, err := b.Environments(ctx, layers)
+	if err != nil {
+		return nil, err
 	}
 
 	if envName == "" {
-		return environments[0], nil
+		return environmentLineage(environments, environments[0])
 	}
 
 	for _, env := range environments {
 		if env.String() == envName {
-			return env, nil
+			return environmentLineage(environments, env)
 		}
 	}
 
-	return vos.EnvironmentDefinition{}, fmt.Errorf("el entorno '%s' no es válido", envName)
+	return nil, fmt.Errorf("el entorno '%s' no es válido", envName)
 }
 
-func (b *PlanBuilder) resolveSteps(ctx context.Context, templatePath, finalStepName string) ([]vos.StepNameDefinition, error) {
-	allStepNames, err := b.reader.ReadStepNames(ctx, filepath.Join(templatePath, "steps"))
-	if err != nil {
-		return nil, fmt.Errorf("no se pudieron leer los pasos: %w", err)
+// environmentLineage devuelve el entorno precedido por los entornos de los que hereda,
+// del más general al más específico.
+func environmentLineage(
+	environments []vos.EnvironmentDefinition, env vos.EnvironmentDefinition) ([]vos.EnvironmentDefinition, error) {
+
+	byValue := make(map[string]vos.EnvironmentDefinition, len(environments))
+	for _, candidate := range environments {
+		byValue[candidate.String()] = candidate
+	}
+
+	lineage := []vos.EnvironmentDefinition{env}
+	visited := map[string]struct{}{env.String(): {}}
+	for current := env; current.Extends() != ""; {
+		parent, exists := byValue[current.Extends()]
+		if !exists {
+			return nil, fmt.Errorf("el entorno '%s' extiende '%s', que no está definido", current.String(), current.Extends())
+		}
+		if _, seen := visited[parent.String()]; seen {
+			return nil, fmt.Errorf("la herencia del entorno '%s' forma un ciclo en '%s'", env.String(), parent.String())
+		}
+		visited[parent.String()] = struct{}{}
+		lineage = append([]vos.EnvironmentDefinition{parent}, lineage...)
+		current = parent
+	}
+	return lineage, nil
+}
+
+func (b *PlanBuilder) resolveSteps(
+	ctx context.Context, layers []vos.LayerDefinition, finalStepName string) ([]*layeredStep, error) {
+
+	allSteps := make([]*layeredStep, 0)
+	stepsByName := make(map[string]*layeredStep)
+	for _, layer := range layers {
+		stepNames, err := b.reader.ReadStepNames(ctx, filepath.Join(layer.Path(), "steps"))
+		if err != nil {
+			return nil, fmt.Errorf("no se pudieron leer los pasos de la capa '%s': %w", layer.Name(), err)
+		}
+		for _, stepName := range stepNames {
+			step, exists := stepsByName[stepName.Name()]
+			if !exists {
+				step = &layeredStep{dirs: make(map[string]string)}
+				stepsByName[stepName.Name()] = step
+				allSteps = append(allSteps, step)
+			}
+			step.name = stepName
+			step.dirs[layer.Name()] = filepath.Join(layer.Path(), "steps", stepName.FullName())
+		}
 	}
 	// Asegurarse de que los pasos están ordenados
-	sort.Slice(allStepNames, func(i, j int) bool {
-		return allStepNames[i].Order() < allStepNames[j].Order()
+	sort.SliceStable(allSteps, func(i, j int) bool {
+		return allSteps[i].name.Order() < allSteps[j].name.Order()
 	})
 
+	if finalStepName == "" {
+		return allSteps, nil
+	}
+
 	finalStepIndex := -1
-	for i, s := range allStepNames {
-		if s.Name() == finalStepName {
+	for i, s := range allSteps {
+		if s.name.Name() == finalStepName {
 			finalStepIndex = i
 			break
 		}
@@ -94,28 +201,87 @@ func (b *PlanBuilder) resolveSteps(ctx context.Context, templatePath, finalStepN
 		return nil, fmt.Errorf("el paso final '%s' no se encontró", finalStepName)
 	}
 
-	return allStepNames[:finalStepIndex+1], nil
+	return allSteps[:finalStepIndex+1], nil
 }
 
+// assembleStep combina el paso de cada capa. Las variables se leen de los valores por
+// defecto y de cada entorno de la herencia, del más general al más específico, y
+// dentro de cada uno de la base a los overlays. Las overrides se aplican al final.
 func (b *PlanBuilder) assembleStep(ctx context.Context,
-	templatePath string, stepName vos.StepNameDefinition,
-	env vos.EnvironmentDefinition) (*entities.StepDefinition, error) {
+	layers []vos.LayerDefinition, step *layeredStep,
+	lineage []vos.EnvironmentDefinition,
+	overrides []vos.VariableOverridesDefinition) (*entities.StepDefinition, error) {
 
-	commandsPath := filepath.Join(templatePath, "steps", stepName.FullName(), "commands.yaml")
-	variablesPath := filepath.Join(templatePath, "variables", env.String(), stepName.Name()+".yaml")
+	merged := vos.NewStepFileDefinition(nil, vos.NewStepHooksDefinition(), false)
+	stepDirs := make(map[string]string, len(layers))
+	for _, layer := range layers {
+		stepDir, declared := step.dirs[layer.Name()]
+		if !declared {
+			continue
+		}
+		stepFile, err := b.reader.ReadStepFile(ctx, filepath.Join(stepDir, "commands.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("error al leer los comandos de la capa '%s': %w", layer.Name(), err)
+		}
+		if stepFile.Replace() {
+			merged = vos.NewStepFileDefinition(nil, vos.NewStepHooksDefinition(), false)
+			// Los archivos del paso de las capas anteriores dejan de aplicarse.
+			stepDirs = make(map[string]string, len(layers))
+		}
+		merged, err = mergeStepFile(merged, stepFile, layer.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error al combinar la capa '%s': %w", layer.Name(), err)
+		}
+		stepDirs[layer.Name()] = stepDir
+	}
 
-	commands, err := b.reader.ReadCommands(ctx, commandsPath)
-	if err != nil {
-		return nil, fmt.Errorf("error al leer los comandos: %w", err)
+	variables := make([]vos.VariableDefinition, 0)
+	variablesFiles := make(map[string][]string, len(layers))
+	// El directorio vacío corresponde a los valores por defecto, variables/<paso>.yaml.
+	variablesDirs := []string{""}
+	for _, env := range lineage {
+		variablesDirs = append(variablesDirs, env.String())
+	}
+	for _, variablesDir := range variablesDirs {
+		for _, layer := range layers {
+			variablesFile := filepath.Join("variables", variablesDir, step.name.Name()+".yaml")
+			variablesPath := filepath.Join(layer.Path(), variablesFile)
+			layerVariables, err := b.reader.ReadVariables(ctx, variablesPath)
+			if err != nil {
+				return nil, fmt.Errorf("error al leer las variables de la capa '%s': %w", layer.Name(), err)
+			}
+			for i, variable := range layerVariables {
+				layerVariables[i] = variable.FromFile(variablesFile)
+			}
+			variables = mergeVariables(variables, layerVariables, layer.Name())
+			variablesFiles[layer.Name()] = append(variablesFiles[layer.Name()], variablesPath)
+		}
 	}
 
-	variables, err := b.reader.ReadVariables(ctx, variablesPath)
-	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("error al leer las variables: %w", err)
+	for _, override := range overrides {
+		for _, envScope := range variablesDirs {
+			if envScope == "" {
+				envScope = vos.AnyScope
+			}
+			for _, stepScope := range []string{vos.AnyScope, step.name.Name()} {
+				overrideVariables, err := override.Variables(envScope, stepScope)
+				if err != nil {
+					return nil, fmt.Errorf("variables inválidas en '%s': %w", override.Source(), err)
+				}
+				variables = mergeVariables(variables, overrideVariables, override.Source())
+			}
 		}
-		variables = []vos.VariableDefinition{}
 	}
 
-	return entities.NewStepDefinition(stepName, commands, variables)
+	sources := make([]vos.StepSourceDefinition, 0, len(layers))
+	for _, layer := range layers {
+		sources = append(sources,
+			vos.NewStepSourceDefinition(layer.Name(), stepDirs[layer.Name()], variablesFiles[layer.Name()]))
+	}
+
+	opts := []entities.StepDefinitionOption{entities.WithHooks(merged.Hooks()), entities.WithSources(sources)}
+	if scope, declared := merged.Fingerprint(); declared {
+		opts = append(opts, entities.WithFingerprintScope(scope))
+	}
+	return entities.NewStepDefinition(step.name, merged.Commands(), variables, opts...)
 }
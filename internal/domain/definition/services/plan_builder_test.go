@@ -0,0 +1,473 @@
+package services_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jairoprogramador/vex/internal/domain/definition/services"
+	"github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDefinitionReader sirve las definiciones desde memoria, indexadas por ruta.
+type fakeDefinitionReader struct {
+	environments map[string][]vos.EnvironmentDefinition
+	stepNames    map[string][]vos.StepNameDefinition
+	stepFiles    map[string]vos.StepFileDefinition
+	variables    map[string][]vos.VariableDefinition
+	settings     map[string]vos.TemplateSettingsDefinition
+}
+
+func newFakeDefinitionReader() *fakeDefinitionReader {
+	return &fakeDefinitionReader{
+		environments: make(map[string][]vos.EnvironmentDefinition),
+		stepNames:    make(map[string][]vos.StepNameDefinition),
+		stepFiles:    make(map[string]vos.StepFileDefinition),
+		variables:    make(map[string][]vos.VariableDefinition),
+		settings:     make(map[string]vos.TemplateSettingsDefinition),
+	}
+}
+
+func (r *fakeDefinitionReader) ReadEnvironments(_ context.Context, path string) ([]vos.EnvironmentDefinition, error) {
+	return r.environments[path], nil
+}
+
+func (r *fakeDefinitionReader) ReadStepNames(_ context.Context, path string) ([]vos.StepNameDefinition, error) {
+	return r.stepNames[path], nil
+}
+
+func (r *fakeDefinitionReader) ReadStepFile(_ context.Context, path string) (vos.StepFileDefinition, error) {
+	if stepFile, exists := r.stepFiles[path]; exists {
+		return stepFile, nil
+	}
+	return vos.NewStepFileDefinition(nil, vos.NewStepHooksDefinition(), false), nil
+}
+
+func (r *fakeDefinitionReader) ReadVariables(_ context.Context, path string) ([]vos.VariableDefinition, error) {
+	return r.variables[path], nil
+}
+
+func (r *fakeDefinitionReader) ReadTemplateSettings(_ context.Context, path string) (vos.TemplateSettingsDefinition, error) {
+	return r.settings[path], nil
+}
+
+func (r *fakeDefinitionReader) withEnvironments(layer string, envs ...vos.EnvironmentDefinition) {
+	r.environments[filepath.Join(layer, "environments.yaml")] = envs
+}
+
+func (r *fakeDefinitionReader) withStep(t *testing.T, layer, dirName string, stepFile vos.StepFileDefinition) {
+	stepName, err := vos.NewStepNameDefinition(dirName)
+	require.NoError(t, err)
+	stepsDir := filepath.Join(layer, "steps")
+	r.stepNames[stepsDir] = append(r.stepNames[stepsDir], stepName)
+	r.stepFiles[filepath.Join(stepsDir, dirName, "commands.yaml")] = stepFile
+}
+
+func (r *fakeDefinitionReader) withVariables(layer, env, step string, variables ...vos.VariableDefinition) {
+	r.variables[filepath.Join(layer, "variables", env, step+".yaml")] = variables
+}
+
+func command(t *testing.T, name, cmd string) vos.CommandDefinition {
+	command, err := vos.NewCommandDefinition(name, cmd)
+	require.NoError(t, err)
+	return command
+}
+
+func variable(t *testing.T, name string, value interface{}) vos.VariableDefinition {
+	variable, err := vos.NewVariableDefinition(name, value)
+	require.NoError(t, err)
+	return variable
+}
+
+func environment(t *testing.T, value, name string) vos.EnvironmentDefinition {
+	env, err := vos.NewEnvironment(value, name)
+	require.NoError(t, err)
+	return env
+}
+
+func stepFile(commands ...vos.CommandDefinition) vos.StepFileDefinition {
+	return vos.NewStepFileDefinition(commands, vos.NewStepHooksDefinition(), false)
+}
+
+func layers(t *testing.T, names ...string) []vos.LayerDefinition {
+	result := make([]vos.LayerDefinition, 0, len(names))
+	for _, name := range names {
+		layer, err := vos.NewLayerDefinition(name, name)
+		require.NoError(t, err)
+		result = append(result, layer)
+	}
+	return result
+}
+
+func commandNames(commands []vos.CommandDefinition) []string {
+	names := make([]string, 0, len(commands))
+	for _, command := range commands {
+		names = append(names, command.Name())
+	}
+	return names
+}
+
+func TestPlanBuilder_Build_SingleLayer(t *testing.T) {
+	reader := newFakeDefinitionReader()
+	reader.withEnvironments("base", environment(t, "dev", "Desarrollo"))
+	reader.withStep(t, "base", "01-test", stepFile(command(t, "unit", "go test ./...")))
+	reader.withStep(t, "base", "02-deploy", stepFile(command(t, "deploy", "make deploy")))
+
+	plan, err := services.NewPlanBuilder(reader).Build(context.Background(), layers(t, "base"), "test", "dev")
+
+	require.NoError(t, err)
+	require.Len(t, plan.Steps(), 1)
+	assert.Equal(t, "test", plan.Steps()[0].NameDef().Name())
+	assert.Equal(t, []string{"unit"}, commandNames(plan.Steps()[0].CommandsDef()))
+}
+
+func TestPlanBuilder_Build_Overlays(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should add overlay steps and keep the order of the step names", func(t *testing.T) {
+		reader := newFakeDefinitionReader()
+		reader.withEnvironments("base", environment(t, "dev", "Desarrollo"))
+		reader.withStep(t, "base", "01-test", stepFile(command(t, "unit", "go test ./...")))
+		reader.withStep(t, "base", "03-deploy", stepFile(command(t, "deploy", "make deploy")))
+		reader.withStep(t, "overlay", "02-scan", stepFile(command(t, "scan", "trivy fs .")))
+
+		plan, err := services.NewPlanBuilder(reader).Build(ctx, layers(t, "base", "overlay"), "", "dev")
+
+		require.NoError(t, err)
+		require.Len(t, plan.Steps(), 3)
+		assert.Equal(t, "test", plan.Steps()[0].NameDef().Name())
+		assert.Equal(t, "scan", plan.Steps()[1].NameDef().Name())
+		assert.Equal(t, "deploy", plan.Steps()[2].NameDef().Name())
+		assert.Equal(t, "overlay", plan.Steps()[1].CommandsDef()[0].Layer())
+	})
+
+	t.Run("should replace, append and remove commands by name", func(t *testing.T) {
+		reader := newFakeDefinitionReader()
+		reader.withEnvironments("base", environment(t, "dev", "Desarrollo"))
+		reader.withStep(t, "base", "01-test", stepFile(
+			command(t, "lint", "golangci-lint run"),
+			command(t, "unit", "go test ./..."),
+			command(t, "bench", "go test -bench ."),
+		))
+		removed, err := vos.NewRemovedCommandDefinition("bench")
+		require.NoError(t, err)
+		reader.withStep(t, "overlay", "01-test", stepFile(
+			command(t, "unit", "go test -race ./..."),
+			removed,
+			command(t, "e2e", "make e2e"),
+		))
+
+		plan, err := services.NewPlanBuilder(reader).Build(ctx, layers(t, "base", "overlay"), "test", "dev")
+
+		require.NoError(t, err)
+		commands := plan.Steps()[0].CommandsDef()
+		assert.Equal(t, []string{"lint", "unit", "e2e"}, commandNames(commands))
+		assert.Equal(t, "base", commands[0].Layer())
+		assert.Equal(t, "go test -race ./...", commands[1].Cmd())
+		assert.Equal(t, "overlay", commands[1].Layer())
+		assert.Equal(t, "overlay", commands[2].Layer())
+	})
+
+	t.Run("should fail when an overlay removes a missing command", func(t *testing.T) {
+		reader := newFakeDefinitionReader()
+		reader.withEnvironments("base", environment(t, "dev", "Desarrollo"))
+		reader.withStep(t, "base", "01-test", stepFile(command(t, "unit", "go test ./...")))
+		removed, err := vos.NewRemovedCommandDefinition("bench")
+		require.NoError(t, err)
+		reader.withStep(t, "overlay", "01-test", stepFile(removed))
+
+		_, err = services.NewPlanBuilder(reader).Build(ctx, layers(t, "base", "overlay"), "test", "dev")
+
+		assert.ErrorContains(t, err, "bench")
+	})
+
+	t.Run("should replace the whole step and drop the base step directory", func(t *testing.T) {
+		reader := newFakeDefinitionReader()
+		reader.withEnvironments("base", environment(t, "dev", "Desarrollo"))
+		reader.withStep(t, "base", "01-test", stepFile(
+			command(t, "lint", "golangci-lint run"),
+			command(t, "unit", "go test ./..."),
+		))
+		reader.withStep(t, "overlay", "01-test", vos.NewStepFileDefinition(
+			[]vos.CommandDefinition{command(t, "tox", "tox")}, vos.NewStepHooksDefinition(), true))
+
+		plan, err := services.NewPlanBuilder(reader).Build(ctx, layers(t, "base", "overlay"), "test", "dev")
+
+		require.NoError(t, err)
+		step := plan.Steps()[0]
+		assert.Equal(t, []string{"tox"}, commandNames(step.CommandsDef()))
+		sources := step.SourcesDef()
+		require.Len(t, sources, 2)
+		assert.Empty(t, sources[0].StepDir())
+		assert.Equal(t, filepath.Join("overlay", "steps", "01-test"), sources[1].StepDir())
+	})
+
+	t.Run("should deep merge variables and record the layer of each one", func(t *testing.T) {
+		reader := newFakeDefinitionReader()
+		reader.withEnvironments("base", environment(t, "dev", "Desarrollo"))
+		reader.withStep(t, "base", "01-test", stepFile(command(t, "unit", "go test ./...")))
+		reader.withVariables("base", "dev", "test",
+			variable(t, "region", "us-east-1"),
+			variable(t, "limits", map[string]interface{}{"cpu": "1", "memory": "512Mi"}),
+		)
+		reader.withVariables("overlay", "dev", "test",
+			variable(t, "limits", map[string]interface{}{"memory": "1Gi"}),
+			variable(t, "team", "payments"),
+		)
+
+		plan, err := services.NewPlanBuilder(reader).Build(ctx, layers(t, "base", "overlay"), "test", "dev")
+
+		require.NoError(t, err)
+		variables := plan.Steps()[0].VariablesDef()
+		require.Len(t, variables, 3)
+		assert.Equal(t, "region", variables[0].Name())
+		assert.Equal(t, "base", variables[0].Layer())
+		assert.Equal(t, map[string]interface{}{"cpu": "1", "memory": "1Gi"}, variables[1].Value())
+		assert.Equal(t, "overlay", variables[1].Layer())
+		assert.Equal(t, "overlay", variables[2].Layer())
+	})
+
+	t.Run("should merge environments by value", func(t *testing.T) {
+		reader := newFakeDefinitionReader()
+		reader.withEnvironments("base", environment(t, "dev", "Desarrollo"), environment(t, "prod", "Producción"))
+		reader.withEnvironments("overlay", environment(t, "prod", "Producción EU"), environment(t, "qa", "Calidad"))
+		reader.withStep(t, "base", "01-test", stepFile(command(t, "unit", "go test ./...")))
+
+		plan, err := services.NewPlanBuilder(reader).Build(ctx, layers(t, "base", "overlay"), "test", "prod")
+		require.NoError(t, err)
+		assert.Equal(t, "Producción EU", plan.Environment().Name())
+		assert.Equal(t, "overlay", plan.Environment().Layer())
+
+		plan, err = services.NewPlanBuilder(reader).Build(ctx, layers(t, "base", "overlay"), "test", "qa")
+		require.NoError(t, err)
+		assert.Equal(t, "Calidad", plan.Environment().Name())
+	})
+
+	t.Run("should keep the base fields an overlay does not declare", func(t *testing.T) {
+		prod, err := vos.NewEnvironment("prod", "Producción",
+			vos.WithExtends("staging"), vos.WithOrder(3), vos.WithProtected(true),
+			vos.WithAllowedBranches([]string{"main"}))
+		require.NoError(t, err)
+
+		reader := newFakeDefinitionReader()
+		reader.withEnvironments("base", environment(t, "staging", "Staging"), prod)
+		reader.withEnvironments("overlay", environment(t, "prod", "Producción EU"))
+		reader.withStep(t, "base", "01-test", stepFile(command(t, "unit", "go test ./...")))
+
+		plan, err := services.NewPlanBuilder(reader).Build(ctx, layers(t, "base", "overlay"), "test", "prod")
+		require.NoError(t, err)
+		env := plan.Environment()
+		assert.Equal(t, "Producción EU", env.Name())
+		assert.True(t, env.IsProtected())
+		assert.Equal(t, "staging", env.Extends())
+		assert.Equal(t, 3, env.Order())
+		assert.Equal(t, []string{"main"}, env.AllowedBranches())
+		assert.Equal(t, "overlay", env.Layer())
+	})
+
+	t.Run("should let an overlay unprotect an environment explicitly", func(t *testing.T) {
+		prod, err := vos.NewEnvironment("prod", "Producción", vos.WithProtected(true))
+		require.NoError(t, err)
+		unprotected, err := vos.NewEnvironment("prod", "Producción", vos.WithProtected(false))
+		require.NoError(t, err)
+
+		reader := newFakeDefinitionReader()
+		reader.withEnvironments("base", prod)
+		reader.withEnvironments("overlay", unprotected)
+		reader.withStep(t, "base", "01-test", stepFile(command(t, "unit", "go test ./...")))
+
+		plan, err := services.NewPlanBuilder(reader).Build(ctx, layers(t, "base", "overlay"), "test", "prod")
+		require.NoError(t, err)
+		assert.False(t, plan.Environment().IsProtected())
+	})
+}
+
+func TestPlanBuilder_Build_EnvironmentInheritance(t *testing.T) {
+	ctx := context.Background()
+
+	newReader := func(t *testing.T, envs ...vos.EnvironmentDefinition) *fakeDefinitionReader {
+		reader := newFakeDefinitionReader()
+		reader.withEnvironments("base", envs...)
+		reader.withStep(t, "base", "01-deploy", stepFile(command(t, "deploy", "make deploy")))
+		return reader
+	}
+
+	t.Run("should layer the variable files from the parent to the child", func(t *testing.T) {
+		staging := environment(t, "staging", "Staging")
+		prod, err := vos.NewEnvironment("prod", "Producción", vos.WithExtends("staging"), vos.WithProtected(true))
+		require.NoError(t, err)
+		reader := newReader(t, staging, prod)
+		reader.withVariables("base", "staging", "deploy",
+			variable(t, "replicas", "1"),
+			variable(t, "region", "us-east-1"),
+		)
+		reader.withVariables("base", "prod", "deploy", variable(t, "replicas", "3"))
+
+		plan, err := services.NewPlanBuilder(reader).Build(ctx, layers(t, "base"), "deploy", "prod")
+
+		require.NoError(t, err)
+		assert.Equal(t, "prod", plan.Environment().String())
+		assert.True(t, plan.Environment().IsProtected())
+		variables := plan.Steps()[0].VariablesDef()
+		require.Len(t, variables, 2)
+		assert.Equal(t, "replicas", variables[0].Name())
+		assert.Equal(t, "3", variables[0].Value())
+		assert.Equal(t, "us-east-1", variables[1].Value())
+		assert.Equal(t, []string{
+			filepath.Join("base", "variables", "deploy.yaml"),
+			filepath.Join("base", "variables", "staging", "deploy.yaml"),
+			filepath.Join("base", "variables", "prod", "deploy.yaml"),
+		}, plan.Steps()[0].SourcesDef()[0].VariablesFiles())
+	})
+
+	t.Run("should fail when the parent environment is not defined", func(t *testing.T) {
+		prod, err := vos.NewEnvironment("prod", "Producción", vos.WithExtends("staging"))
+		require.NoError(t, err)
+
+		_, err = services.NewPlanBuilder(newReader(t, prod)).Build(ctx, layers(t, "base"), "deploy", "prod")
+
+		assert.ErrorContains(t, err, "staging")
+	})
+
+	t.Run("should fail when the inheritance forms a cycle", func(t *testing.T) {
+		staging, err := vos.NewEnvironment("staging", "Staging", vos.WithExtends("prod"))
+		require.NoError(t, err)
+		prod, err := vos.NewEnvironment("prod", "Producción", vos.WithExtends("staging"))
+		require.NoError(t, err)
+
+		_, err = services.NewPlanBuilder(newReader(t, staging, prod)).Build(ctx, layers(t, "base"), "deploy", "prod")
+
+		assert.ErrorContains(t, err, "ciclo")
+	})
+}
+
+func TestPlanBuilder_Build_VariablePrecedence(t *testing.T) {
+	reader := newFakeDefinitionReader()
+	reader.withEnvironments("base", environment(t, "dev", "Desarrollo"))
+	reader.withStep(t, "base", "01-deploy", stepFile(command(t, "deploy", "make deploy")))
+	reader.variables[filepath.Join("base", "variables", "deploy.yaml")] = []vos.VariableDefinition{
+		variable(t, "replicas", "1"),
+		variable(t, "region", "us-east-1"),
+		variable(t, "image", "app"),
+		variable(t, "debug", "false"),
+	}
+	reader.withVariables("base", "dev", "deploy",
+		variable(t, "replicas", "2"),
+		variable(t, "region", "eu-west-1"),
+	)
+	project, err := vos.NewVariableOverridesDefinition(vos.ProjectConfigLayer,
+		map[string]map[string]map[string]interface{}{
+			vos.AnyScope: {vos.AnyScope: {"region": "us-west-2", "debug": "true"}},
+			"dev":        {"deploy": {"replicas": "3"}},
+			"prod":       {"deploy": {"replicas": "10"}},
+		})
+	require.NoError(t, err)
+	local, err := vos.NewVariableOverridesDefinition(vos.LocalConfigLayer,
+		map[string]map[string]map[string]interface{}{
+			"dev": {vos.AnyScope: {"debug": "verbose"}},
+		})
+	require.NoError(t, err)
+
+	plan, err := services.NewPlanBuilder(reader).Build(
+		context.Background(), layers(t, "base"), "deploy", "dev", project, local)
+
+	require.NoError(t, err)
+	byName := make(map[string]vos.VariableDefinition)
+	for _, variable := range plan.Steps()[0].VariablesDef() {
+		byName[variable.Name()] = variable
+	}
+	assert.Equal(t, "app", byName["image"].Value(), "template default")
+	assert.Equal(t, "base", byName["image"].Layer())
+	assert.Equal(t, "us-west-2", byName["region"].Value(), "project config over the environment file")
+	assert.Equal(t, vos.ProjectConfigLayer, byName["region"].Layer())
+	assert.Equal(t, "3", byName["replicas"].Value(), "project step override over the project wildcard")
+	assert.Equal(t, "verbose", byName["debug"].Value(), "local file over the project config")
+	assert.Equal(t, vos.LocalConfigLayer, byName["debug"].Layer())
+
+	chain := byName["replicas"].Chain()
+	require.Len(t, chain, 3, "the project override, the environment file and the template default")
+	assert.Equal(t, vos.ProjectConfigLayer, chain[0].Layer())
+	assert.Equal(t, "2", chain[1].Value())
+	assert.Equal(t, filepath.Join("variables", "dev", "deploy.yaml"), chain[1].File())
+	assert.Equal(t, "1", chain[2].Value())
+	assert.Equal(t, filepath.Join("variables", "deploy.yaml"), chain[2].File())
+}
+
+func TestPlanBuilder_Build_StepOutputs(t *testing.T) {
+	outputCommand := func(t *testing.T, name, output string) vos.CommandDefinition {
+		t.Helper()
+		out, err := vos.NewOutputDefinition(output, "", "(.*)")
+		require.NoError(t, err)
+		cmd, err := vos.NewCommandDefinition(name, "make "+name, vos.WithOutputs([]vos.OutputDefinition{out}))
+		require.NoError(t, err)
+		return cmd
+	}
+	reader := newFakeDefinitionReader()
+	reader.withEnvironments("base", environment(t, "dev", "Desarrollo"))
+	reader.withStep(t, "base", "01-supply", stepFile(outputCommand(t, "supply", "url")))
+	reader.withStep(t, "base", "02-deploy", stepFile(outputCommand(t, "deploy", "url")))
+
+	t.Run("should report an output that shadows the output of an earlier step", func(t *testing.T) {
+		plan, err := services.NewPlanBuilder(reader).Build(context.Background(), layers(t, "base"), "deploy", "dev")
+
+		require.NoError(t, err)
+		collisions := plan.OutputCollisions()
+		require.Len(t, collisions, 1)
+		assert.Equal(t, "url", collisions[0].Name())
+		assert.Equal(t, "deploy", collisions[0].Step())
+		assert.Equal(t, "supply", collisions[0].ShadowedStep())
+		assert.False(t, plan.Settings().StrictOutputs())
+	})
+
+	t.Run("should let an overlay enable strict outputs", func(t *testing.T) {
+		reader.settings[filepath.Join("base", "template.yaml")] = vos.NewTemplateSettingsDefinition()
+		reader.settings[filepath.Join("overlay", "template.yaml")] = vos.NewTemplateSettingsDefinition(vos.WithStrictOutputs(true))
+
+		plan, err := services.NewPlanBuilder(reader).Build(context.Background(), layers(t, "base", "overlay"), "deploy", "dev")
+
+		require.NoError(t, err)
+		assert.True(t, plan.Settings().StrictOutputs())
+	})
+}
+
+func TestPlanBuilder_Build_FingerprintScope(t *testing.T) {
+	scope := func(t *testing.T, include, exclude []string) vos.FingerprintScopeDefinition {
+		t.Helper()
+		fingerprint, err := vos.NewFingerprintScopeDefinition(include, exclude)
+		require.NoError(t, err)
+		return fingerprint
+	}
+	reader := newFakeDefinitionReader()
+	reader.withEnvironments("base", environment(t, "dev", "Desarrollo"))
+	reader.withStep(t, "base", "01-package", stepFile(command(t, "build", "mvn package")).
+		WithFingerprint(scope(t, []string{"src/**", "pom.xml"}, []string{"**/*.md"})))
+
+	t.Run("should keep the fingerprint block of the base layer", func(t *testing.T) {
+		reader.withStep(t, "overlay", "01-package", stepFile(command(t, "lint", "mvn verify")))
+
+		plan, err := services.NewPlanBuilder(reader).Build(context.Background(), layers(t, "base", "overlay"), "package", "dev")
+
+		require.NoError(t, err)
+		fingerprint := plan.Steps()[0].FingerprintScopeDef()
+		assert.Equal(t, []string{"src/**", "pom.xml"}, fingerprint.Include())
+		assert.Equal(t, []string{"**/*.md"}, fingerprint.Exclude())
+	})
+
+	t.Run("should let an overlay replace the fingerprint block", func(t *testing.T) {
+		reader.withStep(t, "other", "01-package", stepFile(command(t, "lint", "mvn verify")).
+			WithFingerprint(scope(t, []string{"app/**"}, nil)))
+
+		plan, err := services.NewPlanBuilder(reader).Build(context.Background(), layers(t, "base", "other"), "package", "dev")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app/**"}, plan.Steps()[0].FingerprintScopeDef().Include())
+		assert.Empty(t, plan.Steps()[0].FingerprintScopeDef().Exclude())
+	})
+
+	t.Run("should reject a pattern outside the project", func(t *testing.T) {
+		_, err := vos.NewFingerprintScopeDefinition([]string{"../shared/**"}, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jairoprogramador/vex/internal/domain/definition/vos"
+)
+
+// PromotionChain devuelve, en orden, los entornos que participan en la promoción:
+// los que definen un orden mayor que cero. Los de igual orden conservan el orden
+// en que están definidos.
+func PromotionChain(environments []vos.EnvironmentDefinition) []vos.EnvironmentDefinition {
+	chain := make([]vos.EnvironmentDefinition, 0, len(environments))
+	for _, env := range environments {
+		if env.Order() > 0 {
+			chain = append(chain, env)
+		}
+	}
+	sort.SliceStable(chain, func(i, j int) bool {
+		return chain[i].Order() < chain[j].Order()
+	})
+	return chain
+}
+
+// ValidatePromotion comprueba que el entorno de destino esté en el nivel siguiente al
+// del origen en la cadena de promoción y lo devuelve. Los entornos con el mismo orden
+// forman un nivel, p. ej. dos regiones de producción: a cualquiera de ellos se puede
+// promover desde el nivel anterior, pero no de uno a otro.
+func ValidatePromotion(
+	environments []vos.EnvironmentDefinition, source, target string) (vos.EnvironmentDefinition, error) {
+
+	chain := PromotionChain(environments)
+	for _, env := range chain {
+		if env.String() != source {
+			continue
+		}
+		nextLevel := nextPromotionLevel(chain, env.Order())
+		if len(nextLevel) == 0 {
+			return vos.EnvironmentDefinition{}, fmt.Errorf("el entorno '%s' pertenece al último nivel de la cadena de promoción", source)
+		}
+		names := make([]string, 0, len(nextLevel))
+		for _, next := range nextLevel {
+			if next.String() == target {
+				return next, nil
+			}
+			names = append(names, "'"+next.String()+"'")
+		}
+		return vos.EnvironmentDefinition{}, fmt.Errorf(
+			"'%s' solo se puede promover a %s, el siguiente nivel de la cadena de promoción", source, strings.Join(names, " o "))
+	}
+
+	for _, env := range environments {
+		if env.String() == source {
+			return vos.EnvironmentDefinition{}, fmt.Errorf(
+				"el entorno '%s' no tiene 'order' en environments.yaml y no participa en la promoción", source)
+		}
+	}
+	return vos.EnvironmentDefinition{}, fmt.Errorf("el entorno '%s' no es válido", source)
+}
+
+// nextPromotionLevel devuelve los entornos de la cadena con el menor orden mayor que order.
+func nextPromotionLevel(chain []vos.EnvironmentDefinition, order int) []vos.EnvironmentDefinition {
+	var level []vos.EnvironmentDefinition
+	for _, env := range chain {
+		if env.Order() <= order {
+			continue
+		}
+		if len(level) > 0 && env.Order() != level[0].Order() {
+			break
+		}
+		level = append(level, env)
+	}
+	return level
+}
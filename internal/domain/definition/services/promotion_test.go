@@ -0,0 +1,73 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/jairoprogramador/vex/internal/domain/definition/services"
+	"github.com/jairoprogramador/vex/internal/domain/definition/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePromotion(t *testing.T) {
+	ordered := func(value string, order int) vos.EnvironmentDefinition {
+		env, err := vos.NewEnvironment(value, value, vos.WithOrder(order))
+		require.NoError(t, err)
+		return env
+	}
+	environments := []vos.EnvironmentDefinition{
+		ordered("prod", 3), ordered("sand", 1), ordered("local", 0), ordered("staging", 2),
+	}
+
+	t.Run("should follow the order of the environments", func(t *testing.T) {
+		chain := services.PromotionChain(environments)
+
+		require.Len(t, chain, 3)
+		assert.Equal(t, "sand", chain[0].String())
+		assert.Equal(t, "staging", chain[1].String())
+		assert.Equal(t, "prod", chain[2].String())
+	})
+
+	t.Run("should accept the next environment", func(t *testing.T) {
+		target, err := services.ValidatePromotion(environments, "staging", "prod")
+
+		require.NoError(t, err)
+		assert.Equal(t, "prod", target.String())
+	})
+
+	t.Run("should reject skipping an environment", func(t *testing.T) {
+		_, err := services.ValidatePromotion(environments, "sand", "prod")
+
+		assert.ErrorContains(t, err, "'staging'")
+	})
+
+	t.Run("should reject promoting from the last environment", func(t *testing.T) {
+		_, err := services.ValidatePromotion(environments, "prod", "staging")
+
+		assert.ErrorContains(t, err, "último")
+	})
+
+	t.Run("should reject environments without order", func(t *testing.T) {
+		_, err := services.ValidatePromotion(environments, "local", "sand")
+
+		assert.ErrorContains(t, err, "order")
+	})
+
+	t.Run("should accept every environment of the next level and reject moving sideways", func(t *testing.T) {
+		regions := []vos.EnvironmentDefinition{
+			ordered("sand", 1), ordered("staging", 2), ordered("prod-eu", 3), ordered("prod-us", 3),
+		}
+
+		for _, region := range []string{"prod-eu", "prod-us"} {
+			target, err := services.ValidatePromotion(regions, "staging", region)
+			require.NoError(t, err)
+			assert.Equal(t, region, target.String())
+		}
+
+		_, err := services.ValidatePromotion(regions, "prod-eu", "prod-us")
+		assert.ErrorContains(t, err, "último nivel")
+
+		_, err = services.ValidatePromotion(regions, "sand", "prod-us")
+		assert.ErrorContains(t, err, "'staging'")
+	})
+}
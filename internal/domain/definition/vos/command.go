@@ -11,6 +11,8 @@ type CommandDefinition struct {
 	workdir       string
 	templateFiles []string
 	outputs       []OutputDefinition
+	layer         string
+	removed       bool
 }
 
 type CommandOption func(*CommandDefinition)
@@ -55,6 +57,21 @@ func NewCommandDefinition(name, cmd string, opts ...CommandOption) (CommandDefin
 	return *cmdDef, nil
 }
 
+// NewRemovedCommandDefinition crea la marca con la que un overlay elimina el
+// comando del mismo nombre definido en las capas anteriores.
+func NewRemovedCommandDefinition(name string) (CommandDefinition, error) {
+	if name == "" {
+		return CommandDefinition{}, errors.New("el nombre del comando a eliminar no puede estar vacío")
+	}
+	return CommandDefinition{name: name, removed: true}, nil
+}
+
+// FromLayer devuelve una copia del comando que recuerda la capa de la plantilla que lo definió.
+func (cd CommandDefinition) FromLayer(layer string) CommandDefinition {
+	cd.layer = layer
+	return cd
+}
+
 func WithWorkdir(workdir string) CommandOption {
 	return func(c *CommandDefinition) {
 		c.workdir = workdir
@@ -106,3 +123,12 @@ func (cd CommandDefinition) Outputs() []OutputDefinition {
 	copy(outputsCopy, cd.outputs)
 	return outputsCopy
 }
+
+func (cd CommandDefinition) Layer() string {
+	return cd.layer
+}
+
+// IsRemoved indica si es la marca que elimina el comando de las capas anteriores.
+func (cd CommandDefinition) IsRemoved() bool {
+	return cd.removed
+}
@@ -1,20 +1,121 @@
 package vos
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"path"
+)
 
 type EnvironmentDefinition struct {
-	name  string
-	value string
+	name        string
+	value       string
+	description string
+	extends     string
+	order       int
+	protected   bool
+	group       string
+	branches    []string
+	remote      string
+	layer       string
+	// protectedSet distingue un 'protected: false' explícito de uno omitido,
+	// para que una capa pueda desproteger un entorno sin hacerlo al omitirlo.
+	protectedSet bool
 }
 
-func NewEnvironment(value, name string) (EnvironmentDefinition, error) {
+// DefaultRemote es el remoto en el que se comprueba que el commit de un entorno
+// protegido ya fue publicado si el entorno no configura otro.
+const DefaultRemote = "origin"
+
+type EnvironmentOption func(*EnvironmentDefinition)
+
+// WithEnvironmentDescription describe para qué se usa el entorno.
+func WithEnvironmentDescription(description string) EnvironmentOption {
+	return func(e *EnvironmentDefinition) {
+		e.description = description
+	}
+}
+
+// WithExtends hace que el entorno herede los archivos de variables de otro entorno,
+// que se aplican antes que los suyos.
+func WithExtends(parent string) EnvironmentOption {
+	return func(e *EnvironmentDefinition) {
+		e.extends = parent
+	}
+}
+
+// WithOrder fija la posición del entorno en la cadena de promoción.
+func WithOrder(order int) EnvironmentOption {
+	return func(e *EnvironmentDefinition) {
+		e.order = order
+	}
+}
+
+// WithProtected marca el entorno como protegido.
+func WithProtected(protected bool) EnvironmentOption {
+	return func(e *EnvironmentDefinition) {
+		e.protected = protected
+		e.protectedSet = true
+	}
+}
+
+// WithGroup agrupa el entorno con otros que se ejecutan juntos, p. ej. los
+// entornos regionales de producción.
+func WithGroup(group string) EnvironmentOption {
+	return func(e *EnvironmentDefinition) {
+		e.group = group
+	}
+}
+
+// WithAllowedBranches limita las ramas desde las que se puede ejecutar un entorno
+// protegido. Se aceptan patrones como 'release/*'.
+func WithAllowedBranches(branches []string) EnvironmentOption {
+	return func(e *EnvironmentDefinition) {
+		e.branches = branches
+	}
+}
+
+// WithRemote indica el remoto en el que debe estar publicado el commit de un entorno protegido.
+func WithRemote(remote string) EnvironmentOption {
+	return func(e *EnvironmentDefinition) {
+		e.remote = remote
+	}
+}
+
+func NewEnvironment(value, name string, opts ...EnvironmentOption) (EnvironmentDefinition, error) {
 	if value == "" {
 		return EnvironmentDefinition{}, errors.New("el nombre del entorno no puede estar vacío")
 	}
 	if name == "" {
 		return EnvironmentDefinition{}, errors.New("el nombre del entorno no puede estar vacío")
 	}
-	return EnvironmentDefinition{value: value, name: name}, nil
+
+	env := &EnvironmentDefinition{value: value, name: name}
+	for _, opt := range opts {
+		opt(env)
+	}
+
+	if env.extends == value {
+		return EnvironmentDefinition{}, errors.New("un entorno no puede extenderse a sí mismo")
+	}
+	for _, branch := range env.branches {
+		if _, err := path.Match(branch, ""); err != nil {
+			return EnvironmentDefinition{}, fmt.Errorf("patrón de rama inválido '%s': %w", branch, err)
+		}
+	}
+	if env.order < 0 {
+		return EnvironmentDefinition{}, errors.New("el orden del entorno no puede ser negativo")
+	}
+	return *env, nil
+}
+
+// FromLayer devuelve una copia del entorno que recuerda la capa de la plantilla que lo definió.
+func (e EnvironmentDefinition) FromLayer(layer string) EnvironmentDefinition {
+	e.layer = layer
+	return e
+}
+
+func (e EnvironmentDefinition) Layer() string {
+	return e.layer
 }
 
 func (e EnvironmentDefinition) String() string {
@@ -25,6 +126,86 @@ func (e EnvironmentDefinition) Name() string {
 	return e.name
 }
 
+func (e EnvironmentDefinition) Description() string {
+	return e.description
+}
+
+// Extends devuelve el valor del entorno padre, o vacío si el entorno no hereda de otro.
+func (e EnvironmentDefinition) Extends() string {
+	return e.extends
+}
+
+// Order devuelve la posición del entorno en la cadena de promoción; 0 si no la tiene.
+func (e EnvironmentDefinition) Order() int {
+	return e.order
+}
+
+func (e EnvironmentDefinition) IsProtected() bool {
+	return e.protected
+}
+
+func (e EnvironmentDefinition) Group() string {
+	return e.group
+}
+
+// AllowedBranches devuelve los patrones de las ramas permitidas; vacío si no hay restricción.
+func (e EnvironmentDefinition) AllowedBranches() []string {
+	branchesCopy := make([]string, len(e.branches))
+	copy(branchesCopy, e.branches)
+	return branchesCopy
+}
+
+// IsBranchAllowed indica si el entorno se puede ejecutar desde una rama.
+func (e EnvironmentDefinition) IsBranchAllowed(branch string) bool {
+	if len(e.branches) == 0 {
+		return true
+	}
+	for _, pattern := range e.branches {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (e EnvironmentDefinition) Remote() string {
+	if e.remote == "" {
+		return DefaultRemote
+	}
+	return e.remote
+}
+
+// MergedWith aplica sobre el entorno los campos que declara el de una capa posterior.
+// Los campos que el overlay no declara conservan el valor del entorno base.
+func (e EnvironmentDefinition) MergedWith(overlay EnvironmentDefinition) EnvironmentDefinition {
+	merged := e
+	merged.name = overlay.name
+	merged.layer = overlay.layer
+	if overlay.description != "" {
+		merged.description = overlay.description
+	}
+	if overlay.extends != "" {
+		merged.extends = overlay.extends
+	}
+	if overlay.order != 0 {
+		merged.order = overlay.order
+	}
+	if overlay.protectedSet {
+		merged.protected = overlay.protected
+		merged.protectedSet = true
+	}
+	if overlay.group != "" {
+		merged.group = overlay.group
+	}
+	if len(overlay.branches) > 0 {
+		merged.branches = overlay.branches
+	}
+	if overlay.remote != "" {
+		merged.remote = overlay.remote
+	}
+	return merged
+}
+
 func (e EnvironmentDefinition) Equals(other EnvironmentDefinition) bool {
 	return e.value == other.value
 }
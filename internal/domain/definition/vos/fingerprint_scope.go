@@ -0,0 +1,53 @@
+package vos
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// FingerprintScopeDefinition es el bloque fingerprint de commands.yaml: los archivos
+// del proyecto que cuentan para el fingerprint de código del paso.
+type FingerprintScopeDefinition struct {
+	include []string
+	exclude []string
+}
+
+func NewFingerprintScopeDefinition(include, exclude []string) (FingerprintScopeDefinition, error) {
+	for _, pattern := range append(append([]string(nil), include...), exclude...) {
+		if err := validateFingerprintPattern(pattern); err != nil {
+			return FingerprintScopeDefinition{}, err
+		}
+	}
+	return FingerprintScopeDefinition{
+		include: append([]string(nil), include...),
+		exclude: append([]string(nil), exclude...),
+	}, nil
+}
+
+// validateFingerprintPattern exige rutas relativas al proyecto con segmentos válidos para path.Match.
+func validateFingerprintPattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("el patrón de fingerprint no puede estar vacío")
+	}
+	if path.IsAbs(pattern) {
+		return fmt.Errorf("el patrón de fingerprint '%s' debe ser relativo a la raíz del proyecto", pattern)
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == ".." {
+			return fmt.Errorf("el patrón de fingerprint '%s' no puede salir del proyecto", pattern)
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("el patrón de fingerprint '%s' es inválido: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (f FingerprintScopeDefinition) Include() []string {
+	return append([]string(nil), f.include...)
+}
+
+func (f FingerprintScopeDefinition) Exclude() []string {
+	return append([]string(nil), f.exclude...)
+}
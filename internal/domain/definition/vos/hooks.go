@@ -0,0 +1,61 @@
+package vos
+
+// StepHooksDefinition agrupa las listas de comandos auxiliares de un paso que
+// no forman parte de su flujo principal: los que se ejecutan cuando un comando
+// falla (on_failure), los que se ejecutan siempre al terminar (finally) y los
+// que compensan el paso cuando un paso posterior falla (rollback).
+type StepHooksDefinition struct {
+	onFailure []CommandDefinition
+	finally   []CommandDefinition
+	rollback  []CommandDefinition
+}
+
+type HooksOption func(*StepHooksDefinition)
+
+func NewStepHooksDefinition(opts ...HooksOption) StepHooksDefinition {
+	hooks := &StepHooksDefinition{}
+	for _, opt := range opts {
+		opt(hooks)
+	}
+	return *hooks
+}
+
+func WithOnFailure(commands []CommandDefinition) HooksOption {
+	return func(h *StepHooksDefinition) {
+		h.onFailure = commands
+	}
+}
+
+func WithFinally(commands []CommandDefinition) HooksOption {
+	return func(h *StepHooksDefinition) {
+		h.finally = commands
+	}
+}
+
+func WithRollback(commands []CommandDefinition) HooksOption {
+	return func(h *StepHooksDefinition) {
+		h.rollback = commands
+	}
+}
+
+func (h StepHooksDefinition) OnFailure() []CommandDefinition {
+	commandsCopy := make([]CommandDefinition, len(h.onFailure))
+	copy(commandsCopy, h.onFailure)
+	return commandsCopy
+}
+
+func (h StepHooksDefinition) Finally() []CommandDefinition {
+	commandsCopy := make([]CommandDefinition, len(h.finally))
+	copy(commandsCopy, h.finally)
+	return commandsCopy
+}
+
+func (h StepHooksDefinition) Rollback() []CommandDefinition {
+	commandsCopy := make([]CommandDefinition, len(h.rollback))
+	copy(commandsCopy, h.rollback)
+	return commandsCopy
+}
+
+func (h StepHooksDefinition) IsEmpty() bool {
+	return len(h.onFailure) == 0 && len(h.finally) == 0 && len(h.rollback) == 0
+}
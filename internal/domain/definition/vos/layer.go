@@ -0,0 +1,29 @@
+package vos
+
+import "errors"
+
+// LayerDefinition es una capa de la plantilla. Las capas se aplican en orden: la
+// primera es la plantilla base y cada una de las siguientes es un overlay sobre
+// las anteriores.
+type LayerDefinition struct {
+	name string
+	path string
+}
+
+func NewLayerDefinition(name, path string) (LayerDefinition, error) {
+	if name == "" {
+		return LayerDefinition{}, errors.New("el nombre de la capa de plantilla no puede estar vacío")
+	}
+	if path == "" {
+		return LayerDefinition{}, errors.New("la ruta de la capa de plantilla no puede estar vacía")
+	}
+	return LayerDefinition{name: name, path: path}, nil
+}
+
+func (l LayerDefinition) Name() string {
+	return l.name
+}
+
+func (l LayerDefinition) Path() string {
+	return l.path
+}
@@ -0,0 +1,32 @@
+package vos
+
+import "fmt"
+
+// OutputCollision es una salida de un paso que tiene el mismo nombre que la salida
+// de un paso anterior y la oculta para los pasos siguientes.
+type OutputCollision struct {
+	name         string
+	step         string
+	shadowedStep string
+}
+
+func NewOutputCollision(name, step, shadowedStep string) OutputCollision {
+	return OutputCollision{name: name, step: step, shadowedStep: shadowedStep}
+}
+
+func (c OutputCollision) Name() string {
+	return c.name
+}
+
+func (c OutputCollision) Step() string {
+	return c.step
+}
+
+func (c OutputCollision) ShadowedStep() string {
+	return c.shadowedStep
+}
+
+func (c OutputCollision) String() string {
+	return fmt.Sprintf("la salida '%s' del paso '%s' oculta la del paso '%s'; usa ${step.%s.%s} para referirte a la anterior",
+		c.name, c.step, c.shadowedStep, c.shadowedStep, c.name)
+}
@@ -0,0 +1,44 @@
+package vos
+
+// StepFileDefinition es el contenido de commands.yaml en una capa de la plantilla.
+// Con replace, el paso reemplaza por completo al de las capas anteriores; si no,
+// sus comandos y hooks se combinan con ellos por nombre.
+type StepFileDefinition struct {
+	commands []CommandDefinition
+	hooks    StepHooksDefinition
+	replace  bool
+	// fingerprint es nil si la capa no declara el bloque fingerprint.
+	fingerprint *FingerprintScopeDefinition
+}
+
+func NewStepFileDefinition(commands []CommandDefinition, hooks StepHooksDefinition, replace bool) StepFileDefinition {
+	return StepFileDefinition{commands: commands, hooks: hooks, replace: replace}
+}
+
+func (f StepFileDefinition) Commands() []CommandDefinition {
+	commandsCopy := make([]CommandDefinition, len(f.commands))
+	copy(commandsCopy, f.commands)
+	return commandsCopy
+}
+
+func (f StepFileDefinition) Hooks() StepHooksDefinition {
+	return f.hooks
+}
+
+func (f StepFileDefinition) Replace() bool {
+	return f.replace
+}
+
+// WithFingerprint devuelve una copia con el bloque fingerprint declarado en la capa.
+func (f StepFileDefinition) WithFingerprint(scope FingerprintScopeDefinition) StepFileDefinition {
+	f.fingerprint = &scope
+	return f
+}
+
+// Fingerprint devuelve el bloque fingerprint e indica si la capa lo declara.
+func (f StepFileDefinition) Fingerprint() (FingerprintScopeDefinition, bool) {
+	if f.fingerprint == nil {
+		return FingerprintScopeDefinition{}, false
+	}
+	return *f.fingerprint, true
+}
@@ -0,0 +1,29 @@
+package vos
+
+// StepSourceDefinition son los archivos que una capa de la plantilla aporta a un paso:
+// el directorio del paso, vacío si la capa no lo define, y sus archivos de variables
+// para el entorno del plan y los entornos de los que hereda, del más general al más
+// específico. Los archivos pueden no existir.
+type StepSourceDefinition struct {
+	layer          string
+	stepDir        string
+	variablesFiles []string
+}
+
+func NewStepSourceDefinition(layer, stepDir string, variablesFiles []string) StepSourceDefinition {
+	return StepSourceDefinition{layer: layer, stepDir: stepDir, variablesFiles: variablesFiles}
+}
+
+func (s StepSourceDefinition) Layer() string {
+	return s.layer
+}
+
+func (s StepSourceDefinition) StepDir() string {
+	return s.stepDir
+}
+
+func (s StepSourceDefinition) VariablesFiles() []string {
+	filesCopy := make([]string, len(s.variablesFiles))
+	copy(filesCopy, s.variablesFiles)
+	return filesCopy
+}
@@ -0,0 +1,39 @@
+package vos
+
+// TemplateSettingsDefinition son las opciones generales de una plantilla, declaradas
+// en template.yaml. Cada opción recuerda si la capa la declaró, para que un overlay
+// solo cambie las que declara.
+type TemplateSettingsDefinition struct {
+	strictOutputs *bool
+}
+
+type TemplateSettingsOption func(*TemplateSettingsDefinition)
+
+// WithStrictOutputs hace que una salida de un paso que oculta la de un paso anterior
+// sea un error en lugar de una advertencia.
+func WithStrictOutputs(strict bool) TemplateSettingsOption {
+	return func(s *TemplateSettingsDefinition) {
+		s.strictOutputs = &strict
+	}
+}
+
+func NewTemplateSettingsDefinition(opts ...TemplateSettingsOption) TemplateSettingsDefinition {
+	settings := TemplateSettingsDefinition{}
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	return settings
+}
+
+// Merge devuelve la combinación de estas opciones con las de un overlay: prevalecen
+// las que el overlay declara.
+func (s TemplateSettingsDefinition) Merge(overlay TemplateSettingsDefinition) TemplateSettingsDefinition {
+	if overlay.strictOutputs != nil {
+		s.strictOutputs = overlay.strictOutputs
+	}
+	return s
+}
+
+func (s TemplateSettingsDefinition) StrictOutputs() bool {
+	return s.strictOutputs != nil && *s.strictOutputs
+}
@@ -5,6 +5,10 @@ import "errors"
 type VariableDefinition struct {
 	name  string
 	value interface{}
+	layer string
+	file  string
+	// overridden es la definición de una capa, archivo o entorno anterior que esta sobrescribe.
+	overridden *VariableDefinition
 }
 
 func NewVariableDefinition(name string, value interface{}) (VariableDefinition, error) {
@@ -14,6 +18,49 @@ func NewVariableDefinition(name string, value interface{}) (VariableDefinition,
 	return VariableDefinition{name: name, value: value}, nil
 }
 
+// FromLayer devuelve una copia de la variable que recuerda la capa de la plantilla que la definió.
+func (v VariableDefinition) FromLayer(layer string) VariableDefinition {
+	v.layer = layer
+	return v
+}
+
+// FromFile devuelve una copia de la variable que recuerda el archivo, relativo a su
+// capa, que la definió.
+func (v VariableDefinition) FromFile(file string) VariableDefinition {
+	v.file = file
+	return v
+}
+
+// Overriding devuelve una copia de la variable que recuerda la definición que sobrescribe.
+func (v VariableDefinition) Overriding(previous VariableDefinition) VariableDefinition {
+	v.overridden = &previous
+	return v
+}
+
+// WithValue devuelve una copia de la variable con otro valor.
+func (v VariableDefinition) WithValue(value interface{}) VariableDefinition {
+	v.value = value
+	return v
+}
+
+func (v VariableDefinition) Layer() string {
+	return v.layer
+}
+
+func (v VariableDefinition) File() string {
+	return v.file
+}
+
+// Chain devuelve la variable seguida de las definiciones que sobrescribe, de la más
+// reciente a la más antigua.
+func (v VariableDefinition) Chain() []VariableDefinition {
+	chain := []VariableDefinition{v}
+	for current := v.overridden; current != nil; current = current.overridden {
+		chain = append(chain, *current)
+	}
+	return chain
+}
+
 func (v VariableDefinition) Name() string {
 	return v.name
 }
@@ -0,0 +1,55 @@
+package vos
+
+import (
+	"errors"
+	"sort"
+)
+
+// AnyScope, en lugar de un entorno o un paso, aplica las variables a todos.
+const AnyScope = "*"
+
+// Capas de las variables que el proyecto fija sobre las de la plantilla.
+const (
+	ProjectConfigLayer = "vexconfig.yaml"
+	LocalConfigLayer   = "vexconfig.local.yaml"
+)
+
+// VariableOverridesDefinition son variables que se aplican sobre las de la plantilla,
+// agrupadas por entorno y paso. Source identifica su origen en el plan.
+type VariableOverridesDefinition struct {
+	source string
+	values map[string]map[string]map[string]interface{}
+}
+
+func NewVariableOverridesDefinition(
+	source string, values map[string]map[string]map[string]interface{}) (VariableOverridesDefinition, error) {
+	if source == "" {
+		return VariableOverridesDefinition{}, errors.New("el origen de las variables no puede estar vacío")
+	}
+	return VariableOverridesDefinition{source: source, values: values}, nil
+}
+
+func (o VariableOverridesDefinition) Source() string {
+	return o.source
+}
+
+// Variables devuelve, ordenadas por nombre, las variables fijadas exactamente para un
+// entorno y un paso, que pueden ser AnyScope.
+func (o VariableOverridesDefinition) Variables(env, step string) ([]VariableDefinition, error) {
+	scoped := o.values[env][step]
+	names := make([]string, 0, len(scoped))
+	for name := range scoped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	variables := make([]VariableDefinition, 0, len(names))
+	for _, name := range names {
+		variable, err := NewVariableDefinition(name, scoped[name])
+		if err != nil {
+			return nil, err
+		}
+		variables = append(variables, variable.FromLayer(o.source))
+	}
+	return variables, nil
+}
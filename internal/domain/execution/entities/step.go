@@ -11,6 +11,9 @@ type Step struct {
 	workspaceShared string
 	name            string
 	commands        []vos.Command
+	onFailure       []vos.Command
+	finally         []vos.Command
+	rollback        []vos.Command
 	variables       vos.VariableSet
 }
 
@@ -42,6 +45,24 @@ func WithCommands(commands []vos.Command) StepOption {
 	}
 }
 
+func WithOnFailureCommands(commands []vos.Command) StepOption {
+	return func(s *Step) {
+		s.onFailure = commands
+	}
+}
+
+func WithFinallyCommands(commands []vos.Command) StepOption {
+	return func(s *Step) {
+		s.finally = commands
+	}
+}
+
+func WithRollbackCommands(commands []vos.Command) StepOption {
+	return func(s *Step) {
+		s.rollback = commands
+	}
+}
+
 func WithVariables(variables vos.VariableSet) StepOption {
 	return func(s *Step) {
 		s.variables = variables
@@ -78,6 +99,37 @@ func (sd Step) Commands() []vos.Command {
 	return commandsCopy
 }
 
+func (sd Step) OnFailureCommands() []vos.Command {
+	commandsCopy := make([]vos.Command, len(sd.onFailure))
+	copy(commandsCopy, sd.onFailure)
+	return commandsCopy
+}
+
+func (sd Step) FinallyCommands() []vos.Command {
+	commandsCopy := make([]vos.Command, len(sd.finally))
+	copy(commandsCopy, sd.finally)
+	return commandsCopy
+}
+
+func (sd Step) RollbackCommands() []vos.Command {
+	commandsCopy := make([]vos.Command, len(sd.rollback))
+	copy(commandsCopy, sd.rollback)
+	return commandsCopy
+}
+
+// UsesSharedWorkdir indica si algún comando del paso, incluidos los hooks y el
+// rollback, se ejecuta en el workdir compartido entre ambientes.
+func (sd Step) UsesSharedWorkdir() bool {
+	for _, commands := range [][]vos.Command{sd.commands, sd.onFailure, sd.finally, sd.rollback} {
+		for _, command := range commands {
+			if command.IsShared() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (sd Step) Variables() vos.VariableSet {
 	variablesCopy := make(vos.VariableSet, len(sd.variables))
 	for k, v := range sd.variables {
@@ -10,4 +10,7 @@ import (
 // StepExecutor define la interfaz para ejecutar un único paso de un plan de ejecución.
 type StepExecutor interface {
 	Execute(ctx context.Context, step *entities.Step, initialVars vos.VariableSet) (*vos.ExecutionResult, error)
+
+	// Rollback ejecuta los comandos de compensación de un paso ya completado.
+	Rollback(ctx context.Context, step *entities.Step, vars vos.VariableSet) (*vos.ExecutionResult, error)
 }
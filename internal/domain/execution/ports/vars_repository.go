@@ -5,4 +5,15 @@ import "github.com/jairoprogramador/vex/internal/domain/execution/vos"
 type VarsRepository interface {
 	Get(filePath string) (vos.VariableSet, error)
 	Save(filePath string, generatedVars vos.VariableSet) error
+	// Delete borra un archivo de variables. Si no existe, no hace nada.
+	Delete(filePath string) error
+	// Update aplica update a las variables de un archivo y guarda el resultado, o
+	// borra el archivo si no queda ninguna, sin que otra escritura se intercale.
+	Update(filePath string, update func(vos.VariableSet) (vos.VariableSet, error)) error
+	// List devuelve las rutas de los archivos de variables de un directorio. Si el
+	// directorio no existe, devuelve una lista vacía.
+	List(dirPath string) ([]string, error)
+	// Migrate convierte un archivo de variables guardado en un formato anterior al
+	// actual. Indica si hizo falta convertirlo.
+	Migrate(filePath string) (bool, error)
 }
@@ -38,8 +38,7 @@ func (ce *CommandExecutor) Execute(
 	workspaceStep, workspaceShared string) *vos.ExecutionResult {
 
 	workspaceMain := workspaceStep
-	isShared := filepath.Base(command.Workdir()) == vos.SharedScope
-	if isShared {
+	if command.IsShared() {
 		workspaceMain = workspaceShared
 	}
 
@@ -97,7 +96,7 @@ func (ce *CommandExecutor) Execute(
 	outputVars := vos.NewVariableSet()
 	if len(extractedVars) > 0 {
 		for name, value := range extractedVars {
-			outputVar, err := vos.NewOutputVar(name, value.Value(), isShared)
+			outputVar, err := vos.NewOutputVar(name, value.Value(), command.IsShared())
 			if err != nil {
 				return &vos.ExecutionResult{
 					Status: vos.Failure,
@@ -11,8 +11,9 @@ import (
 
 var (
 	defaultInterpolator ports.Interpolator = &Interpolator{}
-	// Regex para encontrar placeholders como ${var.nombre_de_variable}
-	varRegex = regexp.MustCompile(`\$\{var\.([a-zA-Z0-9_]+)\}`)
+	// Regex para encontrar placeholders como ${var.nombre_de_variable} o, para las
+	// salidas de un paso concreto, ${step.nombre_del_paso.nombre_de_variable}
+	varRegex = regexp.MustCompile(`\$\{(?:var\.([a-zA-Z0-9_]+)|step\.([a-zA-Z0-9_-]+)\.([a-zA-Z0-9_]+))\}`)
 )
 
 type Interpolator struct{}
@@ -33,6 +34,9 @@ func (i *Interpolator) Interpolate(input string, vars vos.VariableSet) (string,
 			return placeholder
 		}
 		varName := matches[1]
+		if varName == "" {
+			varName = vos.StepOutputName(matches[2], matches[3])
+		}
 
 		val, exists := vars.Get(varName)
 		if !exists {
@@ -50,7 +54,7 @@ func (i *Interpolator) Interpolate(input string, vars vos.VariableSet) (string,
 		return "", firstError
 	}
 
-	if strings.Contains(result, "${var.") {
+	if strings.Contains(result, "${var.") || strings.Contains(result, "${step.") {
 		return "", fmt.Errorf("interpolación incompleta, es posible que haya placeholders mal formados. Resultado: %s", result)
 	}
 
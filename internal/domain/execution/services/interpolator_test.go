@@ -76,6 +76,24 @@ func TestInterpolator_Interpolate(t *testing.T) {
 			expectedOutput: "Hola, te despides con Adiós",
 			expectError:    false,
 		},
+		{
+			name:  "Salida de un Paso",
+			input: "curl ${step.supply.url} && curl ${var.url}",
+			vars: func() vos.VariableSet {
+				outputs := newVarsFromMap(map[string]string{"url": "https://supply"})
+				vars := newVarsFromMap(map[string]string{"url": "https://deploy"})
+				vars.AddAll(outputs.Namespaced("supply"))
+				return vars
+			}(),
+			expectedOutput: "curl https://supply && curl https://deploy",
+			expectError:    false,
+		},
+		{
+			name:        "Salida de un Paso Faltante",
+			input:       "curl ${step.deploy.url}",
+			vars:        newVarsFromMap(map[string]string{"url": "https://supply"}),
+			expectError: true,
+		},
 		{
 			name:        "Mapa de Variables Vacio",
 			input:       "El valor es ${var.valor}",
@@ -11,16 +11,20 @@ import (
 )
 
 type StepExecutor struct {
-	commandExecutor  ports.CommandExecutor
-	variableResolver ports.VariableResolver
+	newCommandExecutor func() ports.CommandExecutor
+	variableResolver   ports.VariableResolver
 }
 
+// NewStepExecutor crea una nueva instancia de StepExecutor. newCommandExecutor se
+// llama una vez por paso: el ejecutor de comandos guarda el contenido original de
+// las plantillas que interpola y no se comparte entre pasos ni entre ejecuciones
+// en paralelo.
 func NewStepExecutor(
-	commandExecutor ports.CommandExecutor,
+	newCommandExecutor func() ports.CommandExecutor,
 	variableResolver ports.VariableResolver) *StepExecutor {
 	return &StepExecutor{
-		commandExecutor:  commandExecutor,
-		variableResolver: variableResolver,
+		newCommandExecutor: newCommandExecutor,
+		variableResolver:   variableResolver,
 	}
 }
 
@@ -44,20 +48,18 @@ func (se *StepExecutor) Execute(
 	cumulativeVars.AddAll(resolvedStepVars)
 
 	stepWorkdir := step.WorkspaceStep()
-	stepWorkdirVar, _ := vos.NewOutputVar("step_workdir", stepWorkdir, false)
-	cumulativeVars.Add(stepWorkdirVar)
-
 	sharedWorkdir := step.WorkspaceShared()
-	sharedWorkdirVar, _ := vos.NewOutputVar("shared_workdir", sharedWorkdir, false)
-	cumulativeVars.Add(sharedWorkdirVar)
+	cumulativeVars.AddAll(workdirVariables(step))
 
 	var finalError error
 	finalStatus := vos.Success
 
+	commandExecutor := se.newCommandExecutor()
 	outputVars := vos.NewVariableSet()
+	var hookResults []vos.HookResult
 
 	for _, command := range step.Commands() {
-		cmdResult := se.commandExecutor.Execute(ctx, command, cumulativeVars, stepWorkdir, sharedWorkdir)
+		cmdResult := commandExecutor.Execute(ctx, command, cumulativeVars, stepWorkdir, sharedWorkdir)
 
 		if cmdResult.Logs != "" {
 			cumulativeLogs.WriteString(fmt.Sprintf("  - comando: '%s'\n", command.Name()))
@@ -71,17 +73,125 @@ func (se *StepExecutor) Execute(
 				finalError = fmt.Errorf("el comando '%s' falló: %w", command.Name(), cmdResult.Error)
 			}
 			finalStatus = vos.Failure
+
+			failureVars := failureVariables(command.Name(), finalError)
+			hookVars := cumulativeVars.Clone()
+			hookVars.AddAll(failureVars)
+			hookResults = append(hookResults,
+				se.runHook(ctx, commandExecutor, vos.OnFailureHook, step.OnFailureCommands(), hookVars, stepWorkdir, sharedWorkdir)...)
+			cumulativeVars.AddAll(failureVars)
 			break
 		}
 
-		cumulativeVars.AddAll(cmdResult.OutputVars)
-		outputVars.AddAll(cmdResult.OutputVars)
+		commandOutputs := cmdResult.OutputVars.WithSource(
+			vos.NewVariableSource(vos.CommandSource, step.Name()+"/"+command.Name()))
+		cumulativeVars.AddAll(commandOutputs)
+		cumulativeVars.AddAll(commandOutputs.Namespaced(step.Name()))
+		outputVars.AddAll(commandOutputs)
 	}
 
+	hookResults = append(hookResults,
+		se.runHook(ctx, commandExecutor, vos.FinallyHook, step.FinallyCommands(), cumulativeVars, stepWorkdir, sharedWorkdir)...)
+
 	return &vos.ExecutionResult{
 		Status:     finalStatus,
 		Logs:       cumulativeLogs.String(),
 		OutputVars: outputVars,
+		Variables:  cumulativeVars,
+		Hooks:      hookResults,
 		Error:      finalError,
 	}, nil
 }
+
+// Rollback ejecuta los comandos de compensación de un paso que ya terminó con éxito.
+func (se *StepExecutor) Rollback(
+	ctx context.Context,
+	step *entities.Step,
+	vars vos.VariableSet) (*vos.ExecutionResult, error) {
+
+	rollbackVars := vars.Clone()
+	rollbackVars.AddAll(workdirVariables(step))
+
+	hookResults := se.runHook(ctx, se.newCommandExecutor(), vos.RollbackHook, step.RollbackCommands(), rollbackVars, step.WorkspaceStep(), step.WorkspaceShared())
+
+	cumulativeLogs := &strings.Builder{}
+	finalStatus := vos.Success
+	var finalError error
+	for _, result := range hookResults {
+		if result.Logs != "" {
+			cumulativeLogs.WriteString(fmt.Sprintf("  - comando: '%s'\n", result.Command))
+			cumulativeLogs.WriteString(result.Logs)
+			cumulativeLogs.WriteString("\n")
+		}
+		if result.Status == vos.Failure && finalError == nil {
+			finalStatus = vos.Failure
+			finalError = result.Error
+		}
+	}
+
+	return &vos.ExecutionResult{
+		Status:     finalStatus,
+		Logs:       cumulativeLogs.String(),
+		OutputVars: vos.NewVariableSet(),
+		Hooks:      hookResults,
+		Error:      finalError,
+	}, nil
+}
+
+// runHook ejecuta todos los comandos de un hook. A diferencia de los comandos
+// principales, un fallo no detiene los comandos siguientes: los hooks son
+// tareas de limpieza y cada uno debe tener la oportunidad de ejecutarse.
+func (se *StepExecutor) runHook(
+	ctx context.Context,
+	commandExecutor ports.CommandExecutor,
+	kind vos.HookKind,
+	commands []vos.Command,
+	vars vos.VariableSet,
+	stepWorkdir, sharedWorkdir string) []vos.HookResult {
+
+	results := make([]vos.HookResult, 0, len(commands))
+	for _, command := range commands {
+		cmdResult := commandExecutor.Execute(ctx, command, vars, stepWorkdir, sharedWorkdir)
+
+		result := vos.HookResult{
+			Kind:    kind,
+			Command: command.Name(),
+			Status:  vos.Success,
+			Logs:    cmdResult.Logs,
+		}
+		if cmdResult.Error != nil || cmdResult.Status == vos.Failure {
+			result.Status = vos.Failure
+			result.Error = fmt.Errorf("el hook %s '%s' falló", kind, command.Name())
+			if cmdResult.Error != nil {
+				result.Error = fmt.Errorf("el hook %s '%s' falló: %w", kind, command.Name(), cmdResult.Error)
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// workdirVariables son los directorios de trabajo del paso, disponibles para sus comandos.
+// Un paso sin workdir compartido no define shared_workdir: NewOutputVar rechaza el
+// valor vacío y añadir la variable sin comprobarlo dejaría una variable sin nombre.
+func workdirVariables(step *entities.Step) vos.VariableSet {
+	workdirVars := vos.NewVariableSet()
+	if stepWorkdirVar, err := vos.NewOutputVar("step_workdir", step.WorkspaceStep(), false); err == nil {
+		workdirVars.Add(stepWorkdirVar)
+	}
+	if sharedWorkdirVar, err := vos.NewOutputVar("shared_workdir", step.WorkspaceShared(), false); err == nil {
+		workdirVars.Add(sharedWorkdirVar)
+	}
+	return workdirVars.WithSource(vos.NewVariableSource(vos.RuntimeSource, ""))
+}
+
+func failureVariables(commandName string, cause error) vos.VariableSet {
+	failureVars := vos.NewVariableSet()
+	if failedCommand, err := vos.NewOutputVar(vos.FailedCommandVar, commandName, false); err == nil {
+		failureVars.Add(failedCommand)
+	}
+	if failureReason, err := vos.NewOutputVar(vos.FailureReasonVar, cause.Error(), false); err == nil {
+		failureVars.Add(failureReason)
+	}
+	return failureVars.WithSource(vos.NewVariableSource(vos.RuntimeSource, ""))
+}
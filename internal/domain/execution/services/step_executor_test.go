@@ -55,7 +55,7 @@ func TestStepExecutor_Execute_Success(t *testing.T) {
 	cmdExecutor := new(MockStepCommandExecutor)
 	interpolator := &mockInterpolator{}
 	resolver := services.NewVariableResolver(interpolator)
-	stepExecutor := services.NewStepExecutor(cmdExecutor, resolver)
+	stepExecutor := services.NewStepExecutor(func() ports.CommandExecutor { return cmdExecutor }, resolver)
 
 	varName1, varValue1 := "var1", "val1"
 	varInitName1, varInitValue1 := "init", "true"
@@ -78,7 +78,7 @@ func TestStepExecutor_Execute_Success(t *testing.T) {
 	// Preparamos las variables esperadas para la PRIMERA llamada, incluyendo step_workdir
 	expectedVarsForCmd1 := initialVars.Clone()
 	stepWorkdirVar, _ := vos.NewOutputVar("step_workdir", pathRoot, false)
-	expectedVarsForCmd1.Add(stepWorkdirVar)
+	expectedVarsForCmd1.Add(stepWorkdirVar.WithSource(vos.NewVariableSource(vos.RuntimeSource, "")))
 
 	cmdExecutor.On("Execute", mock.Anything, cmd1, expectedVarsForCmd1, pathRoot).Return(&vos.ExecutionResult{
 		Status:     vos.Success,
@@ -88,7 +88,9 @@ func TestStepExecutor_Execute_Success(t *testing.T) {
 
 	// Preparamos las variables esperadas para la SEGUNDA llamada
 	expectedVarsForCmd2 := expectedVarsForCmd1.Clone()
-	expectedVarsForCmd2.Add(newVar(varName1, varValue1))
+	cmd1Outputs := vos.VariableSet{varName1: newVar(varName1, varValue1).WithSource(vos.NewVariableSource(vos.CommandSource, "test-step/cmd1"))}
+	expectedVarsForCmd2.AddAll(cmd1Outputs)
+	expectedVarsForCmd2.AddAll(cmd1Outputs.Namespaced("test-step"))
 
 	cmdExecutor.On("Execute", mock.Anything, cmd2, expectedVarsForCmd2, pathRoot).Return(&vos.ExecutionResult{
 		Status:     vos.Success,
@@ -118,7 +120,7 @@ func TestStepExecutor_Execute_StopsOnFailure(t *testing.T) {
 	cmdExecutor := new(MockStepCommandExecutor)
 	interpolator := &mockInterpolator{}
 	resolver := services.NewVariableResolver(interpolator)
-	stepExecutor := services.NewStepExecutor(cmdExecutor, resolver)
+	stepExecutor := services.NewStepExecutor(func() ports.CommandExecutor { return cmdExecutor }, resolver)
 
 	cmd1, _ := vos.NewCommand("cmd1", "failing command")
 	cmd2, _ := vos.NewCommand("cmd2", "should not run")
@@ -148,7 +150,7 @@ func TestStepExecutor_Execute_StopsOnIrrecoverableError(t *testing.T) {
 	cmdExecutor := new(MockStepCommandExecutor)
 	interpolator := &mockInterpolator{}
 	resolver := services.NewVariableResolver(interpolator)
-	stepExecutor := services.NewStepExecutor(cmdExecutor, resolver)
+	stepExecutor := services.NewStepExecutor(func() ports.CommandExecutor { return cmdExecutor }, resolver)
 
 	cmd1, _ := vos.NewCommand("cmd1", "failing command")
 	step, _ := entities.NewStep("fail-step", entities.WithCommands([]vos.Command{cmd1}))
@@ -169,6 +171,134 @@ func TestStepExecutor_Execute_StopsOnIrrecoverableError(t *testing.T) {
 	cmdExecutor.AssertExpectations(t)
 }
 
+func TestStepExecutor_Execute_RunsHooksOnFailure(t *testing.T) {
+	cmdExecutor := new(MockStepCommandExecutor)
+	interpolator := &mockInterpolator{}
+	resolver := services.NewVariableResolver(interpolator)
+	stepExecutor := services.NewStepExecutor(func() ports.CommandExecutor { return cmdExecutor }, resolver)
+
+	cmd1, _ := vos.NewCommand("apply", "terraform apply")
+	cmd2, _ := vos.NewCommand("notify", "should not run")
+	unlock, _ := vos.NewCommand("unlock", "terraform force-unlock")
+	cleanup, _ := vos.NewCommand("cleanup", "rm -rf tmp")
+	step, _ := entities.NewStep("supply",
+		entities.WithCommands([]vos.Command{cmd1, cmd2}),
+		entities.WithOnFailureCommands([]vos.Command{unlock}),
+		entities.WithFinallyCommands([]vos.Command{cleanup}),
+	)
+
+	cmdExecutor.On("Execute", mock.Anything, cmd1, mock.Anything, mock.Anything).Return(&vos.ExecutionResult{
+		Status: vos.Failure,
+		Error:  errors.New("lock timeout"),
+	}).Once()
+
+	hasFailureVars := mock.MatchedBy(func(vars vos.VariableSet) bool {
+		failedCommand, ok := vars.Get(vos.FailedCommandVar)
+		if !ok || failedCommand.Value() != "apply" {
+			return false
+		}
+		failureReason, ok := vars.Get(vos.FailureReasonVar)
+		return ok && strings.Contains(failureReason.Value(), "lock timeout")
+	})
+	cmdExecutor.On("Execute", mock.Anything, unlock, hasFailureVars, mock.Anything).Return(&vos.ExecutionResult{
+		Status: vos.Failure,
+		Logs:   "unlock log",
+		Error:  errors.New("no lock"),
+	}).Once()
+	cmdExecutor.On("Execute", mock.Anything, cleanup, hasFailureVars, mock.Anything).Return(&vos.ExecutionResult{
+		Status: vos.Success,
+		Logs:   "cleanup log",
+	}).Once()
+
+	// Act
+	result, err := stepExecutor.Execute(context.Background(), &step, vos.NewVariableSet())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, vos.Failure, result.Status)
+	require.Len(t, result.Hooks, 2)
+	assert.Equal(t, vos.OnFailureHook, result.Hooks[0].Kind)
+	assert.Equal(t, vos.Failure, result.Hooks[0].Status)
+	assert.Error(t, result.Hooks[0].Error)
+	assert.Equal(t, vos.FinallyHook, result.Hooks[1].Kind)
+	assert.Equal(t, "cleanup log", result.Hooks[1].Logs)
+	assert.NotContains(t, result.Logs, "cleanup log", "los logs de los hooks se registran por separado")
+	cmdExecutor.AssertExpectations(t)
+	cmdExecutor.AssertNumberOfCalls(t, "Execute", 3)
+}
+
+func TestStepExecutor_Execute_RunsOnlyFinallyOnSuccess(t *testing.T) {
+	cmdExecutor := new(MockStepCommandExecutor)
+	interpolator := &mockInterpolator{}
+	resolver := services.NewVariableResolver(interpolator)
+	stepExecutor := services.NewStepExecutor(func() ports.CommandExecutor { return cmdExecutor }, resolver)
+
+	cmd1, _ := vos.NewCommand("deploy", "kubectl apply")
+	unlock, _ := vos.NewCommand("unlock", "should not run")
+	cleanup, _ := vos.NewCommand("cleanup", "rm -rf tmp")
+	step, _ := entities.NewStep("deploy",
+		entities.WithCommands([]vos.Command{cmd1}),
+		entities.WithOnFailureCommands([]vos.Command{unlock}),
+		entities.WithFinallyCommands([]vos.Command{cleanup}),
+	)
+
+	cmdExecutor.On("Execute", mock.Anything, cmd1, mock.Anything, mock.Anything).Return(&vos.ExecutionResult{
+		Status:     vos.Success,
+		OutputVars: vos.NewVariableSet(),
+	}).Once()
+	cmdExecutor.On("Execute", mock.Anything, cleanup, mock.Anything, mock.Anything).Return(&vos.ExecutionResult{
+		Status: vos.Success,
+	}).Once()
+
+	// Act
+	result, err := stepExecutor.Execute(context.Background(), &step, vos.NewVariableSet())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, vos.Success, result.Status)
+	require.Len(t, result.Hooks, 1)
+	assert.Equal(t, vos.FinallyHook, result.Hooks[0].Kind)
+	cmdExecutor.AssertExpectations(t)
+}
+
+func TestStepExecutor_Rollback(t *testing.T) {
+	cmdExecutor := new(MockStepCommandExecutor)
+	interpolator := &mockInterpolator{}
+	resolver := services.NewVariableResolver(interpolator)
+	stepExecutor := services.NewStepExecutor(func() ports.CommandExecutor { return cmdExecutor }, resolver)
+
+	apply, _ := vos.NewCommand("apply", "terraform apply")
+	destroyApp, _ := vos.NewCommand("destroy-app", "terraform destroy -target=app")
+	destroyRg, _ := vos.NewCommand("destroy-rg", "az group delete")
+	step, _ := entities.NewStep("supply",
+		entities.WithCommands([]vos.Command{apply}),
+		entities.WithRollbackCommands([]vos.Command{destroyApp, destroyRg}),
+		entities.WithWorkspaceStep("/ws/supply"),
+	)
+
+	rollbackErr := errors.New("app not found")
+	cmdExecutor.On("Execute", mock.Anything, destroyApp, mock.Anything, "/ws/supply").Return(&vos.ExecutionResult{
+		Status: vos.Failure,
+		Error:  rollbackErr,
+	}).Once()
+	cmdExecutor.On("Execute", mock.Anything, destroyRg, mock.Anything, "/ws/supply").Return(&vos.ExecutionResult{
+		Status: vos.Success,
+		Logs:   "deleted",
+	}).Once()
+
+	// Act
+	result, err := stepExecutor.Rollback(context.Background(), &step, vos.NewVariableSet())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, vos.Failure, result.Status)
+	assert.ErrorIs(t, result.Error, rollbackErr)
+	assert.Contains(t, result.Logs, "deleted")
+	require.Len(t, result.Hooks, 2, "todos los comandos de rollback se intentan aunque uno falle")
+	assert.Equal(t, vos.RollbackHook, result.Hooks[1].Kind)
+	cmdExecutor.AssertExpectations(t)
+}
+
 // Helper para crear OutputVar de forma segura en tests
 func newVar(name, value string) vos.OutputVar {
 	v, err := vos.NewOutputVar(name, value, false)
@@ -9,7 +9,7 @@ import (
 	"github.com/jairoprogramador/vex/internal/domain/execution/vos"
 )
 
-var variableInterpolationRegex = regexp.MustCompile(`\$\{var\.`)
+var variableInterpolationRegex = regexp.MustCompile(`\$\{(var|step)\.`)
 
 type VariableResolver struct {
 	interpolator ports.Interpolator
@@ -53,7 +53,7 @@ func (vr *VariableResolver) Resolve(initialVars, varsToResolve vos.VariableSet)
 				continue
 			}
 
-			resolvedVar, _ := vos.NewOutputVar(unresolvedVar.Name(), interpolatedValue, unresolvedVar.IsShared())
+			resolvedVar := unresolvedVar.WithValue(interpolatedValue)
 			finalResolvedSet.Add(resolvedVar)
 			varsForInterpolation.Add(resolvedVar)
 			madeProgress = true
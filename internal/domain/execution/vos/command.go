@@ -2,6 +2,7 @@ package vos
 
 import (
 	"errors"
+	"path/filepath"
 )
 
 type Command struct {
@@ -64,6 +65,11 @@ func (cd Command) Workdir() string {
 	return cd.workdir
 }
 
+// IsShared indica si el comando se ejecuta en el workdir compartido entre ambientes.
+func (cd Command) IsShared() bool {
+	return filepath.Base(cd.workdir) == SharedScope
+}
+
 func (cd Command) TemplateFiles() []string {
 	filesCopy := make([]string, len(cd.templateFiles))
 	copy(filesCopy, cd.templateFiles)
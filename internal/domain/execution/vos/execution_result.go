@@ -9,8 +9,12 @@ const (
 )
 
 type ExecutionResult struct {
-	Status        StepStatus
-	Logs          string
-	OutputVars    VariableSet
-	Error         error
+	Status     StepStatus
+	Logs       string
+	OutputVars VariableSet
+	// Variables son las variables con las que se ejecutaron los comandos del paso,
+	// con su origen.
+	Variables VariableSet
+	Hooks      []HookResult
+	Error      error
 }
@@ -0,0 +1,30 @@
+package vos
+
+// HookKind identifica el momento en que se ejecuta un hook de un paso.
+type HookKind string
+
+const (
+	// OnFailureHook se ejecuta únicamente cuando un comando del paso falla.
+	OnFailureHook HookKind = "on_failure"
+	// FinallyHook se ejecuta siempre al terminar el paso, haya fallado o no.
+	FinallyHook HookKind = "finally"
+	// RollbackHook compensa un paso completado cuando un paso posterior falla.
+	RollbackHook HookKind = "rollback"
+)
+
+const (
+	// FailedCommandVar es la variable que expone a los hooks el nombre del comando que falló.
+	FailedCommandVar = "failed_command"
+	// FailureReasonVar es la variable que expone a los hooks el motivo del fallo.
+	FailureReasonVar = "failure_reason"
+)
+
+// HookResult es el resultado de ejecutar un comando de un hook.
+// Se registra por separado del resultado de los comandos principales del paso.
+type HookResult struct {
+	Kind    HookKind
+	Command string
+	Status  StepStatus
+	Logs    string
+	Error   error
+}
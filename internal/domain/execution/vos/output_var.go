@@ -12,6 +12,10 @@ type OutputVar struct {
 	name     string
 	value    string
 	isShared bool
+	// source es quién fijó el valor y overridden, el valor que había antes y que
+	// este sobrescribió.
+	source     VariableSource
+	overridden *OutputVar
 }
 
 func NewOutputVar(name, value string, isShared bool) (OutputVar, error) {
@@ -29,6 +33,18 @@ func NewOutputVar(name, value string, isShared bool) (OutputVar, error) {
 	}, nil
 }
 
+// WithSource devuelve una copia de la variable con otro origen.
+func (ve OutputVar) WithSource(source VariableSource) OutputVar {
+	ve.source = source
+	return ve
+}
+
+// WithValue devuelve una copia de la variable con otro valor y el mismo origen.
+func (ve OutputVar) WithValue(value string) OutputVar {
+	ve.value = value
+	return ve
+}
+
 func (ve *OutputVar) IsShared() bool {
 	return ve.isShared
 }
@@ -40,3 +56,32 @@ func (ve *OutputVar) Name() string {
 func (ve *OutputVar) Value() string {
 	return ve.value
 }
+
+func (ve *OutputVar) Source() VariableSource {
+	return ve.source
+}
+
+// Overridden devuelve el valor que esta variable sobrescribió, si lo hubo.
+func (ve *OutputVar) Overridden() (OutputVar, bool) {
+	if ve.overridden == nil {
+		return OutputVar{}, false
+	}
+	return *ve.overridden, true
+}
+
+// Chain devuelve la cadena de resolución de la variable: ella misma seguida de
+// los valores que fue sobrescribiendo, del más reciente al más antiguo.
+func (ve *OutputVar) Chain() []OutputVar {
+	chain := []OutputVar{*ve}
+	for current := ve.overridden; current != nil; current = current.overridden {
+		chain = append(chain, *current)
+	}
+	return chain
+}
+
+// sameAs indica si dos variables tienen el mismo valor y el mismo origen, sin
+// tener en cuenta lo que cada una sobrescribió.
+func (ve *OutputVar) sameAs(other OutputVar) bool {
+	return ve.name == other.name && ve.value == other.value &&
+		ve.isShared == other.isShared && ve.source == other.source
+}
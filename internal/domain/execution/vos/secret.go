@@ -0,0 +1,44 @@
+package vos
+
+import "strings"
+
+// RedactedValue reemplaza el valor de una variable secreta cuando se persiste o se muestra.
+const RedactedValue = "********"
+
+// secretNameMarkers son fragmentos que, presentes en el nombre de una variable,
+// indican que su valor es sensible.
+var secretNameMarkers = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"api_key",
+	"apikey",
+	"private_key",
+	"credential",
+	"auth",
+}
+
+// IsSecretName indica, a partir de su nombre, si una variable contiene un valor sensible.
+func IsSecretName(name string) bool {
+	lowerName := strings.ToLower(name)
+	for _, marker := range secretNameMarkers {
+		if strings.Contains(lowerName, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redacted devuelve las variables como mapa, con el valor de las secretas reemplazado.
+func (vs VariableSet) Redacted() map[string]string {
+	m := make(map[string]string, len(vs))
+	for k, v := range vs {
+		if IsSecretName(k) {
+			m[k] = RedactedValue
+			continue
+		}
+		m[k] = v.Value()
+	}
+	return m
+}
@@ -26,22 +26,61 @@ func (vs VariableSet) Clone() VariableSet {
 	return clone
 }
 
+// Add guarda la variable. Si ya había otra con el mismo nombre, la nueva la
+// sobrescribe y la recuerda en su cadena de resolución; volver a añadir la misma
+// variable, con el mismo valor y origen, no cambia nada.
 func (vs VariableSet) Add(outputVar OutputVar) {
+	if previous, exists := vs[outputVar.Name()]; exists {
+		if previous.sameAs(outputVar) {
+			return
+		}
+		outputVar.overridden = &previous
+	}
 	vs[outputVar.Name()] = outputVar
 }
 
 func (vs VariableSet) AddAll(other VariableSet) {
-	for k, v := range other {
-		vs[k] = v
+	for _, v := range other {
+		vs.Add(v)
+	}
+}
+
+// StepOutputName es el nombre con el que la salida de un paso se puede referenciar
+// sin ambigüedad: ${step.<paso>.<variable>}.
+func StepOutputName(stepName, name string) string {
+	return "step." + stepName + "." + name
+}
+
+// Namespaced devuelve una copia de las variables renombradas como salidas del paso
+// stepName, p. ej. 'url' pasa a ser 'step.supply.url'.
+func (vs VariableSet) Namespaced(stepName string) VariableSet {
+	namespaced := make(VariableSet, len(vs))
+	for _, v := range vs {
+		v.name = StepOutputName(stepName, v.name)
+		v.overridden = nil
+		namespaced[v.name] = v
+	}
+	return namespaced
+}
+
+// WithSource devuelve una copia del conjunto con todas las variables atribuidas a source.
+func (vs VariableSet) WithSource(source VariableSource) VariableSet {
+	sourced := make(VariableSet, len(vs))
+	for k, v := range vs {
+		sourced[k] = v.WithSource(source)
 	}
+	return sourced
 }
 
+// Equals compara los nombres, valores y alcance de las variables, sin tener en
+// cuenta su origen.
 func (vs VariableSet) Equals(other VariableSet) bool {
 	if len(vs) != len(other) {
 		return false
 	}
 	for k, v := range vs {
-		if other[k] != v {
+		otherVar, exists := other[k]
+		if !exists || otherVar.Name() != v.Name() || otherVar.Value() != v.Value() || otherVar.IsShared() != v.IsShared() {
 			return false
 		}
 	}
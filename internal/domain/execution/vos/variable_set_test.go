@@ -0,0 +1,53 @@
+package vos_test
+
+import (
+	"testing"
+
+	"github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sourcedVar(t *testing.T, name, value string, kind vos.SourceKind, origin string) vos.OutputVar {
+	t.Helper()
+	v, err := vos.NewOutputVar(name, value, false)
+	require.NoError(t, err)
+	return v.WithSource(vos.NewVariableSource(kind, origin))
+}
+
+func TestVariableSet_Add(t *testing.T) {
+	t.Run("should remember the values a variable overrides", func(t *testing.T) {
+		vars := vos.NewVariableSet()
+		vars.Add(sourcedVar(t, "db_host", "localhost", vos.DefinitionSource, "base: variables/deploy.yaml"))
+		vars.Add(sourcedVar(t, "db_host", "db.internal", vos.CommandSource, "supply/terraform"))
+		vars.Add(sourcedVar(t, "db_host", "db.override", vos.CommandLineSource, "--var"))
+
+		dbHost, _ := vars.Get("db_host")
+		chain := dbHost.Chain()
+		require.Len(t, chain, 3)
+		assert.Equal(t, "db.override", chain[0].Value())
+		assert.Equal(t, "línea de comandos (--var)", chain[0].Source().String())
+		assert.Equal(t, "comando (supply/terraform)", chain[1].Source().String())
+		assert.Equal(t, "localhost", chain[2].Value())
+	})
+
+	t.Run("should not grow the chain when the same variable is added again", func(t *testing.T) {
+		override := sourcedVar(t, "replicas", "3", vos.CommandLineSource, "--var")
+		vars := vos.NewVariableSet()
+		vars.Add(sourcedVar(t, "replicas", "1", vos.StoredSource, "sand/deploy"))
+		vars.Add(override)
+		vars.Add(override)
+
+		replicas, _ := vars.Get("replicas")
+		assert.Len(t, replicas.Chain(), 2)
+	})
+}
+
+func TestVariableSet_Equals(t *testing.T) {
+	t.Run("should ignore the source of the variables", func(t *testing.T) {
+		stored := vos.VariableSet{"url": sourcedVar(t, "url", "https://app", vos.StoredSource, "prod/deploy")}
+		executed := vos.VariableSet{"url": sourcedVar(t, "url", "https://app", vos.CommandSource, "deploy/apply")}
+
+		assert.True(t, stored.Equals(executed))
+	})
+}
@@ -0,0 +1,52 @@
+package vos
+
+// SourceKind clasifica de dónde sale el valor de una variable.
+type SourceKind string
+
+const (
+	// ProjectSource son los datos del proyecto en vexconfig.yaml.
+	ProjectSource SourceKind = "proyecto"
+	// RuntimeSource son las variables que calcula vex en cada ejecución.
+	RuntimeSource SourceKind = "vex"
+	// StoredSource son las salidas guardadas de una ejecución anterior del paso.
+	StoredSource SourceKind = "guardada"
+	// DefinitionSource son las variables de la definición del paso en la plantilla.
+	DefinitionSource SourceKind = "plantilla"
+	// CommandSource son las salidas de un comando del paso.
+	CommandSource SourceKind = "comando"
+	// CommandLineSource son las variables pasadas con --var o --var-file.
+	CommandLineSource SourceKind = "línea de comandos"
+	// RestoredSource son las variables restauradas desde la ejecución que se revierte.
+	RestoredSource SourceKind = "restaurada"
+	// PromotedSource son las salidas reutilizadas del entorno de origen de una promoción.
+	PromotedSource SourceKind = "promovida"
+)
+
+// VariableSource identifica quién fijó el valor de una variable: el tipo de origen y,
+// cuando se conoce, el archivo, paso, comando o flag concreto.
+type VariableSource struct {
+	kind   SourceKind
+	origin string
+}
+
+func NewVariableSource(kind SourceKind, origin string) VariableSource {
+	return VariableSource{kind: kind, origin: origin}
+}
+
+func (s VariableSource) Kind() SourceKind {
+	return s.kind
+}
+
+func (s VariableSource) Origin() string {
+	return s.origin
+}
+
+func (s VariableSource) String() string {
+	if s.kind == "" {
+		return "desconocido"
+	}
+	if s.origin == "" {
+		return string(s.kind)
+	}
+	return string(s.kind) + " (" + s.origin + ")"
+}
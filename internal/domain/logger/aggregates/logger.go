@@ -123,7 +123,7 @@ func (e *Logger) RecalculateStatus() {
 			hasFailure = true
 			break
 		}
-		if stepStatus != vos.Success {
+		if stepStatus != vos.Success && stepStatus != vos.Cached && stepStatus != vos.Skipped {
 			allFinished = false
 		}
 	}
@@ -16,6 +16,7 @@ type StepRecord struct {
 	endTime   time.Time
 	reason    string
 	tasks     []*TaskRecord
+	hooks     []*TaskRecord
 	err       error
 }
 
@@ -42,6 +43,7 @@ func HydrateStepRecord(
 	endTime time.Time,
 	reason string,
 	tasks []*TaskRecord,
+	hooks []*TaskRecord,
 	StepErr error) (*StepRecord, error) {
 
 	id, err := uuid.NewRandom()
@@ -56,6 +58,7 @@ func HydrateStepRecord(
 		endTime:   endTime,
 		reason:    reason,
 		tasks:     tasks,
+		hooks:     hooks,
 		err:       StepErr,
 	}, nil
 }
@@ -125,6 +128,16 @@ func (s *StepRecord) Tasks() []*TaskRecord {
 	return s.tasks
 }
 
+// AddHook registra un comando de un hook del paso. Los hooks se guardan aparte de
+// las tareas y no cambian el estado del paso.
+func (s *StepRecord) AddHook(hook *TaskRecord) {
+	s.hooks = append(s.hooks, hook)
+}
+
+func (s *StepRecord) Hooks() []*TaskRecord {
+	return s.hooks
+}
+
 func (s *StepRecord) Status() vos.Status {
 	s.recalculateStatus()
 	return s.status
@@ -8,21 +8,47 @@ type Project struct {
 	id               vos.ProjectID
 	data             vos.ProjectData
 	templateRepo     vos.TemplateRepository
+	overlays         []vos.TemplateRepository
+	variables        vos.VariableOverrides
+	localVariables   vos.VariableOverrides
 	projectLocalPath string
 	isIDDirty        bool
 }
 
+type ProjectOption func(*Project)
+
+// WithTemplateOverlays añade plantillas que se aplican, en orden, sobre la plantilla base.
+func WithTemplateOverlays(overlays ...vos.TemplateRepository) ProjectOption {
+	return func(p *Project) {
+		p.overlays = append(p.overlays, overlays...)
+	}
+}
+
+// WithVariables fija las variables de vexconfig.yaml y las de vexconfig.local.yaml,
+// que tienen prioridad sobre las primeras.
+func WithVariables(variables, localVariables vos.VariableOverrides) ProjectOption {
+	return func(p *Project) {
+		p.variables = variables
+		p.localVariables = localVariables
+	}
+}
+
 func NewProject(
 	id vos.ProjectID,
 	data vos.ProjectData,
 	templateRepo vos.TemplateRepository,
-	projectLocalPath string) *Project {
-	return &Project{
+	projectLocalPath string,
+	opts ...ProjectOption) *Project {
+	project := &Project{
 		id:               id,
 		data:             data,
 		templateRepo:     templateRepo,
 		projectLocalPath: projectLocalPath,
 	}
+	for _, opt := range opts {
+		opt(project)
+	}
+	return project
 }
 
 func (p *Project) SyncID() bool {
@@ -50,3 +76,30 @@ func (p *Project) Data() vos.ProjectData {
 func (p *Project) TemplateRepo() vos.TemplateRepository {
 	return p.templateRepo
 }
+
+// Templates devuelve las capas de la plantilla en orden de aplicación: la plantilla
+// base seguida de sus overlays.
+func (p *Project) Templates() []vos.TemplateRepository {
+	templates := make([]vos.TemplateRepository, 0, len(p.overlays)+1)
+	templates = append(templates, p.templateRepo)
+	return append(templates, p.overlays...)
+}
+
+// LocalTemplatePath devuelve la ruta de una capa de la plantilla cuando es un directorio local.
+func (p *Project) LocalTemplatePath(repo vos.TemplateRepository) (string, bool) {
+	if !repo.IsLocal() {
+		return "", false
+	}
+	return repo.LocalPath(p.projectLocalPath), true
+}
+
+// Variables devuelve las variables que vexconfig.yaml fija sobre las de la plantilla.
+func (p *Project) Variables() vos.VariableOverrides {
+	return p.variables
+}
+
+// LocalVariables devuelve las variables de vexconfig.local.yaml, propias de cada
+// desarrollador, que tienen prioridad sobre las de vexconfig.yaml.
+func (p *Project) LocalVariables() vos.VariableOverrides {
+	return p.localVariables
+}
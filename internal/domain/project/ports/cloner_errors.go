@@ -0,0 +1,41 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Causas de fallo al clonar o actualizar un repositorio de plantillas.
+var (
+	ErrTemplateRepositoryNotFound = errors.New("el repositorio de plantillas no existe o no es accesible")
+	ErrTemplateAuthentication     = errors.New("el repositorio de plantillas rechazó las credenciales")
+	ErrTemplateRefNotFound        = errors.New("la referencia no existe en el repositorio de plantillas")
+	ErrTemplatePathNotRepository  = errors.New("la ruta local existe pero no es un repositorio git")
+)
+
+// CloneError describe un fallo al clonar o actualizar un repositorio de plantillas.
+// Kind es una de las causas ErrTemplate*; Cause es el error original, si lo hay.
+type CloneError struct {
+	URL   string
+	Ref   string
+	Kind  error
+	Cause error
+}
+
+func (e *CloneError) Error() string {
+	message := fmt.Sprintf("falló la clonación del repositorio '%s'", e.URL)
+	if e.Ref != "" {
+		message += fmt.Sprintf(" (referencia '%s')", e.Ref)
+	}
+	if e.Kind != nil {
+		message += ": " + e.Kind.Error()
+	}
+	if e.Cause != nil {
+		message += ": " + e.Cause.Error()
+	}
+	return message
+}
+
+func (e *CloneError) Unwrap() []error {
+	return []error{e.Kind, e.Cause}
+}
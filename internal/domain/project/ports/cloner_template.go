@@ -2,12 +2,23 @@ package ports
 
 import "context"
 
-type CommandResultDTO struct {
-	Output   string
-	ExitCode int
-}
-
 type ClonerTemplate interface {
-	EnsureCloned(ctx context.Context, repoURL, ref, localPath string) error
-	Run(ctx context.Context, command string, workDir string) (*CommandResultDTO, error)
+	// EnsureCloned deja en localPath un árbol de trabajo de la plantilla en la referencia
+	// indicada, si aún no existe. Los objetos se guardan en el clon bare de barePath, que
+	// comparten los árboles de trabajo de todas las referencias del repositorio.
+	EnsureCloned(ctx context.Context, repoURL, ref, barePath, localPath string) error
+	// Fetch trae del remoto las ramas y tags del clon local.
+	Fetch(ctx context.Context, localPath string) error
+	// FetchCommit trae del remoto un commit concreto, aunque ya no esté en la punta de
+	// su rama o el clon local sea superficial.
+	FetchCommit(ctx context.Context, localPath, commit string) error
+	// ResolveRef devuelve el commit al que apunta una rama, tag o SHA.
+	// Las ramas se resuelven contra el remoto para no depender de la rama local.
+	ResolveRef(ctx context.Context, localPath, ref string) (string, error)
+	// HasCommit indica si el commit ya está disponible en el clon local.
+	HasCommit(ctx context.Context, localPath, commit string) bool
+	// Checkout deja el árbol de trabajo en el commit indicado, descartando cambios locales.
+	Checkout(ctx context.Context, localPath, commit string) error
+	// CommitRange devuelve el resumen de los commits alcanzables desde toCommit y no desde fromCommit.
+	CommitRange(ctx context.Context, localPath, fromCommit, toCommit string) ([]string, error)
 }
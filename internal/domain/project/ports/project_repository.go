@@ -13,9 +13,24 @@ type ProjectConfigDTO struct {
 	Version      string
 	TemplateURL  string
 	TemplateRef  string
+	// TemplateOverlays son las plantillas que se aplican, en orden, sobre la plantilla base.
+	TemplateOverlays []TemplateConfigDTO
+	// Variables son las variables del proyecto por entorno, paso y nombre.
+	Variables VariablesConfigDTO
+}
+
+// VariablesConfigDTO agrupa variables por entorno, paso y nombre.
+type VariablesConfigDTO map[string]map[string]map[string]interface{}
+
+type TemplateConfigDTO struct {
+	URL string
+	Ref string
 }
 
 type ProjectRepository interface {
 	Load(ctx context.Context, pathFile string) (*ProjectConfigDTO, error)
 	Save(ctx context.Context, pathFile string, data *ProjectConfigDTO) error
+	// LoadVariables lee solo la sección 'variables' de un archivo con el esquema de
+	// vexconfig.yaml. Si el archivo no existe, no devuelve variables.
+	LoadVariables(ctx context.Context, pathFile string) (VariablesConfigDTO, error)
 }
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/jairoprogramador/vex/internal/domain/project/vos"
+)
+
+// TemplateLockRepository persiste los commits bloqueados de los repositorios de plantillas.
+type TemplateLockRepository interface {
+	// Load devuelve los bloqueos del archivo. Si el archivo no existe devuelve una lista vacía.
+	Load(ctx context.Context, pathFile string) ([]vos.TemplateLock, error)
+	Save(ctx context.Context, pathFile string, locks []vos.TemplateLock) error
+}
@@ -0,0 +1,41 @@
+package vos
+
+import "errors"
+
+// TemplateLock fija el commit al que resolvió la referencia de un repositorio de
+// plantillas. Mientras la referencia configurada no cambie, se usa ese commit.
+type TemplateLock struct {
+	url    string
+	ref    string
+	commit string
+}
+
+func NewTemplateLock(url, ref, commit string) (TemplateLock, error) {
+	if url == "" {
+		return TemplateLock{}, errors.New("la URL del bloqueo de plantilla no puede estar vacía")
+	}
+	if ref == "" {
+		return TemplateLock{}, errors.New("la referencia del bloqueo de plantilla no puede estar vacía")
+	}
+	if commit == "" {
+		return TemplateLock{}, errors.New("el commit del bloqueo de plantilla no puede estar vacío")
+	}
+	return TemplateLock{url: url, ref: ref, commit: commit}, nil
+}
+
+func (l TemplateLock) URL() string {
+	return l.url
+}
+
+func (l TemplateLock) Ref() string {
+	return l.ref
+}
+
+func (l TemplateLock) Commit() string {
+	return l.commit
+}
+
+// Matches indica si el bloqueo corresponde al repositorio y la referencia configurados.
+func (l TemplateLock) Matches(repo TemplateRepository) bool {
+	return l.url == repo.URL() && l.ref == repo.Ref()
+}
@@ -1,15 +1,26 @@
 package vos
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"net/url"
 	"path/filepath"
 	"strings"
 )
 
+const fileScheme = "file"
+
+// cacheKeyHashLength es la cantidad de caracteres del hash que distingue dos plantillas
+// con el mismo nombre en la caché local.
+const cacheKeyHashLength = 12
+
+// TemplateRepository es el origen de la plantilla del proyecto: un repositorio git
+// remoto (https, ssh) o un directorio local (file:// o una ruta), que se usa tal cual.
 type TemplateRepository struct {
-	url string
-	ref string
+	url     string
+	ref     string
+	isLocal bool
 }
 
 func NewTemplateRepository(repoURL, ref string) (TemplateRepository, error) {
@@ -27,21 +38,36 @@ func NewTemplateRepository(repoURL, ref string) (TemplateRepository, error) {
 		return TemplateRepository{}, errors.New("la URL del repositorio de plantillas no es válida")
 	}
 
-	// Si después de parsear no hay esquema, es probable que sea una ruta local o una URL inválida.
-	if parsedURL.Scheme == "" {
-		return TemplateRepository{}, errors.New("la URL del repositorio debe tener un esquema (ej: https, ssh)")
-	}
+	// Sin esquema, la URL es una ruta local, relativa al directorio del proyecto.
+	isLocal := parsedURL.Scheme == "" || parsedURL.Scheme == fileScheme
 
 	if ref == "" {
 		ref = "main"
 	}
 
 	return TemplateRepository{
-		url: repoURL,
-		ref: ref,
+		url:     repoURL,
+		ref:     ref,
+		isLocal: isLocal,
 	}, nil
 }
 
+// IsLocal indica si la plantilla es un directorio local que no se clona.
+func (t TemplateRepository) IsLocal() bool {
+	return t.isLocal
+}
+
+// LocalPath devuelve la ruta absoluta de una plantilla local. Las rutas relativas
+// se resuelven desde projectPath.
+func (t TemplateRepository) LocalPath(projectPath string) string {
+	path := strings.TrimPrefix(t.url, fileScheme+"://")
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectPath, path)
+	}
+	return filepath.Clean(path)
+}
+
 func (t TemplateRepository) URL() string {
 	return t.url
 }
@@ -51,6 +77,62 @@ func (t TemplateRepository) Ref() string {
 }
 
 func (t TemplateRepository) DirName() string {
-	base := filepath.Base(t.url)
+	base := filepath.Base(filepath.Clean(filepath.FromSlash(t.url)))
+	if base == "." || base == string(filepath.Separator) {
+		return "template"
+	}
 	return strings.TrimSuffix(base, ".git")
 }
+
+// RepositoryKey identifica el repositorio en la caché local: el nombre del directorio
+// seguido de un hash de la URL normalizada, para que dos repositorios con el mismo
+// nombre (github.com/a/deploy y github.com/b/deploy) no compartan clon.
+func (t TemplateRepository) RepositoryKey() string {
+	return t.DirName() + "-" + shortHash(t.normalizedURL())
+}
+
+// CacheKey identifica la plantilla en una referencia concreta. Cada referencia tiene
+// su propio árbol de trabajo y su propio workspace.
+func (t TemplateRepository) CacheKey() string {
+	return t.DirName() + "-" + shortHash(t.normalizedURL()+"@"+t.ref)
+}
+
+// TemplateStackKey identifica una pila de plantillas (la base y sus overlays) en la
+// caché local. Una plantilla sin overlays conserva su CacheKey.
+func TemplateStackKey(templates []TemplateRepository) string {
+	if len(templates) == 1 {
+		return templates[0].CacheKey()
+	}
+	keys := make([]string, 0, len(templates))
+	for _, template := range templates {
+		keys = append(keys, template.CacheKey())
+	}
+	return templates[len(templates)-1].DirName() + "-" + shortHash(strings.Join(keys, "+"))
+}
+
+// normalizedURL reduce las distintas formas de escribir la misma URL a una sola:
+// esquema y host en minúsculas, la forma scp de ssh convertida a ssh:// y sin '.git'
+// ni barras finales.
+func (t TemplateRepository) normalizedURL() string {
+	if t.isLocal {
+		return filepath.ToSlash(filepath.Clean(strings.TrimPrefix(t.url, fileScheme+"://")))
+	}
+
+	raw := t.url
+	if strings.HasPrefix(raw, "git@") {
+		raw = "ssh://" + strings.Replace(raw, ":", "/", 1)
+	}
+	normalized := raw
+	if parsedURL, err := url.Parse(raw); err == nil {
+		parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+		parsedURL.Host = strings.ToLower(parsedURL.Host)
+		normalized = parsedURL.String()
+	}
+	normalized = strings.TrimRight(normalized, "/")
+	return strings.TrimSuffix(normalized, ".git")
+}
+
+func shortHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:cacheKeyHashLength]
+}
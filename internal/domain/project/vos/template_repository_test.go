@@ -1,6 +1,7 @@
 package vos_test
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/jairoprogramador/vex/internal/domain/project/vos"
@@ -49,6 +50,23 @@ func TestNewTemplateRepository(t *testing.T) {
 		assert.Equal(t, "main", repo.Ref())
 	})
 
+	t.Run("should accept file urls and relative paths as local templates", func(t *testing.T) {
+		fileRepo, err := vos.NewTemplateRepository("file:///opt/templates/mydeploy", "")
+		require.NoError(t, err)
+		assert.True(t, fileRepo.IsLocal())
+		assert.Equal(t, filepath.FromSlash("/opt/templates/mydeploy"), fileRepo.LocalPath("/project"))
+
+		relativeRepo, err := vos.NewTemplateRepository("./mydeploy", "")
+		require.NoError(t, err)
+		assert.True(t, relativeRepo.IsLocal())
+		assert.Equal(t, filepath.Join("/project", "mydeploy"), relativeRepo.LocalPath("/project"))
+		assert.Equal(t, "mydeploy", relativeRepo.DirName())
+
+		remoteRepo, err := vos.NewTemplateRepository("https://github.com/user/my-templates.git", "")
+		require.NoError(t, err)
+		assert.False(t, remoteRepo.IsLocal())
+	})
+
 	t.Run("should return an error when repo URL is empty", func(t *testing.T) {
 		// Act
 		_, err := vos.NewTemplateRepository("", "main")
@@ -108,3 +126,38 @@ func TestTemplateRepository_DirName(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateRepository_CacheKey(t *testing.T) {
+	newRepo := func(repoURL, ref string) vos.TemplateRepository {
+		repo, err := vos.NewTemplateRepository(repoURL, ref)
+		require.NoError(t, err)
+		return repo
+	}
+
+	t.Run("should separate repositories with the same name", func(t *testing.T) {
+		a := newRepo("https://github.com/a/deploy.git", "main")
+		b := newRepo("https://github.com/b/deploy.git", "main")
+
+		assert.NotEqual(t, a.RepositoryKey(), b.RepositoryKey())
+		assert.NotEqual(t, a.CacheKey(), b.CacheKey())
+		assert.Contains(t, a.CacheKey(), "deploy-")
+	})
+
+	t.Run("should share the repository but not the cache between refs", func(t *testing.T) {
+		main := newRepo("https://github.com/a/deploy.git", "main")
+		v2 := newRepo("https://github.com/a/deploy.git", "v2")
+
+		assert.Equal(t, main.RepositoryKey(), v2.RepositoryKey())
+		assert.NotEqual(t, main.CacheKey(), v2.CacheKey())
+	})
+
+	t.Run("should normalize equivalent urls", func(t *testing.T) {
+		withSuffix := newRepo("https://GitHub.com/a/deploy.git", "main")
+		withoutSuffix := newRepo("https://github.com/a/deploy/", "main")
+		scp := newRepo("git@github.com:a/deploy.git", "main")
+		ssh := newRepo("ssh://git@github.com/a/deploy", "main")
+
+		assert.Equal(t, withSuffix.CacheKey(), withoutSuffix.CacheKey())
+		assert.Equal(t, scp.CacheKey(), ssh.CacheKey())
+	})
+}
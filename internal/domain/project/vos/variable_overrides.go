@@ -0,0 +1,49 @@
+package vos
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AnyScope, en lugar de un entorno o un paso, aplica las variables a todos.
+const AnyScope = "*"
+
+// VariableOverrides son las variables que el proyecto fija sobre las de la plantilla,
+// agrupadas por entorno y por paso.
+type VariableOverrides struct {
+	values map[string]map[string]map[string]interface{}
+}
+
+func NewVariableOverrides(values map[string]map[string]map[string]interface{}) (VariableOverrides, error) {
+	for env, steps := range values {
+		if env == "" {
+			return VariableOverrides{}, errors.New("el entorno de las variables del proyecto no puede estar vacío")
+		}
+		for step, variables := range steps {
+			if step == "" {
+				return VariableOverrides{}, fmt.Errorf("el paso de las variables del entorno '%s' no puede estar vacío", env)
+			}
+			for name := range variables {
+				if name == "" {
+					return VariableOverrides{}, fmt.Errorf("hay una variable sin nombre en '%s.%s'", env, step)
+				}
+			}
+		}
+	}
+	return VariableOverrides{values: values}, nil
+}
+
+// Scope devuelve las variables fijadas exactamente para un entorno y un paso, que
+// pueden ser AnyScope.
+func (o VariableOverrides) Scope(env, step string) map[string]interface{} {
+	return o.values[env][step]
+}
+
+func (o VariableOverrides) IsEmpty() bool {
+	return len(o.values) == 0
+}
+
+// Values devuelve todas las variables agrupadas por entorno y paso.
+func (o VariableOverrides) Values() map[string]map[string]map[string]interface{} {
+	return o.values
+}
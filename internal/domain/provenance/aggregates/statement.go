@@ -0,0 +1,108 @@
+package aggregates
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jairoprogramador/vex/internal/domain/provenance/vos"
+)
+
+// Statement es la declaración de procedencia de un despliegue: qué se desplegó
+// (sujetos), a partir de qué fuentes (dependencias resueltas), dónde y con qué
+// versión de vex.
+type Statement struct {
+	subjects       []vos.ResourceDescriptor
+	dependencies   []vos.ResourceDescriptor
+	environment    string
+	finalStep      string
+	version        string
+	invocationID   string
+	builderVersion string
+	startedOn      time.Time
+	finishedOn     time.Time
+}
+
+func NewStatement(
+	subjects, dependencies []vos.ResourceDescriptor,
+	environment, finalStep, version, invocationID, builderVersion string,
+	startedOn, finishedOn time.Time) (*Statement, error) {
+
+	if len(subjects) == 0 {
+		return nil, errors.New("la declaración de procedencia debe tener al menos un sujeto")
+	}
+	if environment == "" {
+		return nil, errors.New("el entorno de la declaración de procedencia no puede estar vacío")
+	}
+	if version == "" {
+		return nil, errors.New("la versión de la declaración de procedencia no puede estar vacía")
+	}
+	return &Statement{
+		subjects:       append([]vos.ResourceDescriptor(nil), subjects...),
+		dependencies:   append([]vos.ResourceDescriptor(nil), dependencies...),
+		environment:    environment,
+		finalStep:      finalStep,
+		version:        version,
+		invocationID:   invocationID,
+		builderVersion: builderVersion,
+		startedOn:      startedOn,
+		finishedOn:     finishedOn,
+	}, nil
+}
+
+// Dependency busca una dependencia resuelta por su nombre.
+func (s *Statement) Dependency(name string) (vos.ResourceDescriptor, bool) {
+	for _, dependency := range s.dependencies {
+		if dependency.Name() == name {
+			return dependency, true
+		}
+	}
+	return vos.ResourceDescriptor{}, false
+}
+
+// Instructions devuelve las dependencias que corresponden a las instrucciones de cada paso.
+func (s *Statement) Instructions() []vos.ResourceDescriptor {
+	instructions := make([]vos.ResourceDescriptor, 0, len(s.dependencies))
+	for _, dependency := range s.dependencies {
+		if strings.HasPrefix(dependency.Name(), vos.InstructionDependency) {
+			instructions = append(instructions, dependency)
+		}
+	}
+	return instructions
+}
+
+func (s *Statement) Subjects() []vos.ResourceDescriptor {
+	return append([]vos.ResourceDescriptor(nil), s.subjects...)
+}
+
+func (s *Statement) Dependencies() []vos.ResourceDescriptor {
+	return append([]vos.ResourceDescriptor(nil), s.dependencies...)
+}
+
+func (s *Statement) Environment() string {
+	return s.environment
+}
+
+func (s *Statement) FinalStep() string {
+	return s.finalStep
+}
+
+func (s *Statement) Version() string {
+	return s.version
+}
+
+func (s *Statement) InvocationID() string {
+	return s.invocationID
+}
+
+func (s *Statement) BuilderVersion() string {
+	return s.builderVersion
+}
+
+func (s *Statement) StartedOn() time.Time {
+	return s.startedOn
+}
+
+func (s *Statement) FinishedOn() time.Time {
+	return s.finishedOn
+}
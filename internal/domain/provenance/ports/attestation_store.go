@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"github.com/jairoprogramador/vex/internal/domain/provenance/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/provenance/vos"
+)
+
+// AttestationStore firma, guarda y recupera declaraciones de procedencia.
+type AttestationStore interface {
+	// Save firma la declaración y la escribe en dirPath. Devuelve la ruta del archivo creado.
+	Save(dirPath string, statement *aggregates.Statement) (string, error)
+
+	// Load lee una declaración firmada y comprueba su firma. Una firma que no se puede
+	// comprobar no es un error: se indica en el SignatureCheck devuelto.
+	Load(filePath string) (*aggregates.Statement, vos.SignatureCheck, error)
+
+	// FindLatest devuelve la ruta de la declaración más reciente de dirPath.
+	FindLatest(dirPath string) (string, error)
+}
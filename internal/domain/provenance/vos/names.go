@@ -0,0 +1,23 @@
+package vos
+
+import "strconv"
+
+// Nombres de las dependencias resueltas que vex registra en cada declaración.
+const (
+	SourceDependency      = "source"
+	TemplateDependency    = "template"
+	InstructionDependency = "instructions/"
+)
+
+// TemplateLayerDependency nombra la dependencia de una capa de la plantilla: la base
+// conserva el nombre TemplateDependency y cada overlay se numera a partir de 1.
+func TemplateLayerDependency(index int) string {
+	if index == 0 {
+		return TemplateDependency
+	}
+	return TemplateDependency + "/" + strconv.Itoa(index)
+}
+
+// ArtifactVarPrefix identifica las variables de salida que declaran artefactos.
+// Su valor es la ruta del archivo, relativa al proyecto, o un digest 'sha256:<hex>'.
+const ArtifactVarPrefix = "artifact_"
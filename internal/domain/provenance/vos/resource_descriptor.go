@@ -0,0 +1,50 @@
+package vos
+
+import "errors"
+
+// Algoritmos de digest usados en las declaraciones de procedencia.
+const (
+	SHA256Digest    = "sha256"
+	GitCommitDigest = "gitCommit"
+)
+
+// ResourceDescriptor identifica un recurso (artefacto, repositorio o instrucción)
+// por su nombre, su URI y uno o más digests de su contenido.
+type ResourceDescriptor struct {
+	name   string
+	uri    string
+	digest map[string]string
+}
+
+func NewResourceDescriptor(name, uri string, digest map[string]string) (ResourceDescriptor, error) {
+	if name == "" && uri == "" {
+		return ResourceDescriptor{}, errors.New("el recurso debe tener un nombre o una URI")
+	}
+	if len(digest) == 0 {
+		return ResourceDescriptor{}, errors.New("el recurso debe tener al menos un digest")
+	}
+	digestCopy := make(map[string]string, len(digest))
+	for algorithm, value := range digest {
+		if value == "" {
+			return ResourceDescriptor{}, errors.New("el digest '" + algorithm + "' del recurso no puede estar vacío")
+		}
+		digestCopy[algorithm] = value
+	}
+	return ResourceDescriptor{name: name, uri: uri, digest: digestCopy}, nil
+}
+
+func (r ResourceDescriptor) Name() string {
+	return r.name
+}
+
+func (r ResourceDescriptor) URI() string {
+	return r.uri
+}
+
+func (r ResourceDescriptor) Digest() map[string]string {
+	digestCopy := make(map[string]string, len(r.digest))
+	for algorithm, value := range r.digest {
+		digestCopy[algorithm] = value
+	}
+	return digestCopy
+}
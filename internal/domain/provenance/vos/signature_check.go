@@ -0,0 +1,21 @@
+package vos
+
+// SignatureCheck es el resultado de comprobar la firma de una declaración de procedencia.
+type SignatureCheck struct {
+	valid  bool
+	detail string
+}
+
+func NewSignatureCheck(valid bool, detail string) SignatureCheck {
+	return SignatureCheck{valid: valid, detail: detail}
+}
+
+// IsValid indica si la firma corresponde a la clave con la que se comprobó.
+func (c SignatureCheck) IsValid() bool {
+	return c.valid
+}
+
+// Detail describe la clave con la que se comprobó la firma o por qué no es válida.
+func (c SignatureCheck) Detail() string {
+	return c.detail
+}
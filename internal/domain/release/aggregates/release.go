@@ -0,0 +1,369 @@
+package aggregates
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jairoprogramador/vex/internal/domain/release/entities"
+	"github.com/jairoprogramador/vex/internal/domain/release/vos"
+)
+
+// Release es el manifiesto inmutable de una ejecución del plan en un entorno:
+// qué versión y commit se ejecutaron, con qué plantilla, hasta qué paso, con
+// qué variables, cómo resultó cada paso y cómo terminó.
+type Release struct {
+	id           string
+	environment  string
+	finalStep    string
+	version      string
+	commit       string
+	kind         vos.Kind
+	status       vos.Status
+	rollbackOf   string
+	promotedFrom string
+	startedAt    time.Time
+	finishedAt   time.Time
+	variables    map[string]string
+	overrides    map[string]string
+	errorMessage string
+	template     vos.TemplateSource
+	overlays     []vos.TemplateSource
+	toolVersion  string
+	steps        []*entities.StepRun
+}
+
+type ReleaseOption func(*Release)
+
+func WithTemplateSource(template vos.TemplateSource) ReleaseOption {
+	return func(r *Release) {
+		r.template = template
+	}
+}
+
+// WithTemplateSources registra las capas de la plantilla: la primera es la base y
+// las siguientes, sus overlays.
+func WithTemplateSources(templates ...vos.TemplateSource) ReleaseOption {
+	return func(r *Release) {
+		if len(templates) == 0 {
+			return
+		}
+		r.template = templates[0]
+		r.overlays = append([]vos.TemplateSource(nil), templates[1:]...)
+	}
+}
+
+// WithOverrides registra las variables que se pasaron en la línea de comandos.
+func WithOverrides(overrides map[string]string) ReleaseOption {
+	return func(r *Release) {
+		r.overrides = overrides
+	}
+}
+
+func WithToolVersion(toolVersion string) ReleaseOption {
+	return func(r *Release) {
+		r.toolVersion = toolVersion
+	}
+}
+
+func NewRelease(
+	environment, finalStep, version, commit string, kind vos.Kind, opts ...ReleaseOption) (*Release, error) {
+	if environment == "" {
+		return nil, errors.New("el entorno de la ejecución no puede estar vacío")
+	}
+	if finalStep == "" {
+		return nil, errors.New("el paso final de la ejecución no puede estar vacío")
+	}
+	if version == "" {
+		return nil, errors.New("la versión de la ejecución no puede estar vacía")
+	}
+	if commit == "" {
+		return nil, errors.New("el commit de la ejecución no puede estar vacío")
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo generar el id de la ejecución: %w", err)
+	}
+	release := &Release{
+		id:          id.String(),
+		environment: environment,
+		finalStep:   finalStep,
+		version:     version,
+		commit:      commit,
+		kind:        kind,
+		status:      vos.Running,
+		startedAt:   time.Now().UTC(),
+		variables:   make(map[string]string),
+		steps:       []*entities.StepRun{},
+	}
+	for _, opt := range opts {
+		opt(release)
+	}
+	return release, nil
+}
+
+func HydrateRelease(
+	id, environment, finalStep, version, commit string,
+	kind vos.Kind,
+	status vos.Status,
+	rollbackOf, promotedFrom string,
+	startedAt, finishedAt time.Time,
+	variables, overrides map[string]string,
+	errorMessage string,
+	template vos.TemplateSource,
+	overlays []vos.TemplateSource,
+	toolVersion string,
+	steps []*entities.StepRun) *Release {
+
+	if variables == nil {
+		variables = make(map[string]string)
+	}
+	if steps == nil {
+		steps = []*entities.StepRun{}
+	}
+	return &Release{
+		id:           id,
+		environment:  environment,
+		finalStep:    finalStep,
+		version:      version,
+		commit:       commit,
+		kind:         kind,
+		status:       status,
+		rollbackOf:   rollbackOf,
+		promotedFrom: promotedFrom,
+		startedAt:    startedAt,
+		finishedAt:   finishedAt,
+		variables:    variables,
+		overrides:    overrides,
+		errorMessage: errorMessage,
+		template:     template,
+		overlays:     overlays,
+		toolVersion:  toolVersion,
+		steps:        steps,
+	}
+}
+
+// MarkAsRollbackOf enlaza esta ejecución con la ejecución que se está restaurando.
+func (r *Release) MarkAsRollbackOf(releaseID string) {
+	r.rollbackOf = releaseID
+}
+
+// MarkAsPromotionOf enlaza esta ejecución con el despliegue del entorno de origen que se promueve.
+func (r *Release) MarkAsPromotionOf(releaseID string) {
+	r.promotedFrom = releaseID
+}
+
+// StartStep abre el registro de un paso. El paso anterior debe estar cerrado.
+func (r *Release) StartStep(name string) error {
+	if current := r.currentStep(); current != nil {
+		return fmt.Errorf("el paso '%s' sigue en curso", current.Name())
+	}
+	step, err := entities.NewStepRun(name)
+	if err != nil {
+		return err
+	}
+	r.steps = append(r.steps, step)
+	return nil
+}
+
+// RecordFingerprints asocia al paso en curso las huellas con las que se evaluó.
+func (r *Release) RecordFingerprints(fingerprints vos.StepFingerprints) {
+	if current := r.currentStep(); current != nil {
+		current.SetFingerprints(fingerprints)
+	}
+}
+
+// RecordVariables asocia al paso en curso la cadena de resolución de sus variables.
+func (r *Release) RecordVariables(variables []vos.VariableTrace) {
+	if current := r.currentStep(); current != nil {
+		current.SetVariables(variables)
+	}
+}
+
+// RecordHooks asocia al último registro del paso stepName los hooks que se
+// ejecutaron para él. Los hooks de rollback llegan cuando el paso ya está cerrado.
+func (r *Release) RecordHooks(stepName string, hooks []vos.HookRun) {
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		if r.steps[i].Name() == stepName {
+			r.steps[i].AddHooks(hooks...)
+			return
+		}
+	}
+}
+
+// HasVariableTraces indica si algún paso registró la resolución de sus variables.
+func (r *Release) HasVariableTraces() bool {
+	for _, step := range r.steps {
+		if len(step.Variables()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipStep cierra el paso en curso como omitido porque su estado no cambió.
+func (r *Release) SkipStep() {
+	if current := r.currentStep(); current != nil {
+		current.Finish(vos.StepSkipped, nil)
+	}
+}
+
+// ReuseStep cierra el paso en curso como reutilizado, con las salidas tomadas de otra ejecución.
+func (r *Release) ReuseStep(outputs map[string]string) {
+	if current := r.currentStep(); current != nil {
+		current.Finish(vos.StepReused, outputs)
+	}
+}
+
+// CompleteStep cierra el paso en curso como ejecutado, con sus variables de salida.
+func (r *Release) CompleteStep(outputs map[string]string) {
+	if current := r.currentStep(); current != nil {
+		current.Finish(vos.StepExecuted, outputs)
+	}
+}
+
+func (r *Release) currentStep() *entities.StepRun {
+	if len(r.steps) == 0 {
+		return nil
+	}
+	last := r.steps[len(r.steps)-1]
+	if last.Outcome() != vos.StepRunning {
+		return nil
+	}
+	return last
+}
+
+// Finish cierra el registro con el resultado de la ejecución y las variables vigentes al terminar.
+// Un paso que siga en curso se registra como fallido.
+func (r *Release) Finish(runErr error, variables map[string]string) {
+	if r.status != vos.Running {
+		return
+	}
+	if current := r.currentStep(); current != nil {
+		current.Finish(vos.StepFailed, nil)
+	}
+	r.finishedAt = time.Now().UTC()
+	r.variables = make(map[string]string, len(variables))
+	for name, value := range variables {
+		r.variables[name] = value
+	}
+	if runErr != nil {
+		r.status = vos.Failed
+		r.errorMessage = runErr.Error()
+		return
+	}
+	r.status = vos.Succeeded
+}
+
+// IsSuccessfulDeployment indica si la ejecución llegó con éxito hasta el paso de despliegue.
+func (r *Release) IsSuccessfulDeployment() bool {
+	return r.status == vos.Succeeded && r.finalStep == vos.DeployStep
+}
+
+func (r *Release) ID() string {
+	return r.id
+}
+
+func (r *Release) Environment() string {
+	return r.environment
+}
+
+func (r *Release) FinalStep() string {
+	return r.finalStep
+}
+
+func (r *Release) Version() string {
+	return r.version
+}
+
+func (r *Release) Commit() string {
+	return r.commit
+}
+
+func (r *Release) Kind() vos.Kind {
+	return r.kind
+}
+
+func (r *Release) Status() vos.Status {
+	return r.status
+}
+
+func (r *Release) RollbackOf() string {
+	return r.rollbackOf
+}
+
+func (r *Release) PromotedFrom() string {
+	return r.promotedFrom
+}
+
+// StepOutputs devuelve las salidas registradas de un paso ejecutado o reutilizado.
+func (r *Release) StepOutputs(name string) (map[string]string, bool) {
+	for _, step := range r.steps {
+		if step.Name() != name {
+			continue
+		}
+		if step.Outcome() == vos.StepExecuted || step.Outcome() == vos.StepReused {
+			return step.Outputs(), true
+		}
+	}
+	return nil, false
+}
+
+// HasStep indica si la ejecución llegó a evaluar un paso.
+func (r *Release) HasStep(name string) bool {
+	for _, step := range r.steps {
+		if step.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Release) StartedAt() time.Time {
+	return r.startedAt
+}
+
+func (r *Release) FinishedAt() time.Time {
+	return r.finishedAt
+}
+
+func (r *Release) Variables() map[string]string {
+	variablesCopy := make(map[string]string, len(r.variables))
+	for name, value := range r.variables {
+		variablesCopy[name] = value
+	}
+	return variablesCopy
+}
+
+// Overrides devuelve las variables pasadas en la línea de comandos, con los secretos ocultos.
+func (r *Release) Overrides() map[string]string {
+	overrides := make(map[string]string, len(r.overrides))
+	for name, value := range r.overrides {
+		overrides[name] = value
+	}
+	return overrides
+}
+
+func (r *Release) ErrorMessage() string {
+	return r.errorMessage
+}
+
+func (r *Release) Template() vos.TemplateSource {
+	return r.template
+}
+
+// TemplateOverlays devuelve las capas aplicadas sobre la plantilla base, en orden.
+func (r *Release) TemplateOverlays() []vos.TemplateSource {
+	return append([]vos.TemplateSource(nil), r.overlays...)
+}
+
+func (r *Release) ToolVersion() string {
+	return r.toolVersion
+}
+
+func (r *Release) Steps() []*entities.StepRun {
+	stepsCopy := make([]*entities.StepRun, len(r.steps))
+	copy(stepsCopy, r.steps)
+	return stepsCopy
+}
@@ -0,0 +1,90 @@
+package aggregates_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/release/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelease_RecordsStepsAndOutcome(t *testing.T) {
+	t.Run("should record executed and skipped steps of a successful run", func(t *testing.T) {
+		release, err := aggregates.NewRelease("prod", vos.DeployStep, "v1.0.0", "abc123", vos.Execution,
+			aggregates.WithToolVersion("1.2.3"),
+			aggregates.WithTemplateSource(vos.NewTemplateSource("https://example.com/t.git", "main", "def456")))
+		require.NoError(t, err)
+
+		require.NoError(t, release.StartStep("test"))
+		release.SkipStep()
+		require.NoError(t, release.StartStep("deploy"))
+		release.RecordFingerprints(vos.NewStepFingerprints("c", "i", "v", "prod"))
+		release.CompleteStep(map[string]string{"url": "https://app"})
+		release.Finish(nil, map[string]string{"url": "https://app"})
+
+		assert.True(t, release.IsSuccessfulDeployment())
+		assert.Equal(t, "1.2.3", release.ToolVersion())
+		assert.Equal(t, "def456", release.Template().SHA())
+
+		steps := release.Steps()
+		require.Len(t, steps, 2)
+		assert.Equal(t, vos.StepSkipped, steps[0].Outcome())
+		assert.Equal(t, vos.StepExecuted, steps[1].Outcome())
+		assert.Equal(t, "c", steps[1].Fingerprints().Code())
+		assert.Equal(t, "https://app", steps[1].Outputs()["url"])
+	})
+
+	t.Run("should mark the step in progress as failed when the run fails", func(t *testing.T) {
+		release, err := aggregates.NewRelease("prod", vos.DeployStep, "v1.0.0", "abc123", vos.Execution)
+		require.NoError(t, err)
+
+		require.NoError(t, release.StartStep("deploy"))
+		release.Finish(errors.New("boom"), nil)
+
+		assert.Equal(t, vos.Failed, release.Status())
+		assert.Equal(t, "boom", release.ErrorMessage())
+		assert.False(t, release.IsSuccessfulDeployment())
+		assert.Equal(t, vos.StepFailed, release.Steps()[0].Outcome())
+	})
+
+	t.Run("should not start a step while another is in progress", func(t *testing.T) {
+		release, err := aggregates.NewRelease("prod", "test", "v1.0.0", "abc123", vos.Execution)
+		require.NoError(t, err)
+
+		require.NoError(t, release.StartStep("test"))
+		assert.Error(t, release.StartStep("supply"))
+	})
+
+	t.Run("should record the outputs reused by a promotion", func(t *testing.T) {
+		release, err := aggregates.NewRelease("prod", vos.DeployStep, "v1.0.0", "abc123", vos.Promotion)
+		require.NoError(t, err)
+		release.MarkAsPromotionOf("staging-release")
+
+		require.NoError(t, release.StartStep(vos.PackageStep))
+		release.ReuseStep(map[string]string{"image_tag": "v1.0.0"})
+		require.NoError(t, release.StartStep(vos.DeployStep))
+		release.CompleteStep(nil)
+		release.Finish(nil, nil)
+
+		assert.True(t, release.IsSuccessfulDeployment())
+		assert.Equal(t, "staging-release", release.PromotedFrom())
+		assert.True(t, release.HasStep(vos.PackageStep))
+		outputs, found := release.StepOutputs(vos.PackageStep)
+		require.True(t, found)
+		assert.Equal(t, "v1.0.0", outputs["image_tag"])
+		assert.Equal(t, vos.StepReused, release.Steps()[0].Outcome())
+	})
+
+	t.Run("should keep a copy of the command line overrides", func(t *testing.T) {
+		overrides := map[string]string{"replicas": "3"}
+		release, err := aggregates.NewRelease("prod", vos.DeployStep, "v1.0.0", "abc123", vos.Execution,
+			aggregates.WithOverrides(overrides))
+		require.NoError(t, err)
+
+		release.Overrides()["replicas"] = "5"
+
+		assert.Equal(t, map[string]string{"replicas": "3"}, release.Overrides())
+	})
+}
@@ -0,0 +1,132 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jairoprogramador/vex/internal/domain/release/vos"
+)
+
+// StepRun registra cómo se evaluó y ejecutó un paso dentro de una ejecución.
+type StepRun struct {
+	name         string
+	outcome      vos.StepOutcome
+	startedAt    time.Time
+	finishedAt   time.Time
+	fingerprints vos.StepFingerprints
+	outputs      map[string]string
+	variables    []vos.VariableTrace
+	hooks        []vos.HookRun
+}
+
+func NewStepRun(name string) (*StepRun, error) {
+	if name == "" {
+		return nil, errors.New("el nombre del paso no puede estar vacío")
+	}
+	return &StepRun{
+		name:      name,
+		outcome:   vos.StepRunning,
+		startedAt: time.Now().UTC(),
+		outputs:   make(map[string]string),
+	}, nil
+}
+
+func HydrateStepRun(
+	name string,
+	outcome vos.StepOutcome,
+	startedAt, finishedAt time.Time,
+	fingerprints vos.StepFingerprints,
+	outputs map[string]string,
+	variables []vos.VariableTrace,
+	hooks []vos.HookRun) *StepRun {
+
+	if outputs == nil {
+		outputs = make(map[string]string)
+	}
+	return &StepRun{
+		name:         name,
+		outcome:      outcome,
+		startedAt:    startedAt,
+		finishedAt:   finishedAt,
+		fingerprints: fingerprints,
+		outputs:      outputs,
+		variables:    variables,
+		hooks:        hooks,
+	}
+}
+
+func (s *StepRun) SetFingerprints(fingerprints vos.StepFingerprints) {
+	s.fingerprints = fingerprints
+}
+
+// SetVariables registra las variables con las que se ejecutaron los comandos del paso.
+func (s *StepRun) SetVariables(variables []vos.VariableTrace) {
+	s.variables = append([]vos.VariableTrace(nil), variables...)
+}
+
+// AddHooks registra los hooks que se ejecutaron para el paso. Se pueden añadir
+// después de cerrarlo, como los de rollback.
+func (s *StepRun) AddHooks(hooks ...vos.HookRun) {
+	s.hooks = append(s.hooks, hooks...)
+}
+
+// Finish cierra el paso con su resultado. Un paso ya cerrado no se modifica.
+func (s *StepRun) Finish(outcome vos.StepOutcome, outputs map[string]string) {
+	if s.outcome != vos.StepRunning {
+		return
+	}
+	s.outcome = outcome
+	s.finishedAt = time.Now().UTC()
+	s.outputs = make(map[string]string, len(outputs))
+	for name, value := range outputs {
+		s.outputs[name] = value
+	}
+}
+
+func (s *StepRun) Name() string {
+	return s.name
+}
+
+func (s *StepRun) Outcome() vos.StepOutcome {
+	return s.outcome
+}
+
+func (s *StepRun) StartedAt() time.Time {
+	return s.startedAt
+}
+
+func (s *StepRun) FinishedAt() time.Time {
+	return s.finishedAt
+}
+
+func (s *StepRun) Fingerprints() vos.StepFingerprints {
+	return s.fingerprints
+}
+
+func (s *StepRun) Outputs() map[string]string {
+	outputsCopy := make(map[string]string, len(s.outputs))
+	for name, value := range s.outputs {
+		outputsCopy[name] = value
+	}
+	return outputsCopy
+}
+
+// Variables devuelve la cadena de resolución de cada variable del paso, ordenadas por nombre.
+func (s *StepRun) Variables() []vos.VariableTrace {
+	return append([]vos.VariableTrace(nil), s.variables...)
+}
+
+// Variable busca la cadena de resolución de una variable del paso.
+func (s *StepRun) Variable(name string) (vos.VariableTrace, bool) {
+	for _, variable := range s.variables {
+		if variable.Name() == name {
+			return variable, true
+		}
+	}
+	return vos.VariableTrace{}, false
+}
+
+// Hooks devuelve los hooks del paso en el orden en que se ejecutaron.
+func (s *StepRun) Hooks() []vos.HookRun {
+	return append([]vos.HookRun(nil), s.hooks...)
+}
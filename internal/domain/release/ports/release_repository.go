@@ -0,0 +1,12 @@
+package ports
+
+import "github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+
+// ReleaseRepository persiste el historial de ejecuciones de un entorno.
+type ReleaseRepository interface {
+	// Save guarda una ejecución terminada. Los registros son inmutables.
+	Save(dirPath string, release *aggregates.Release) error
+	// FindAll devuelve las ejecuciones registradas, de la más antigua a la más reciente.
+	// Los registros que no se pueden leer se omiten y se devuelve un error por cada uno.
+	FindAll(dirPath string) ([]*aggregates.Release, []error, error)
+}
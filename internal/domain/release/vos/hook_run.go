@@ -0,0 +1,31 @@
+package vos
+
+// HookRun registra la ejecución de un comando de un hook de un paso: on_failure,
+// finally o rollback.
+type HookRun struct {
+	kind      string
+	command   string
+	succeeded bool
+	errorMsg  string
+}
+
+func NewHookRun(kind, command string, succeeded bool, errorMsg string) HookRun {
+	return HookRun{kind: kind, command: command, succeeded: succeeded, errorMsg: errorMsg}
+}
+
+func (h HookRun) Kind() string {
+	return h.kind
+}
+
+func (h HookRun) Command() string {
+	return h.command
+}
+
+func (h HookRun) Succeeded() bool {
+	return h.succeeded
+}
+
+// ErrorMessage explica por qué falló el hook. Vacío si terminó bien.
+func (h HookRun) ErrorMessage() string {
+	return h.errorMsg
+}
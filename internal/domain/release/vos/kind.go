@@ -0,0 +1,29 @@
+package vos
+
+import "fmt"
+
+// Kind indica qué originó una ejecución registrada.
+type Kind string
+
+const (
+	// Execution es una ejecución normal del plan (`vex <paso> <entorno>`).
+	Execution Kind = "execution"
+	// Rollback es una re-ejecución del plan de despliegue sobre una versión anterior.
+	Rollback Kind = "rollback"
+	// Promotion despliega en un entorno la versión ya desplegada en el entorno anterior
+	// de la cadena de promoción, reutilizando su empaquetado.
+	Promotion Kind = "promotion"
+)
+
+func NewKind(value string) (Kind, error) {
+	switch Kind(value) {
+	case Execution, Rollback, Promotion:
+		return Kind(value), nil
+	default:
+		return "", fmt.Errorf("tipo de ejecución inválido: %s", value)
+	}
+}
+
+func (k Kind) String() string {
+	return string(k)
+}
@@ -0,0 +1,31 @@
+package vos
+
+import "fmt"
+
+// Status es el resultado final de una ejecución registrada.
+type Status string
+
+const (
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+)
+
+// DeployStep es el paso cuya ejecución exitosa convierte una ejecución en un despliegue.
+const DeployStep = "deploy"
+
+// PackageStep es el paso que construye los artefactos que una promoción reutiliza.
+const PackageStep = "package"
+
+func NewStatus(value string) (Status, error) {
+	switch Status(value) {
+	case Running, Succeeded, Failed:
+		return Status(value), nil
+	default:
+		return "", fmt.Errorf("estado de ejecución inválido: %s", value)
+	}
+}
+
+func (s Status) String() string {
+	return string(s)
+}
@@ -0,0 +1,35 @@
+package vos
+
+// StepFingerprints son las huellas con las que se evaluó un paso: código del
+// proyecto, instrucciones y variables de la plantilla, y entorno.
+type StepFingerprints struct {
+	code        string
+	instruction string
+	vars        string
+	environment string
+}
+
+func NewStepFingerprints(code, instruction, vars, environment string) StepFingerprints {
+	return StepFingerprints{
+		code:        code,
+		instruction: instruction,
+		vars:        vars,
+		environment: environment,
+	}
+}
+
+func (f StepFingerprints) Code() string {
+	return f.code
+}
+
+func (f StepFingerprints) Instruction() string {
+	return f.instruction
+}
+
+func (f StepFingerprints) Vars() string {
+	return f.vars
+}
+
+func (f StepFingerprints) Environment() string {
+	return f.environment
+}
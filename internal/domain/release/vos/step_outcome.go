@@ -0,0 +1,29 @@
+package vos
+
+import "fmt"
+
+// StepOutcome es el resultado de un paso dentro de una ejecución registrada.
+type StepOutcome string
+
+const (
+	StepRunning  StepOutcome = "running"
+	StepExecuted StepOutcome = "executed"
+	StepSkipped  StepOutcome = "skipped"
+	StepFailed   StepOutcome = "failed"
+	// StepReused indica que el paso no se ejecutó y se tomaron sus salidas de otra
+	// ejecución, como en una promoción.
+	StepReused StepOutcome = "reused"
+)
+
+func NewStepOutcome(value string) (StepOutcome, error) {
+	switch StepOutcome(value) {
+	case StepRunning, StepExecuted, StepSkipped, StepFailed, StepReused:
+		return StepOutcome(value), nil
+	default:
+		return "", fmt.Errorf("resultado de paso inválido: %s", value)
+	}
+}
+
+func (o StepOutcome) String() string {
+	return string(o)
+}
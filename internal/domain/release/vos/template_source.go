@@ -0,0 +1,25 @@
+package vos
+
+// TemplateSource identifica la plantilla usada en una ejecución: el repositorio,
+// la referencia configurada y el commit al que resolvió.
+type TemplateSource struct {
+	url string
+	ref string
+	sha string
+}
+
+func NewTemplateSource(url, ref, sha string) TemplateSource {
+	return TemplateSource{url: url, ref: ref, sha: sha}
+}
+
+func (t TemplateSource) URL() string {
+	return t.url
+}
+
+func (t TemplateSource) Ref() string {
+	return t.ref
+}
+
+func (t TemplateSource) SHA() string {
+	return t.sha
+}
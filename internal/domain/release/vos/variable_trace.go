@@ -0,0 +1,62 @@
+package vos
+
+// VariableOrigin es uno de los valores que tomó una variable y quién lo fijó.
+type VariableOrigin struct {
+	value  string
+	source string
+}
+
+func NewVariableOrigin(value, source string) VariableOrigin {
+	return VariableOrigin{value: value, source: source}
+}
+
+func (o VariableOrigin) Value() string {
+	return o.value
+}
+
+func (o VariableOrigin) Source() string {
+	return o.source
+}
+
+// VariableTrace es la cadena de resolución de una variable en un paso: el valor
+// vigente seguido de los valores que fue sobrescribiendo, del más reciente al más antiguo.
+type VariableTrace struct {
+	name  string
+	chain []VariableOrigin
+}
+
+func NewVariableTrace(name string, chain []VariableOrigin) VariableTrace {
+	return VariableTrace{name: name, chain: append([]VariableOrigin(nil), chain...)}
+}
+
+func (t VariableTrace) Name() string {
+	return t.name
+}
+
+// Value devuelve el valor vigente de la variable.
+func (t VariableTrace) Value() string {
+	if len(t.chain) == 0 {
+		return ""
+	}
+	return t.chain[0].value
+}
+
+// Source devuelve quién fijó el valor vigente.
+func (t VariableTrace) Source() string {
+	if len(t.chain) == 0 {
+		return ""
+	}
+	return t.chain[0].source
+}
+
+// Overridden devuelve los valores sobrescritos, del más reciente al más antiguo.
+func (t VariableTrace) Overridden() []VariableOrigin {
+	if len(t.chain) < 2 {
+		return nil
+	}
+	return append([]VariableOrigin(nil), t.chain[1:]...)
+}
+
+func (t VariableTrace) Chain() []VariableOrigin {
+	return append([]VariableOrigin(nil), t.chain...)
+}
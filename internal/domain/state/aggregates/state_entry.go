@@ -12,16 +12,35 @@ type StateEntry struct {
 	environment vos.Environment
 	vars        vos.Fingerprint
 	createdAt   time.Time
+	// codeManifest y varsManifest permiten explicar qué archivos y variables
+	// cambiaron respecto a esta entrada. Las entradas antiguas no los tienen.
+	codeManifest vos.Manifest
+	varsManifest vos.Manifest
 }
 
-func NewStateEntry(code, instruction, vars vos.Fingerprint, environment vos.Environment) *StateEntry {
-	return &StateEntry{
+type StateEntryOption func(*StateEntry)
+
+// WithManifests guarda en la entrada el detalle de los fingerprints de código y de variables.
+func WithManifests(code, vars vos.Manifest) StateEntryOption {
+	return func(se *StateEntry) {
+		se.codeManifest = code
+		se.varsManifest = vars
+	}
+}
+
+func NewStateEntry(
+	code, instruction, vars vos.Fingerprint, environment vos.Environment, opts ...StateEntryOption) *StateEntry {
+	entry := &StateEntry{
 		code:        code,
 		instruction: instruction,
 		environment: environment,
 		vars:        vars,
 		createdAt:   time.Now().UTC(),
 	}
+	for _, opt := range opts {
+		opt(entry)
+	}
+	return entry
 }
 
 func (se StateEntry) Code() vos.Fingerprint {
@@ -40,6 +59,14 @@ func (se StateEntry) Vars() vos.Fingerprint {
 	return se.vars
 }
 
+func (se StateEntry) CodeManifest() vos.Manifest {
+	return se.codeManifest
+}
+
+func (se StateEntry) VarsManifest() vos.Manifest {
+	return se.varsManifest
+}
+
 func (se StateEntry) CreatedAt() time.Time {
 	return se.createdAt
 }
@@ -48,6 +75,24 @@ func (se *StateEntry) SetCreatedAt(t time.Time) {
 	se.createdAt = t
 }
 
+// ChangedFingerprints devuelve las huellas de la entrada que no coinciden con las actuales.
+func (se StateEntry) ChangedFingerprints(current vos.CurrentStateFingerprints) []vos.FingerprintKind {
+	var changed []vos.FingerprintKind
+	if !se.code.Equals(current.Code()) {
+		changed = append(changed, vos.CodeFingerprint)
+	}
+	if !se.instruction.Equals(current.Instruction()) {
+		changed = append(changed, vos.InstructionFingerprint)
+	}
+	if !se.vars.Equals(current.Vars()) {
+		changed = append(changed, vos.VarsFingerprint)
+	}
+	if !se.environment.Equals(current.Environment()) {
+		changed = append(changed, vos.EnvironmentFingerprint)
+	}
+	return changed
+}
+
 func (se StateEntry) Equals(other StateEntry) bool {
 	return se.code.Equals(other.code) &&
 		se.instruction.Equals(other.instruction) &&
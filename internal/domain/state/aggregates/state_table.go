@@ -2,6 +2,7 @@ package aggregates
 
 import (
 	"sort"
+	"time"
 )
 
 const maxEntries = 5
@@ -52,3 +53,48 @@ func (st *StateTable) AddEntry(newEntry *StateEntry) {
 		st.entries = st.entries[1:]
 	}
 }
+
+// LatestFor devuelve la entrada más reciente del entorno o, si el entorno no tiene
+// entradas, la más reciente de la tabla. Devuelve nil si la tabla está vacía.
+func (st *StateTable) LatestFor(environment string) *StateEntry {
+	for i := len(st.entries) - 1; i >= 0; i-- {
+		if st.entries[i].Environment().String() == environment {
+			return st.entries[i]
+		}
+	}
+	if len(st.entries) == 0 {
+		return nil
+	}
+	return st.entries[len(st.entries)-1]
+}
+
+// RemoveEnvironment borra las entradas de un entorno y devuelve cuántas borró.
+func (st *StateTable) RemoveEnvironment(environment string) int {
+	return st.removeWhere(func(entry *StateEntry) bool {
+		return entry.Environment().String() == environment
+	})
+}
+
+// RemoveAll borra todas las entradas y devuelve cuántas borró.
+func (st *StateTable) RemoveAll() int {
+	return st.removeWhere(func(*StateEntry) bool { return true })
+}
+
+// RemoveOlderThan borra las entradas creadas antes de cutoff y devuelve cuántas borró.
+func (st *StateTable) RemoveOlderThan(cutoff time.Time) int {
+	return st.removeWhere(func(entry *StateEntry) bool {
+		return entry.CreatedAt().Before(cutoff)
+	})
+}
+
+func (st *StateTable) removeWhere(remove func(*StateEntry) bool) int {
+	kept := make([]*StateEntry, 0, len(st.entries))
+	for _, entry := range st.entries {
+		if !remove(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	removed := len(st.entries) - len(kept)
+	st.entries = kept
+	return removed
+}
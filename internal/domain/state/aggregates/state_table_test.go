@@ -163,3 +163,54 @@ func TestLoadStateTable(t *testing.T) {
 		}
 	})
 }
+
+func TestStateTable_RemoveEnvironment(t *testing.T) {
+	st := NewStateTable(vos.StepTest)
+	st.AddEntry(NewStateEntry(newFingerprint("c1"), newFingerprint("i1"), newFingerprint("v1"), newEnv("dev")))
+	st.AddEntry(NewStateEntry(newFingerprint("c2"), newFingerprint("i2"), newFingerprint("v2"), newEnv("prod")))
+	st.AddEntry(NewStateEntry(newFingerprint("c3"), newFingerprint("i3"), newFingerprint("v3"), newEnv("dev")))
+
+	if removed := st.RemoveEnvironment("dev"); removed != 2 {
+		t.Errorf("Se esperaba borrar 2 entradas, pero se borraron %d", removed)
+	}
+	if len(st.Entries()) != 1 || st.Entries()[0].Environment().String() != "prod" {
+		t.Errorf("Solo debería quedar la entrada de prod, pero quedan %v", st.Entries())
+	}
+	if removed := st.RemoveAll(); removed != 1 || len(st.Entries()) != 0 {
+		t.Errorf("RemoveAll debería vaciar la tabla, se borraron %d y quedan %d", removed, len(st.Entries()))
+	}
+}
+
+func TestStateTable_RemoveOlderThan(t *testing.T) {
+	st := NewStateTable(vos.StepTest)
+	now := time.Now().UTC()
+
+	old := NewStateEntry(newFingerprint("c1"), newFingerprint("i1"), newFingerprint("v1"), newEnv("dev"))
+	old.SetCreatedAt(now.Add(-40 * 24 * time.Hour))
+	recent := NewStateEntry(newFingerprint("c2"), newFingerprint("i2"), newFingerprint("v2"), newEnv("dev"))
+	recent.SetCreatedAt(now.Add(-time.Hour))
+	st.AddEntry(old)
+	st.AddEntry(recent)
+
+	if removed := st.RemoveOlderThan(now.Add(-30 * 24 * time.Hour)); removed != 1 {
+		t.Errorf("Se esperaba borrar 1 entrada, pero se borraron %d", removed)
+	}
+	if len(st.Entries()) != 1 || !st.Entries()[0].CreatedAt().Equal(recent.CreatedAt()) {
+		t.Errorf("Solo debería quedar la entrada reciente, pero quedan %v", st.Entries())
+	}
+}
+
+func TestStateEntry_ChangedFingerprints(t *testing.T) {
+	entry := NewStateEntry(newFingerprint("c1"), newFingerprint("i1"), newFingerprint("v1"), newEnv("dev"))
+
+	same := vos.NewCurrentStateFingerprints(newFingerprint("c1"), newFingerprint("i1"), newFingerprint("v1"), newEnv("dev"))
+	if changed := entry.ChangedFingerprints(same); len(changed) != 0 {
+		t.Errorf("No se esperaban huellas cambiadas, pero se obtuvo %v", changed)
+	}
+
+	current := vos.NewCurrentStateFingerprints(newFingerprint("c2"), newFingerprint("i1"), newFingerprint("v2"), newEnv("dev"))
+	changed := entry.ChangedFingerprints(current)
+	if len(changed) != 2 || changed[0] != vos.CodeFingerprint || changed[1] != vos.VarsFingerprint {
+		t.Errorf("Se esperaban las huellas de código y variables, pero se obtuvo %v", changed)
+	}
+}
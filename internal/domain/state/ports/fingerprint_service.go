@@ -5,4 +5,11 @@ import "github.com/jairoprogramador/vex/internal/domain/state/vos"
 type FingerprintService interface {
 	FromFile(filePath string) (vos.Fingerprint, error)
 	FromDirectory(dirPath string) (vos.Fingerprint, error)
+	// DirectoryManifest devuelve el hash de cada archivo de un directorio por su ruta
+	// relativa, con las mismas reglas de exclusión que FromDirectory y limitado a los
+	// archivos de scope.
+	DirectoryManifest(dirPath string, scope vos.FingerprintScope) (vos.Manifest, error)
+	// FromManifest combina un manifiesto en un único fingerprint. Para un directorio
+	// sin scope, coincide con FromDirectory.
+	FromManifest(manifest vos.Manifest) (vos.Fingerprint, error)
 }
@@ -0,0 +1,9 @@
+package ports
+
+// ManifestKeyRepository guarda la clave del workspace con la que se resumen los valores
+// de las variables secretas en los manifiestos del estado. Sin la clave, el resumen no
+// permite comprobar por fuerza bruta un valor candidato.
+type ManifestKeyRepository interface {
+	// LoadOrCreate devuelve la clave guardada en filePath y la genera si no existe.
+	LoadOrCreate(filePath string) ([]byte, error)
+}
@@ -1,6 +1,9 @@
 package ports
 
 import (
+	"time"
+
+	"github.com/jairoprogramador/vex/internal/domain/state/aggregates"
 	"github.com/jairoprogramador/vex/internal/domain/state/vos"
 )
 
@@ -16,4 +19,21 @@ type StateManager interface {
 
 	// UpdateState guarda el nuevo estado de un paso.
 	UpdateState(stateTablePath string, currentState vos.CurrentStateFingerprints) error
+
+	// GetState devuelve la tabla de estado de un paso, o nil si no hay estado guardado.
+	GetState(stateTablePath string) (*aggregates.StateTable, error)
+
+	// MigrateState convierte al formato actual una tabla de estado guardada en un
+	// formato anterior. Indica si la convirtió.
+	MigrateState(stateTablePath string) (bool, error)
+
+	// ListStates devuelve las rutas de las tablas de estado de un directorio.
+	ListStates(stateDirPath string) ([]string, error)
+
+	// ClearState borra las entradas de un entorno, o todas si environment está
+	// vacío, y devuelve cuántas borró.
+	ClearState(stateTablePath, environment string) (int, error)
+
+	// PruneState borra las entradas creadas antes de cutoff y devuelve cuántas borró.
+	PruneState(stateTablePath string, cutoff time.Time) (int, error)
 }
@@ -7,4 +7,10 @@ import (
 type StateRepository interface {
 	Get(filePath string) (*aggregates.StateTable, error)
 	Save(filePath string, stateTable *aggregates.StateTable) error
+	// List devuelve las rutas de las tablas de estado de un directorio. Si el
+	// directorio no existe, devuelve una lista vacía.
+	List(dirPath string) ([]string, error)
+	// Migrate convierte una tabla de estado guardada en un formato anterior al actual.
+	// Indica si hizo falta convertirla.
+	Migrate(filePath string) (bool, error)
 }
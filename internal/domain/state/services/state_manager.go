@@ -3,6 +3,7 @@ package services
 import (
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jairoprogramador/vex/internal/domain/state/aggregates"
 	"github.com/jairoprogramador/vex/internal/domain/state/ports"
@@ -60,12 +61,53 @@ func (sm *StateManager) UpdateState(
 		currentState.Instruction(),
 		currentState.Vars(),
 		currentState.Environment(),
+		aggregates.WithManifests(currentState.CodeManifest(), currentState.VarsManifest()),
 	)
 	stateTable.AddEntry(newEntry)
 
 	return sm.stateRepo.Save(filePath, stateTable)
 }
 
+func (sm *StateManager) GetState(filePath string) (*aggregates.StateTable, error) {
+	return sm.stateRepo.Get(filePath)
+}
+
+func (sm *StateManager) MigrateState(filePath string) (bool, error) {
+	return sm.stateRepo.Migrate(filePath)
+}
+
+func (sm *StateManager) ListStates(dirPath string) ([]string, error) {
+	return sm.stateRepo.List(dirPath)
+}
+
+func (sm *StateManager) ClearState(filePath, environment string) (int, error) {
+	return sm.removeEntries(filePath, func(stateTable *aggregates.StateTable) int {
+		if environment == "" {
+			return stateTable.RemoveAll()
+		}
+		return stateTable.RemoveEnvironment(environment)
+	})
+}
+
+func (sm *StateManager) PruneState(filePath string, cutoff time.Time) (int, error) {
+	return sm.removeEntries(filePath, func(stateTable *aggregates.StateTable) int {
+		return stateTable.RemoveOlderThan(cutoff)
+	})
+}
+
+// removeEntries aplica remove a la tabla y la guarda solo si borró alguna entrada.
+func (sm *StateManager) removeEntries(filePath string, remove func(*aggregates.StateTable) int) (int, error) {
+	stateTable, err := sm.stateRepo.Get(filePath)
+	if err != nil || stateTable == nil {
+		return 0, err
+	}
+	removed := remove(stateTable)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, sm.stateRepo.Save(filePath, stateTable)
+}
+
 func (sm *StateManager) findMatch(
 	st *aggregates.StateTable,
 	currentState vos.CurrentStateFingerprints,
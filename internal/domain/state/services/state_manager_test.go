@@ -35,6 +35,14 @@ func (m *mockStateRepository) Save(filePath string, stateTable *aggregates.State
 	return nil
 }
 
+func (m *mockStateRepository) List(dirPath string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (m *mockStateRepository) Migrate(filePath string) (bool, error) {
+	return false, nil
+}
+
 func newFingerprint(v string) vos.Fingerprint {
 	fp, _ := vos.NewFingerprint(v)
 	return fp
@@ -5,6 +5,10 @@ type CurrentStateFingerprints struct {
 	instruction Fingerprint
 	environment Environment
 	vars        Fingerprint
+	// codeManifest y varsManifest detallan de qué se componen los fingerprints de
+	// código y de variables.
+	codeManifest Manifest
+	varsManifest Manifest
 }
 
 func NewCurrentStateFingerprints(
@@ -34,3 +38,18 @@ func (c CurrentStateFingerprints) Environment() Environment {
 func (c CurrentStateFingerprints) Vars() Fingerprint {
 	return c.vars
 }
+
+// WithManifests devuelve una copia con el detalle de los fingerprints de código y de variables.
+func (c CurrentStateFingerprints) WithManifests(code, vars Manifest) CurrentStateFingerprints {
+	c.codeManifest = code
+	c.varsManifest = vars
+	return c
+}
+
+func (c CurrentStateFingerprints) CodeManifest() Manifest {
+	return c.codeManifest
+}
+
+func (c CurrentStateFingerprints) VarsManifest() Manifest {
+	return c.varsManifest
+}
@@ -1,6 +1,11 @@
 package vos
 
-import "errors"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
 
 type Fingerprint struct {
 	value string
@@ -19,4 +24,23 @@ func (f Fingerprint) String() string {
 
 func (f Fingerprint) Equals(other Fingerprint) bool {
 	return f.value == other.value
-}
\ No newline at end of file
+}
+
+// CombineFingerprints reduce a uno los fingerprints de varias fuentes, por ejemplo
+// las capas de una plantilla. Los vacíos se ignoran y uno solo se conserva tal cual.
+func CombineFingerprints(fingerprints ...Fingerprint) Fingerprint {
+	values := make([]string, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		if fingerprint.value != "" {
+			values = append(values, fingerprint.value)
+		}
+	}
+	switch len(values) {
+	case 0:
+		return Fingerprint{}
+	case 1:
+		return Fingerprint{value: values[0]}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(values, "\n")))
+	return Fingerprint{value: hex.EncodeToString(sum[:])}
+}
@@ -0,0 +1,11 @@
+package vos
+
+// FingerprintKind identifica cada una de las huellas con las que se evalúa un paso.
+type FingerprintKind string
+
+const (
+	CodeFingerprint        FingerprintKind = "código"
+	InstructionFingerprint FingerprintKind = "instrucciones"
+	VarsFingerprint        FingerprintKind = "variables"
+	EnvironmentFingerprint FingerprintKind = "entorno"
+)
@@ -0,0 +1,86 @@
+package vos
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FingerprintScope limita los archivos del proyecto que cuentan para el fingerprint
+// de código de un paso. Los patrones son rutas relativas a la raíz del proyecto con
+// la sintaxis de path.Match, donde '**' equivale a cualquier número de directorios.
+// Un patrón que coincide con un directorio abarca todo su contenido.
+type FingerprintScope struct {
+	include []string
+	exclude []string
+}
+
+func NewFingerprintScope(include, exclude []string) FingerprintScope {
+	return FingerprintScope{
+		include: append([]string(nil), include...),
+		exclude: append([]string(nil), exclude...),
+	}
+}
+
+func (s FingerprintScope) Include() []string {
+	return append([]string(nil), s.include...)
+}
+
+func (s FingerprintScope) Exclude() []string {
+	return append([]string(nil), s.exclude...)
+}
+
+func (s FingerprintScope) IsEmpty() bool {
+	return len(s.include) == 0 && len(s.exclude) == 0
+}
+
+// Includes indica si un archivo cuenta para el fingerprint: sin patrones de
+// inclusión cuentan todos, y los de exclusión prevalecen sobre los de inclusión.
+func (s FingerprintScope) Includes(relPath string) bool {
+	segments := splitPath(relPath)
+	if len(s.include) > 0 && !matchesAny(s.include, segments) {
+		return false
+	}
+	return !matchesAny(s.exclude, segments)
+}
+
+// ExcludesDir indica si un directorio queda excluido por completo y no hace falta recorrerlo.
+func (s FingerprintScope) ExcludesDir(relPath string) bool {
+	return matchesAny(s.exclude, splitPath(relPath))
+}
+
+func splitPath(relPath string) []string {
+	return strings.Split(filepath.ToSlash(relPath), "/")
+}
+
+// matchesAny indica si algún patrón coincide con la ruta o con uno de sus directorios.
+func matchesAny(patterns []string, segments []string) bool {
+	for _, pattern := range patterns {
+		patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+		for end := 1; end <= len(segments); end++ {
+			if matchSegments(patternSegments, segments[:end]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for skip := 0; skip <= len(segments); skip++ {
+			if matchSegments(pattern[1:], segments[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	matched, err := path.Match(pattern[0], segments[0])
+	return err == nil && matched && matchSegments(pattern[1:], segments[1:])
+}
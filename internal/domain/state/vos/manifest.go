@@ -0,0 +1,81 @@
+package vos
+
+import "sort"
+
+// Manifest asocia cada elemento que forma un fingerprint, como la ruta relativa de
+// un archivo o el nombre de una variable, con su hash. Permite explicar qué cambió
+// cuando el fingerprint combinado deja de coincidir.
+type Manifest struct {
+	hashes map[string]string
+}
+
+func NewManifest(hashes map[string]string) Manifest {
+	hashesCopy := make(map[string]string, len(hashes))
+	for key, hash := range hashes {
+		hashesCopy[key] = hash
+	}
+	return Manifest{hashes: hashesCopy}
+}
+
+// Hashes devuelve una copia del manifiesto.
+func (m Manifest) Hashes() map[string]string {
+	return NewManifest(m.hashes).hashes
+}
+
+// Keys devuelve los elementos del manifiesto ordenados.
+func (m Manifest) Keys() []string {
+	keys := make([]string, 0, len(m.hashes))
+	for key := range m.hashes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m Manifest) IsEmpty() bool {
+	return len(m.hashes) == 0
+}
+
+// Diff compara el manifiesto con uno anterior.
+func (m Manifest) Diff(previous Manifest) ManifestDiff {
+	var diff ManifestDiff
+	for _, key := range m.Keys() {
+		previousHash, existed := previous.hashes[key]
+		switch {
+		case !existed:
+			diff.added = append(diff.added, key)
+		case previousHash != m.hashes[key]:
+			diff.modified = append(diff.modified, key)
+		}
+	}
+	for _, key := range previous.Keys() {
+		if _, exists := m.hashes[key]; !exists {
+			diff.removed = append(diff.removed, key)
+		}
+	}
+	return diff
+}
+
+// ManifestDiff son los elementos añadidos, borrados y modificados entre dos
+// manifiestos, ordenados.
+type ManifestDiff struct {
+	added    []string
+	removed  []string
+	modified []string
+}
+
+func (d ManifestDiff) Added() []string {
+	return d.added
+}
+
+func (d ManifestDiff) Removed() []string {
+	return d.removed
+}
+
+func (d ManifestDiff) Modified() []string {
+	return d.modified
+}
+
+func (d ManifestDiff) IsEmpty() bool {
+	return len(d.added) == 0 && len(d.removed) == 0 && len(d.modified) == 0
+}
@@ -0,0 +1,26 @@
+package vos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestManifest_Diff(t *testing.T) {
+	previous := NewManifest(map[string]string{"a.go": "1", "b.go": "2", "c.go": "3"})
+	current := NewManifest(map[string]string{"a.go": "1", "b.go": "20", "d.go": "4"})
+
+	diff := current.Diff(previous)
+
+	if !reflect.DeepEqual(diff.Added(), []string{"d.go"}) {
+		t.Errorf("Se esperaba d.go como añadido, pero se obtuvo %v", diff.Added())
+	}
+	if !reflect.DeepEqual(diff.Removed(), []string{"c.go"}) {
+		t.Errorf("Se esperaba c.go como borrado, pero se obtuvo %v", diff.Removed())
+	}
+	if !reflect.DeepEqual(diff.Modified(), []string{"b.go"}) {
+		t.Errorf("Se esperaba b.go como modificado, pero se obtuvo %v", diff.Modified())
+	}
+	if !current.Diff(current).IsEmpty() {
+		t.Error("Un manifiesto comparado consigo mismo no debería tener cambios")
+	}
+}
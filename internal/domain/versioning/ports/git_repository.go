@@ -19,4 +19,15 @@ type GitRepository interface {
 
 	// CreateTagForCommit crea un nuevo tag apuntando a un commit específico.
 	CreateTagForCommit(ctx context.Context, repoPath string, commitHash string, tagName string) error
+
+	// ExportRevision escribe en destPath el árbol de archivos de una revisión (tag o commit)
+	// sin modificar el HEAD ni el índice del repositorio.
+	ExportRevision(ctx context.Context, repoPath string, revision string, destPath string) (*vos.Commit, error)
+
+	// GetRemoteURL obtiene la URL del remoto 'origin'. Devuelve una cadena vacía si no existe.
+	GetRemoteURL(ctx context.Context, repoPath string) (string, error)
+
+	// GetWorkingTreeStatus obtiene la rama actual, los cambios sin confirmar y si HEAD
+	// ya está publicado en la rama de seguimiento del remoto indicado.
+	GetWorkingTreeStatus(ctx context.Context, repoPath string, remote string) (*vos.WorkingTreeStatus, error)
 }
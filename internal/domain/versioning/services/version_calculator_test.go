@@ -46,6 +46,18 @@ func (m *mockGitRepository) CreateTagForCommit(ctx context.Context, repoPath str
 	return nil
 }
 
+func (m *mockGitRepository) ExportRevision(ctx context.Context, repoPath, revision, destPath string) (*vos.Commit, error) {
+	return &vos.Commit{Hash: revision}, nil
+}
+
+func (m *mockGitRepository) GetRemoteURL(ctx context.Context, repoPath string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitRepository) GetWorkingTreeStatus(ctx context.Context, repoPath, remote string) (*vos.WorkingTreeStatus, error) {
+	return &vos.WorkingTreeStatus{}, nil
+}
+
 func TestVersionCalculator_CalculateNextVersion(t *testing.T) {
 	testCases := []struct {
 		name               string
@@ -0,0 +1,33 @@
+package vos
+
+// WorkingTreeStatus describe el estado del repositorio del proyecto frente a una
+// rama remota de seguimiento.
+type WorkingTreeStatus struct {
+	// Branch es la rama actual; vacía si HEAD está desacoplado.
+	Branch string
+	// Head es el SHA completo del commit de HEAD.
+	Head string
+	// ChangedFiles son los archivos modificados, preparados o sin seguimiento que
+	// no están ignorados.
+	ChangedFiles []string
+	// RemoteBranch es la rama remota de seguimiento, p. ej. 'origin/main'; vacía si
+	// no existe.
+	RemoteBranch string
+	// Pushed indica si HEAD ya está contenido en RemoteBranch.
+	Pushed bool
+	// RemoteBranchesAtHead son las ramas del remoto cuya punta es HEAD, sin el
+	// prefijo del remoto. Solo se calculan con HEAD desacoplado, como en CI.
+	RemoteBranchesAtHead []string
+	// TagsAtHead son los tags que apuntan a HEAD. Solo se calculan con HEAD desacoplado.
+	TagsAtHead []string
+}
+
+// IsClean indica si el árbol de trabajo no tiene cambios sin confirmar.
+func (s *WorkingTreeStatus) IsClean() bool {
+	return len(s.ChangedFiles) == 0
+}
+
+// IsDetached indica si HEAD no apunta a ninguna rama.
+func (s *WorkingTreeStatus) IsDetached() bool {
+	return s.Branch == ""
+}
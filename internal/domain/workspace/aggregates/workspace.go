@@ -1,51 +1,97 @@
 package aggregates
 
 import (
+	"errors"
 	"path/filepath"
+	"strings"
 
 	"github.com/jairoprogramador/vex/internal/domain/workspace/vos"
 )
 
+const (
+	repositoriesDirName = "repositories"
+	varsDirName         = "vars"
+	stateDirName        = "state"
+	manifestKeyFileName = "manifest.key"
+)
+
+// Workspace organiza los directorios de vex para un proyecto. templateName identifica
+// la pila de plantillas del proyecto; cada capa de la pila tiene su propio árbol de
+// trabajo, y todas las referencias de un repositorio comparten un único clon bare.
 type Workspace struct {
 	rootPath     vos.RootPath
 	projectName  vos.ProjectName
 	templateName vos.TemplateName
+	layers       []vos.TemplateLayer
+}
+
+type WorkspaceOption func(*Workspace)
+
+// WithLocalTemplate hace que la capa superior de la plantilla se lea directamente de
+// un directorio local en lugar de su clon en 'repositories'.
+func WithLocalTemplate(path string) WorkspaceOption {
+	return func(w *Workspace) {
+		top := len(w.layers) - 1
+		w.layers[top] = vos.NewLocalTemplateLayer(w.layers[top].Name(), path)
+	}
 }
 
 func NewWorkspace(
 	rootPath vos.RootPath,
 	projectName vos.ProjectName,
-	templateName vos.TemplateName) (*Workspace, error) {
+	templateName vos.TemplateName,
+	layers []vos.TemplateLayer,
+	opts ...WorkspaceOption) (*Workspace, error) {
 
-	return &Workspace{
+	if len(layers) == 0 {
+		return nil, errors.New("el workspace necesita al menos una capa de plantilla")
+	}
+	workspace := &Workspace{
 		rootPath:     rootPath,
 		projectName:  projectName,
 		templateName: templateName,
-	}, nil
+		layers:       append([]vos.TemplateLayer(nil), layers...),
+	}
+	for _, opt := range opts {
+		opt(workspace)
+	}
+	return workspace, nil
 }
 
-func (w *Workspace) TemplatePath() string {
-	return filepath.Join(w.rootPath.Path(), "repositories", w.templateName.String())
+// TemplateLayers devuelve las capas de la plantilla en orden de aplicación.
+func (w *Workspace) TemplateLayers() []vos.TemplateLayer {
+	return append([]vos.TemplateLayer(nil), w.layers...)
 }
 
-func (w *Workspace) StepTemplatePath(stepNameDir string) string {
-	return filepath.Join(w.TemplatePath(), "steps", stepNameDir)
+// LayerPath es el directorio desde el que se lee una capa de la plantilla.
+func (w *Workspace) LayerPath(layer vos.TemplateLayer) string {
+	if layer.IsLocal() {
+		return layer.LocalPath()
+	}
+	return filepath.Join(w.repositoriesPath(), "worktrees", layer.Name().String())
 }
 
-func (w *Workspace) VarsTemplatePath(environment, stepName string) string {
-	fileNameStep, err := vos.NewFileName(stepName, "yaml")
-	if err != nil {
-		return ""
-	}
-	return filepath.Join(w.TemplatePath(), "variables", environment, fileNameStep.String())
+// LayerRepositoryPath es el clon bare que comparten los árboles de trabajo de
+// todas las referencias del repositorio de una capa.
+func (w *Workspace) LayerRepositoryPath(layer vos.TemplateLayer) string {
+	return filepath.Join(w.repositoriesPath(), layer.Repository().String()+".git")
+}
+
+func (w *Workspace) repositoriesPath() string {
+	return filepath.Join(w.rootPath.Path(), repositoriesDirName)
 }
 
 func (w *Workspace) WorkspacePath() string {
-	return filepath.Join(w.rootPath.Path(), w.projectName.String(), w.templateName.String())
+	return filepath.Join(w.ProjectDirPath(), w.templateName.String())
+}
+
+// ProjectDirPath agrupa los workspaces de todas las plantillas que usó el proyecto.
+func (w *Workspace) ProjectDirPath() string {
+	return filepath.Join(w.rootPath.Path(), w.projectName.String())
 }
 
 func (w *Workspace) VarsDirPath() string {
-	return filepath.Join(w.WorkspacePath(), "vars")
+	return filepath.Join(w.WorkspacePath(), varsDirName)
 }
 
 func (w *Workspace) VarsFilePath(scopeName, stepName string) string {
@@ -56,6 +102,16 @@ func (w *Workspace) VarsFilePath(scopeName, stepName string) string {
 	return filepath.Join(w.VarsDirPath(), scopeName, fileName.String())
 }
 
+// VarsScopeDirPath es el directorio con las variables guardadas de un entorno o de 'shared'.
+func (w *Workspace) VarsScopeDirPath(scopeName string) string {
+	return filepath.Join(w.VarsDirPath(), scopeName)
+}
+
+// VarsStepName devuelve el paso al que pertenece un archivo de variables.
+func (w *Workspace) VarsStepName(filePath string) (string, bool) {
+	return vos.ParseVarsFileName(filepath.Base(filePath))
+}
+
 func (w *Workspace) WorkdirPath() string {
 	return filepath.Join(w.WorkspacePath(), "workdir")
 }
@@ -64,8 +120,14 @@ func (w *Workspace) ScopeWorkdirPath(scope string, stepName string) string {
 	return filepath.Join(w.WorkdirPath(), scope, stepName)
 }
 
+// ManifestKeyPath es la clave con la que se resumen los valores secretos en los
+// manifiestos de variables del estado. Es propia de cada workspace.
+func (w *Workspace) ManifestKeyPath() string {
+	return filepath.Join(w.WorkspacePath(), manifestKeyFileName)
+}
+
 func (w *Workspace) StateDirPath() string {
-	return filepath.Join(w.WorkspacePath(), "state")
+	return filepath.Join(w.WorkspacePath(), stateDirName)
 }
 
 func (w *Workspace) StateTablePath(stateName string) (string, error) {
@@ -75,3 +137,38 @@ func (w *Workspace) StateTablePath(stateName string) (string, error) {
 	}
 	return filepath.Join(w.StateDirPath(), fileName.String()), nil
 }
+
+// StateStepName devuelve el paso al que pertenece una tabla de estado.
+func (w *Workspace) StateStepName(filePath string) (string, bool) {
+	return vos.ParseStateFileName(filepath.Base(filePath))
+}
+
+func (w *Workspace) ReleasesDirPath(environment string) string {
+	return filepath.Join(w.WorkspacePath(), "releases", environment)
+}
+
+func (w *Workspace) AttestationsDirPath(environment string) string {
+	return filepath.Join(w.WorkspacePath(), "attestations", environment)
+}
+
+// IsStateTablePath indica si relPath, relativo a la raíz de vex, es la tabla de estado
+// de un paso en algún workspace: <proyecto>/<plantilla>/state/<paso>.tb.
+func IsStateTablePath(relPath string) bool {
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	if len(parts) != 4 || parts[0] == repositoriesDirName || parts[2] != stateDirName {
+		return false
+	}
+	_, isStateTable := vos.ParseStateFileName(parts[3])
+	return isStateTable
+}
+
+// IsVarsFilePath indica si relPath, relativo a la raíz de vex, es el archivo de
+// variables de un paso en algún workspace: <proyecto>/<plantilla>/vars/<ámbito>/<paso>.var.
+func IsVarsFilePath(relPath string) bool {
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	if len(parts) != 5 || parts[0] == repositoriesDirName || parts[2] != varsDirName {
+		return false
+	}
+	_, isVarsFile := vos.ParseVarsFileName(parts[4])
+	return isVarsFile
+}
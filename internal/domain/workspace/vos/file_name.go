@@ -36,3 +36,23 @@ func NewStateFileName(stepName string) (FileName, error) {
 func (f FileName) String() string {
 	return f.value
 }
+
+// ParseVarsFileName devuelve el nombre del paso de un archivo de variables, p. ej.
+// 'deploy' para 'deploy.var'. Indica false si el archivo no es de variables.
+func ParseVarsFileName(fileName string) (string, bool) {
+	return parseFileName(fileName, varsExtension)
+}
+
+// ParseStateFileName devuelve el nombre del paso de una tabla de estado, p. ej.
+// 'deploy' para 'deploy.tb'. Indica false si el archivo no es una tabla de estado.
+func ParseStateFileName(fileName string) (string, bool) {
+	return parseFileName(fileName, stateExtension)
+}
+
+func parseFileName(fileName, extension string) (string, bool) {
+	stepName, found := strings.CutSuffix(fileName, "."+extension)
+	if !found || stepName == "" {
+		return "", false
+	}
+	return stepName, true
+}
@@ -0,0 +1,34 @@
+package vos
+
+// TemplateLayer ubica una capa de la plantilla: un árbol de trabajo en la caché local,
+// enlazado al clon bare de su repositorio, o un directorio local que se usa tal cual.
+type TemplateLayer struct {
+	name       TemplateName
+	repository TemplateName
+	localPath  string
+}
+
+func NewTemplateLayer(name, repository TemplateName) TemplateLayer {
+	return TemplateLayer{name: name, repository: repository}
+}
+
+func NewLocalTemplateLayer(name TemplateName, localPath string) TemplateLayer {
+	return TemplateLayer{name: name, localPath: localPath}
+}
+
+func (l TemplateLayer) Name() TemplateName {
+	return l.name
+}
+
+func (l TemplateLayer) Repository() TemplateName {
+	return l.repository
+}
+
+func (l TemplateLayer) LocalPath() string {
+	return l.localPath
+}
+
+// IsLocal indica si la capa se lee de un directorio local y no debe clonarse.
+func (l TemplateLayer) IsLocal() bool {
+	return l.localPath != ""
+}
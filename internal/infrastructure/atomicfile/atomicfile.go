@@ -0,0 +1,43 @@
+// Package atomicfile escribe archivos de forma que quien los lea vea siempre el
+// contenido anterior o el nuevo completo, nunca uno a medias.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile escribe data en un archivo temporal del mismo directorio y lo renombra a
+// filePath. Crea el directorio si no existe.
+func WriteFile(filePath string, data []byte) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("no se pudo crear el directorio '%s': %w", dir, err)
+	}
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("no se pudo crear un archivo temporal en '%s': %w", dir, err)
+	}
+	// Tras el renombrado el temporal ya no existe y Remove no hace nada.
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("no se pudo escribir '%s': %w", filePath, err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("no se pudo escribir '%s': %w", filePath, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("no se pudo escribir '%s': %w", filePath, err)
+	}
+	if err := os.Chmod(tmpFile.Name(), 0644); err != nil {
+		return fmt.Errorf("no se pudo escribir '%s': %w", filePath, err)
+	}
+	if err := os.Rename(tmpFile.Name(), filePath); err != nil {
+		return fmt.Errorf("no se pudo reemplazar '%s': %w", filePath, err)
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package dto
 
 type CommandDTO struct {
-	Name          string   `yaml:"name"`
-	Description   string   `yaml:"description,omitempty"`
-	Cmd           string   `yaml:"cmd"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Cmd         string `yaml:"cmd"`
+	// Remove elimina, en un overlay, el comando del mismo nombre de las capas anteriores.
+	Remove        bool     `yaml:"remove,omitempty"`
 	Workdir       string   `yaml:"workdir,omitempty"`
 	TemplateFiles []string `yaml:"templates,omitempty"`
 	Outputs       []struct {
@@ -1,6 +1,15 @@
 package dto
 
 type EnvironmentDTO struct {
-	Name  string `yaml:"name"`
-	Value string `yaml.value:"value"`
+	Name        string `yaml:"name"`
+	Value       string `yaml:"value"`
+	Description string `yaml:"description,omitempty"`
+	Extends     string `yaml:"extends,omitempty"`
+	Order       int    `yaml:"order,omitempty"`
+	// Protected es un puntero para distinguir un 'false' explícito de uno omitido.
+	Protected *bool  `yaml:"protected,omitempty"`
+	Group     string `yaml:"group,omitempty"`
+	// AllowedBranches y Remote solo se aplican a los entornos protegidos.
+	AllowedBranches []string `yaml:"allowed_branches,omitempty"`
+	Remote          string   `yaml:"remote,omitempty"`
 }
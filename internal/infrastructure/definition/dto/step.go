@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StepFileDTO representa el contenido de un archivo commands.yaml.
+// Acepta el formato clásico (una lista de comandos) y el formato extendido
+// (un mapa con los comandos y los hooks del paso). En un overlay, replace
+// descarta el paso de las capas anteriores en lugar de combinarse con él.
+type StepFileDTO struct {
+	Replace   bool         `yaml:"replace,omitempty"`
+	Commands  []CommandDTO `yaml:"commands"`
+	OnFailure []CommandDTO `yaml:"on_failure,omitempty"`
+	Finally   []CommandDTO `yaml:"finally,omitempty"`
+	Rollback  []CommandDTO `yaml:"rollback,omitempty"`
+	// Fingerprint limita los archivos del proyecto que cuentan para el paso.
+	Fingerprint *FingerprintDTO `yaml:"fingerprint,omitempty"`
+}
+
+type FingerprintDTO struct {
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// stepFileMappingDTO evita la recursión de UnmarshalYAML al decodificar el formato extendido.
+type stepFileMappingDTO StepFileDTO
+
+func (s *StepFileDTO) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return node.Decode(&s.Commands)
+	case yaml.MappingNode:
+		var mapping stepFileMappingDTO
+		if err := node.Decode(&mapping); err != nil {
+			return err
+		}
+		*s = StepFileDTO(mapping)
+		return nil
+	default:
+		return fmt.Errorf("formato de comandos no soportado en la línea %d", node.Line)
+	}
+}
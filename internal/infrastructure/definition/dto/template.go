@@ -0,0 +1,6 @@
+package dto
+
+// TemplateSettingsDTO representa el archivo template.yaml de la raíz de una plantilla.
+type TemplateSettingsDTO struct {
+	StrictOutputs *bool `yaml:"strict_outputs,omitempty"`
+}
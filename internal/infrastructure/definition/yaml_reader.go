@@ -36,7 +36,18 @@ func (r *YamlDefinitionReader) ReadEnvironments(ctx context.Context, sourcePath
 
 	environments := make([]vos.EnvironmentDefinition, 0, len(dtos))
 	for _, envDTO := range dtos {
-		env, err := vos.NewEnvironment(envDTO.Value, envDTO.Name)
+		opts := []vos.EnvironmentOption{
+			vos.WithEnvironmentDescription(envDTO.Description),
+			vos.WithExtends(envDTO.Extends),
+			vos.WithOrder(envDTO.Order),
+			vos.WithGroup(envDTO.Group),
+			vos.WithAllowedBranches(envDTO.AllowedBranches),
+			vos.WithRemote(envDTO.Remote),
+		}
+		if envDTO.Protected != nil {
+			opts = append(opts, vos.WithProtected(*envDTO.Protected))
+		}
+		env, err := vos.NewEnvironment(envDTO.Value, envDTO.Name, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("entorno inválido en el archivo de definición: %w", err)
 		}
@@ -67,23 +78,78 @@ func (r *YamlDefinitionReader) ReadStepNames(ctx context.Context, stepsDir strin
 	return stepNames, nil
 }
 
-// ReadCommands lee y parsea un archivo commands.yaml.
-func (r *YamlDefinitionReader) ReadCommands(ctx context.Context, commandsFilePath string) ([]vos.CommandDefinition, error) {
+// ReadStepFile lee los comandos y los hooks on_failure, finally y rollback declarados
+// en un archivo commands.yaml.
+func (r *YamlDefinitionReader) ReadStepFile(ctx context.Context, commandsFilePath string) (vos.StepFileDefinition, error) {
+	stepFile, err := r.readStepFile(commandsFilePath)
+	if err != nil {
+		return vos.StepFileDefinition{}, err
+	}
+	if stepFile == nil {
+		return vos.NewStepFileDefinition([]vos.CommandDefinition{}, vos.NewStepHooksDefinition(), false), nil
+	}
+
+	commands, err := toCommandDefinitions(stepFile.Commands)
+	if err != nil {
+		return vos.StepFileDefinition{}, err
+	}
+	onFailure, err := toCommandDefinitions(stepFile.OnFailure)
+	if err != nil {
+		return vos.StepFileDefinition{}, fmt.Errorf("hook on_failure inválido: %w", err)
+	}
+	finally, err := toCommandDefinitions(stepFile.Finally)
+	if err != nil {
+		return vos.StepFileDefinition{}, fmt.Errorf("hook finally inválido: %w", err)
+	}
+	rollback, err := toCommandDefinitions(stepFile.Rollback)
+	if err != nil {
+		return vos.StepFileDefinition{}, fmt.Errorf("comando de rollback inválido: %w", err)
+	}
+
+	hooks := vos.NewStepHooksDefinition(
+		vos.WithOnFailure(onFailure),
+		vos.WithFinally(finally),
+		vos.WithRollback(rollback),
+	)
+	stepFileDef := vos.NewStepFileDefinition(commands, hooks, stepFile.Replace)
+	if stepFile.Fingerprint != nil {
+		scope, err := vos.NewFingerprintScopeDefinition(stepFile.Fingerprint.Include, stepFile.Fingerprint.Exclude)
+		if err != nil {
+			return vos.StepFileDefinition{}, fmt.Errorf("bloque fingerprint inválido en '%s': %w", commandsFilePath, err)
+		}
+		stepFileDef = stepFileDef.WithFingerprint(scope)
+	}
+	return stepFileDef, nil
+}
+
+func (r *YamlDefinitionReader) readStepFile(commandsFilePath string) (*dto.StepFileDTO, error) {
 	data, err := os.ReadFile(commandsFilePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return []vos.CommandDefinition{}, nil
+			return nil, nil
 		}
 		return nil, err
 	}
 
-	var dtos []dto.CommandDTO
-	if err := yaml.Unmarshal(data, &dtos); err != nil {
+	var stepFile dto.StepFileDTO
+	if err := yaml.Unmarshal(data, &stepFile); err != nil {
 		return nil, fmt.Errorf("error al parsear YAML de comandos '%s': %w", commandsFilePath, err)
 	}
+	return &stepFile, nil
+}
 
+func toCommandDefinitions(dtos []dto.CommandDTO) ([]vos.CommandDefinition, error) {
 	commands := make([]vos.CommandDefinition, 0, len(dtos))
 	for _, cmdDTO := range dtos {
+		if cmdDTO.Remove {
+			removed, err := vos.NewRemovedCommandDefinition(cmdDTO.Name)
+			if err != nil {
+				return nil, err
+			}
+			commands = append(commands, removed)
+			continue
+		}
+
 		// Mapeo de DTOs de output anidados a VOs de output
 		outputs := make([]vos.OutputDefinition, 0, len(cmdDTO.Outputs))
 		for _, outDTO := range cmdDTO.Outputs {
@@ -136,3 +202,26 @@ func (r *YamlDefinitionReader) ReadVariables(ctx context.Context, variablesFileP
 	}
 	return variables, nil
 }
+
+// ReadTemplateSettings lee y parsea el archivo template.yaml.
+func (r *YamlDefinitionReader) ReadTemplateSettings(
+	ctx context.Context, settingsFilePath string) (vos.TemplateSettingsDefinition, error) {
+	data, err := os.ReadFile(settingsFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return vos.NewTemplateSettingsDefinition(), nil
+		}
+		return vos.TemplateSettingsDefinition{}, err
+	}
+
+	var settingsDTO dto.TemplateSettingsDTO
+	if err := yaml.Unmarshal(data, &settingsDTO); err != nil {
+		return vos.TemplateSettingsDefinition{}, fmt.Errorf("error al parsear YAML de la plantilla '%s': %w", settingsFilePath, err)
+	}
+
+	var opts []vos.TemplateSettingsOption
+	if settingsDTO.StrictOutputs != nil {
+		opts = append(opts, vos.WithStrictOutputs(*settingsDTO.StrictOutputs))
+	}
+	return vos.NewTemplateSettingsDefinition(opts...), nil
+}
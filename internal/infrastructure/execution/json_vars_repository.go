@@ -0,0 +1,188 @@
+package execution
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jairoprogramador/vex/internal/domain/execution/ports"
+	"github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	"github.com/jairoprogramador/vex/internal/infrastructure/atomicfile"
+	"github.com/jairoprogramador/vex/internal/infrastructure/filelock"
+)
+
+// JSONVarsRepository es una implementación de VarsRepository que guarda cada archivo
+// de variables en JSON con la versión de su formato. Los archivos guardados con gob
+// por versiones anteriores se leen tal cual; solo Migrate los convierte a JSON. Las
+// escrituras bloquean el archivo, también frente a otros procesos de vex.
+type JSONVarsRepository struct{}
+
+// NewJSONVarsRepository crea una nueva instancia de JSONVarsRepository.
+func NewJSONVarsRepository() ports.VarsRepository {
+	return &JSONVarsRepository{}
+}
+
+// Get carga una VarTable desde un archivo.
+// Si el archivo no existe o está vacío, devuelve una tabla vacía sin error.
+// No modifica el archivo, aunque use un formato anterior.
+func (r *JSONVarsRepository) Get(filePath string) (vos.VariableSet, error) {
+	varSets, _, err := r.read(filePath)
+	return varSets, err
+}
+
+// Save guarda una VarTable en un archivo. Escribe en un archivo temporal y lo
+// renombra, para que una ejecución simultánea nunca lea un archivo a medias.
+func (r *JSONVarsRepository) Save(filePath string, varSets vos.VariableSet) error {
+	unlock, err := filelock.Lock(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.save(filePath, varSets)
+}
+
+// Delete borra un archivo de variables.
+func (r *JSONVarsRepository) Delete(filePath string) error {
+	unlock, err := filelock.Lock(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return r.delete(filePath)
+}
+
+// Update lee un archivo de variables, le aplica update y guarda el resultado sin
+// soltar el bloqueo, para no perder una escritura simultánea. Si no queda ninguna
+// variable, borra el archivo.
+func (r *JSONVarsRepository) Update(
+	filePath string, update func(vos.VariableSet) (vos.VariableSet, error)) error {
+	unlock, err := filelock.Lock(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	varSets, _, err := r.read(filePath)
+	if err != nil {
+		return err
+	}
+	updated, err := update(varSets)
+	if err != nil {
+		return err
+	}
+	if len(updated) == 0 {
+		return r.delete(filePath)
+	}
+	return r.save(filePath, updated)
+}
+
+func (r *JSONVarsRepository) save(filePath string, varSets vos.VariableSet) error {
+	if len(varSets) == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(toVarsDTO(varSets), "", "  ")
+	if err != nil {
+		return fmt.Errorf("no se pudo codificar el conjunto de variables a '%s': %w", filePath, err)
+	}
+	if err := atomicfile.WriteFile(filePath, data); err != nil {
+		return fmt.Errorf("no se pudo guardar el archivo de conjunto de variables '%s': %w", filePath, err)
+	}
+	return nil
+}
+
+func (r *JSONVarsRepository) delete(filePath string) error {
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no se pudo borrar el archivo de variables '%s': %w", filePath, err)
+	}
+	return nil
+}
+
+// List devuelve los archivos de variables de un directorio, ordenados por nombre.
+func (r *JSONVarsRepository) List(dirPath string) ([]string, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("no se pudo leer el directorio de variables '%s': %w", dirPath, err)
+	}
+
+	filePaths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasSuffix(entry.Name(), filelock.Suffix) {
+			filePaths = append(filePaths, filepath.Join(dirPath, entry.Name()))
+		}
+	}
+	return filePaths, nil
+}
+
+// Migrate convierte a JSON un archivo de variables guardado con gob. Indica si lo convirtió.
+func (r *JSONVarsRepository) Migrate(filePath string) (bool, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("no se pudo abrir el archivo de variables '%s': %w", filePath, err)
+	}
+	unlock, err := filelock.Lock(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	varSets, legacy, err := r.read(filePath)
+	if err != nil || !legacy {
+		return false, err
+	}
+	// Un archivo gob sin variables no se puede guardar en JSON: Save no escribe
+	// conjuntos vacíos, así que se borra.
+	if len(varSets) == 0 {
+		return true, r.delete(filePath)
+	}
+	if err := r.save(filePath, varSets); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// read lee un archivo de variables e indica si estaba guardado con gob.
+func (r *JSONVarsRepository) read(filePath string) (vos.VariableSet, bool, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// El archivo no existe, devolvemos una tabla nueva y vacía.
+			return vos.NewVariableSet(), false, nil
+		}
+		return nil, false, fmt.Errorf("no se pudo abrir el archivo de variables '%s': %w", filePath, err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		// El archivo está vacío, devolvemos una tabla nueva y vacía.
+		return vos.NewVariableSet(), false, nil
+	}
+
+	if !json.Valid(data) {
+		var dtos []VarDTO
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&dtos); err != nil {
+			return nil, false, fmt.Errorf("no se pudo decodificar el conjunto de variables desde '%s': %w", filePath, err)
+		}
+		varSets, err := fromVarsDTO(dtos)
+		return varSets, true, err
+	}
+
+	var dto VarsFileDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, false, fmt.Errorf("no se pudo decodificar el conjunto de variables desde '%s': %w", filePath, err)
+	}
+	if dto.SchemaVersion > varsSchemaVersion {
+		return nil, false, fmt.Errorf("el archivo de variables '%s' usa la versión %d del formato y esta versión de vex solo admite hasta la %d; actualiza vex",
+			filePath, dto.SchemaVersion, varsSchemaVersion)
+	}
+	varSets, err := fromVarsDTO(dto.Variables)
+	return varSets, false, err
+}
@@ -0,0 +1,137 @@
+package execution_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/jairoprogramador/vex/internal/domain/execution/vos"
+	"github.com/jairoprogramador/vex/internal/infrastructure/execution"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONVarsRepository(t *testing.T) {
+	t.Run("should save, list and delete variable files", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "vars", "sand")
+		repository := execution.NewJSONVarsRepository()
+		filePath := filepath.Join(dir, "deploy.var")
+
+		require.NoError(t, repository.Save(filePath, vos.NewVariableSetFromMap(map[string]string{"url": "https://app"})))
+
+		vars, err := repository.Get(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"url": "https://app"}, vars.ToStringMap())
+
+		filePaths, err := repository.List(dir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filePath}, filePaths)
+
+		require.NoError(t, repository.Delete(filePath))
+		filePaths, err = repository.List(dir)
+		require.NoError(t, err)
+		assert.Empty(t, filePaths)
+	})
+
+	t.Run("should ignore missing files and directories", func(t *testing.T) {
+		repository := execution.NewJSONVarsRepository()
+		missing := filepath.Join(t.TempDir(), "missing")
+
+		filePaths, err := repository.List(missing)
+		require.NoError(t, err)
+		assert.Empty(t, filePaths)
+		assert.NoError(t, repository.Delete(filepath.Join(missing, "deploy.var")))
+	})
+	t.Run("should read gob files without rewriting them", func(t *testing.T) {
+		repository := execution.NewJSONVarsRepository()
+		filePath := filepath.Join(t.TempDir(), "deploy.var")
+		var buffer bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buffer).Encode([]execution.VarDTO{{Name: "url", Value: "https://app"}}))
+		require.NoError(t, os.WriteFile(filePath, buffer.Bytes(), 0644))
+
+		vars, err := repository.Get(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"url": "https://app"}, vars.ToStringMap())
+
+		data, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, buffer.Bytes(), data)
+	})
+
+	t.Run("should migrate gob files and skip JSON ones", func(t *testing.T) {
+		repository := execution.NewJSONVarsRepository()
+		filePath := filepath.Join(t.TempDir(), "deploy.var")
+		var buffer bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buffer).Encode([]execution.VarDTO{{Name: "url", Value: "https://app"}}))
+		require.NoError(t, os.WriteFile(filePath, buffer.Bytes(), 0644))
+
+		migrated, err := repository.Migrate(filePath)
+		require.NoError(t, err)
+		assert.True(t, migrated)
+
+		data, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"schema_version": 1, "variables": [{"name": "url", "value": "https://app"}]}`, string(data))
+
+		migrated, err = repository.Migrate(filePath)
+		require.NoError(t, err)
+		assert.False(t, migrated)
+	})
+
+	t.Run("should reject files written by a newer version", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "deploy.var")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"schema_version": 99, "variables": []}`), 0644))
+
+		_, err := execution.NewJSONVarsRepository().Get(filePath)
+
+		assert.ErrorContains(t, err, "versión 99")
+	})
+
+	t.Run("should update a file and delete it when it becomes empty", func(t *testing.T) {
+		repository := execution.NewJSONVarsRepository()
+		filePath := filepath.Join(t.TempDir(), "vars", "sand", "deploy.var")
+
+		require.NoError(t, repository.Update(filePath, func(vars vos.VariableSet) (vos.VariableSet, error) {
+			return vos.NewVariableSetFromMap(map[string]string{"url": "https://app"}), nil
+		}))
+		vars, err := repository.Get(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"url": "https://app"}, vars.ToStringMap())
+
+		require.NoError(t, repository.Update(filePath, func(vars vos.VariableSet) (vos.VariableSet, error) {
+			return vos.NewVariableSet(), nil
+		}))
+		assert.NoFileExists(t, filePath)
+	})
+
+	t.Run("should not lose concurrent updates", func(t *testing.T) {
+		repository := execution.NewJSONVarsRepository()
+		filePath := filepath.Join(t.TempDir(), "deploy.var")
+		updates := 20
+
+		var wg sync.WaitGroup
+		for i := 0; i < updates; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, repository.Update(filePath, func(vars vos.VariableSet) (vos.VariableSet, error) {
+					variable, err := vos.NewOutputVar(fmt.Sprintf("var_%d", i), "valor", false)
+					if err != nil {
+						return nil, err
+					}
+					vars.Add(variable)
+					return vars, nil
+				}))
+			}(i)
+		}
+		wg.Wait()
+
+		vars, err := repository.Get(filePath)
+		require.NoError(t, err)
+		assert.Len(t, vars.ToStringMap(), updates)
+	})
+}
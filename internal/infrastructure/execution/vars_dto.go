@@ -1,21 +1,31 @@
 package execution
 
 import (
+	"sort"
+
 	"github.com/jairoprogramador/vex/internal/domain/execution/vos"
 )
 
-// VarEntryDTO es el objeto de transferencia de datos para la entidad VarEntry.
-// Tiene campos exportados para que el paquete gob pueda serializarlos.
+// varsSchemaVersion es la versión del formato de los archivos de variables. Se
+// incrementa con cada cambio incompatible de VarsFileDTO.
+const varsSchemaVersion = 1
+
+// VarsFileDTO es el contenido de un archivo de variables.
+type VarsFileDTO struct {
+	SchemaVersion int      `json:"schema_version"`
+	Variables     []VarDTO `json:"variables"`
+}
+
+// VarDTO es el objeto de transferencia de datos de una variable. Las versiones
+// anteriores guardaban una lista de VarDTO con gob, que asocia los campos por nombre.
 type VarDTO struct {
-	Name  string
-	Value string
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
-// toVarsDTO convierte un objeto de valor de dominio VariableSet a su DTO para persistencia.
-func toVarsDTO(varSets vos.VariableSet) []VarDTO {
-	if varSets == nil {
-		return []VarDTO{}
-	}
+// toVarsDTO convierte un objeto de valor de dominio VariableSet a su DTO para
+// persistencia. Las variables se ordenan por nombre para que el archivo sea estable.
+func toVarsDTO(varSets vos.VariableSet) VarsFileDTO {
 	dtoVars := make([]VarDTO, 0, len(varSets))
 	for _, outputVar := range varSets {
 		dtoVars = append(dtoVars, VarDTO{
@@ -23,7 +33,8 @@ func toVarsDTO(varSets vos.VariableSet) []VarDTO {
 			Value: outputVar.Value(),
 		})
 	}
-	return dtoVars
+	sort.Slice(dtoVars, func(i, j int) bool { return dtoVars[i].Name < dtoVars[j].Name })
+	return VarsFileDTO{SchemaVersion: varsSchemaVersion, Variables: dtoVars}
 }
 
 // fromVarsDTO convierte un DTO de persistencia a un objeto de valor de dominio VariableSet.
@@ -1,13 +1,18 @@
 package factory
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	applic "github.com/jairoprogramador/vex/internal/application"
 	defServ "github.com/jairoprogramador/vex/internal/domain/definition/services"
+	exePrt "github.com/jairoprogramador/vex/internal/domain/execution/ports"
 	exeServ "github.com/jairoprogramador/vex/internal/domain/execution/services"
+	proPrt "github.com/jairoprogramador/vex/internal/domain/project/ports"
+	staPrt "github.com/jairoprogramador/vex/internal/domain/state/ports"
 	staServ "github.com/jairoprogramador/vex/internal/domain/state/services"
 	verServ "github.com/jairoprogramador/vex/internal/domain/versioning/services"
 	worVos "github.com/jairoprogramador/vex/internal/domain/workspace/vos"
@@ -16,6 +21,8 @@ import (
 	iLgRep "github.com/jairoprogramador/vex/internal/infrastructure/logger/repository"
 	iLgSer "github.com/jairoprogramador/vex/internal/infrastructure/logger/service"
 	iProje "github.com/jairoprogramador/vex/internal/infrastructure/project"
+	iProve "github.com/jairoprogramador/vex/internal/infrastructure/provenance"
+	iRelea "github.com/jairoprogramador/vex/internal/infrastructure/release"
 	iState "github.com/jairoprogramador/vex/internal/infrastructure/state"
 	iVersi "github.com/jairoprogramador/vex/internal/infrastructure/versioning"
 
@@ -25,15 +32,33 @@ import (
 type ServiceFactory interface {
 	BuildExecutionOrchestrator() (*applic.ExecutionOrchestrator, error)
 	BuildLogService() *applic.LoggerService
+	BuildReleaseService() *applic.ReleaseService
+	BuildVarsService() *applic.VarsService
+	BuildProvenanceService() *applic.ProvenanceService
+	BuildTemplateService() *applic.TemplateService
+	BuildStorageMigrationService() *applic.StorageMigrationService
 	PathAppProject() string
 }
 
 type Factory struct {
 	pathAppProject string
 	pathAppVex     string
+	toolVersion    string
+	// paranoidFingerprints vuelve a leer todos los archivos al calcular los fingerprints.
+	paranoidFingerprints bool
 }
 
-func NewFactory() (ServiceFactory, error) {
+type FactoryOption func(*Factory)
+
+// WithParanoidFingerprints calcula los fingerprints leyendo todos los archivos, sin
+// confiar en el índice de tamaños y fechas de modificación.
+func WithParanoidFingerprints(paranoid bool) FactoryOption {
+	return func(f *Factory) {
+		f.paranoidFingerprints = paranoid
+	}
+}
+
+func NewFactory(toolVersion string, opts ...FactoryOption) (ServiceFactory, error) {
 	vexHome := getVexHome()
 
 	workingDir, err := os.Getwd()
@@ -41,10 +66,15 @@ func NewFactory() (ServiceFactory, error) {
 		return nil, fmt.Errorf("error al obtener el directorio de trabajo: %w", err)
 	}
 
-	return &Factory{
+	factory := &Factory{
 		pathAppVex:     vexHome,
 		pathAppProject: workingDir,
-	}, nil
+		toolVersion:    toolVersion,
+	}
+	for _, opt := range opts {
+		opt(factory)
+	}
+	return factory, nil
 }
 
 func (f *Factory) PathAppProject() string {
@@ -53,52 +83,156 @@ func (f *Factory) PathAppProject() string {
 
 func (f *Factory) BuildLogService() *applic.LoggerService {
 	consolePresenter := iLgSer.NewConsolePresenterService()
-	loggerRepository := iLgRep.NewFileLoggerRepository("")
+	loggerRepository := iLgRep.NewFileLoggerRepository(f.pathAppVex)
 	configRepository := iProje.NewYAMLProjectRepository()
 
 	return applic.NewLoggerService(loggerRepository, configRepository, consolePresenter)
 }
 
+func (f *Factory) BuildReleaseService() *applic.ReleaseService {
+	projectRepository := iProje.NewYAMLProjectRepository()
+	releaseRepository := iRelea.NewJSONReleaseRepository()
+
+	return applic.NewReleaseService(
+		f.pathAppProject,
+		f.pathAppVex,
+		applic.NewProjectService(projectRepository),
+		applic.NewWorkspaceService(),
+		releaseRepository,
+	)
+}
+
+func (f *Factory) BuildVarsService() *applic.VarsService {
+	return applic.NewVarsService(
+		f.pathAppProject,
+		f.pathAppVex,
+		applic.NewProjectService(iProje.NewYAMLProjectRepository()),
+		applic.NewWorkspaceService(),
+		iExecut.NewJSONVarsRepository(),
+	)
+}
+
+func (f *Factory) BuildProvenanceService() *applic.ProvenanceService {
+	return applic.NewProvenanceService(
+		f.pathAppProject,
+		f.pathAppVex,
+		applic.NewProjectService(iProje.NewYAMLProjectRepository()),
+		applic.NewWorkspaceService(),
+		iVersi.NewGoGitRepository(),
+		f.newFingerprintService(),
+		iProve.NewDSSEAttestationStore(f.keysDirPath()),
+	)
+}
+
+func (f *Factory) BuildTemplateService() *applic.TemplateService {
+	return applic.NewTemplateService(
+		f.pathAppProject,
+		f.pathAppVex,
+		applic.NewProjectService(iProje.NewYAMLProjectRepository()),
+		applic.NewWorkspaceService(),
+		f.newClonerTemplate(),
+		iProje.NewYAMLTemplateLockRepository(),
+	)
+}
+
+func (f *Factory) BuildStorageMigrationService() *applic.StorageMigrationService {
+	return applic.NewStorageMigrationService(
+		f.pathAppVex,
+		iState.NewJSONStateRepository(),
+		iExecut.NewJSONVarsRepository(),
+	)
+}
+
+// newClonerTemplate usa clones superficiales de la plantilla si VEX_TEMPLATE_SHALLOW está activo.
+func (f *Factory) newClonerTemplate() proPrt.ClonerTemplate {
+	return iProje.NewGitClonerTemplate(iProje.WithShallowClone(viper.GetBool("TEMPLATE_SHALLOW")))
+}
+
+// fingerprintIndexPath es el índice de hashes del proyecto, uno por directorio de proyecto.
+func (f *Factory) fingerprintIndexPath() string {
+	sum := sha256.Sum256([]byte(f.pathAppProject))
+	return filepath.Join(f.pathAppVex, "fingerprints", hex.EncodeToString(sum[:8])+".idx")
+}
+
+func (f *Factory) newFingerprintService() staPrt.FingerprintService {
+	return iState.NewSha256FingerprintService(
+		iState.WithIndexFile(f.fingerprintIndexPath()),
+		iState.WithParanoid(f.paranoidFingerprints),
+	)
+}
+
+func (f *Factory) keysDirPath() string {
+	return filepath.Join(f.pathAppVex, "keys")
+}
+
 func (f *Factory) BuildExecutionOrchestrator() (*applic.ExecutionOrchestrator, error) {
 	// Infrastructure Layer
 	commandRunner := iExecut.NewShellCommandRunner()
 	fileSystem := iExecut.NewOSFileSystem()
-	gitClonerTemplate := iProje.NewGitClonerTemplate()
+	gitClonerTemplate := f.newClonerTemplate()
 	gitRepository := iVersi.NewGoGitRepository()
 	definitionReader := iDefini.NewYamlDefinitionReader()
 	projectRepository := iProje.NewYAMLProjectRepository()
-	fingerprintService := iState.NewSha256FingerprintService()
-	stateRepository := iState.NewGobStateRepository()
+	fingerprintService := f.newFingerprintService()
+	stateRepository := iState.NewJSONStateRepository()
 	copyWorkdir := iExecut.NewCopyWorkdir()
-	varsRepository := iExecut.NewGobVarsRepository()
+	varsRepository := iExecut.NewJSONVarsRepository()
+	releaseRepository := iRelea.NewJSONReleaseRepository()
+	attestationStore := iProve.NewDSSEAttestationStore(f.keysDirPath())
+	templateLockRepository := iProje.NewYAMLTemplateLockRepository()
 
 	// Domain & Application Services
 	projectService := applic.NewProjectService(projectRepository)
 	workspaceService := applic.NewWorkspaceService()
+	templateService := applic.NewTemplateService(
+		f.pathAppProject,
+		f.pathAppVex,
+		projectService,
+		workspaceService,
+		gitClonerTemplate,
+		templateLockRepository,
+	)
 	versionCalculator := verServ.NewVersionCalculator(gitRepository)
 	planBuilder := defServ.NewPlanBuilder(definitionReader)
 	stateManager := staServ.NewStateManager(stateRepository)
 	interpolator := exeServ.NewInterpolator()
-	fileProcessor := exeServ.NewFileProcessor(fileSystem, interpolator)
 	outputExtractor := exeServ.NewOutputExtractor()
-	commandExecutor := exeServ.NewCommandExecutor(commandRunner, fileProcessor, interpolator, outputExtractor)
+	newCommandExecutor := func() exePrt.CommandExecutor {
+		fileProcessor := exeServ.NewFileProcessor(fileSystem, interpolator)
+		return exeServ.NewCommandExecutor(commandRunner, fileProcessor, interpolator, outputExtractor)
+	}
 	variableResolver := exeServ.NewVariableResolver(interpolator)
-	stepExecutor := exeServ.NewStepExecutor(commandExecutor, variableResolver)
+	stepExecutor := exeServ.NewStepExecutor(newCommandExecutor, variableResolver)
+	provenanceService := applic.NewProvenanceService(
+		f.pathAppProject,
+		f.pathAppVex,
+		projectService,
+		workspaceService,
+		gitRepository,
+		fingerprintService,
+		attestationStore,
+	)
 
 	orchestrator := applic.NewExecutionOrchestrator(
 		f.pathAppProject,
 		f.pathAppVex,
 		projectService,
 		workspaceService,
-		gitClonerTemplate,
+		templateService,
 		versionCalculator,
 		planBuilder,
 		fingerprintService,
 		stateManager,
+		iState.NewFileManifestKeyRepository(),
 		stepExecutor,
 		copyWorkdir,
 		varsRepository,
 		gitRepository,
+		releaseRepository,
+		provenanceService,
+		applic.NewLoggerService(
+			iLgRep.NewFileLoggerRepository(f.pathAppVex), projectRepository, iLgSer.NewConsolePresenterService()),
+		f.toolVersion,
 	)
 	return orchestrator, nil
 }
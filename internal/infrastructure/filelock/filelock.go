@@ -0,0 +1,35 @@
+// Package filelock serializa entre procesos las escrituras de un archivo con un
+// bloqueo del sistema sobre el archivo '<ruta>.lock', a su lado.
+package filelock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Suffix es la extensión del archivo de bloqueo. Quien liste el directorio debe ignorarlo.
+const Suffix = ".lock"
+
+// Lock espera a que nadie más tenga bloqueado filePath, lo bloquea y devuelve la
+// función que lo libera. Protege también frente a otros procesos de vex, p. ej. un
+// 'vex vars set' durante una ejecución.
+func Lock(filePath string) (func(), error) {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("no se pudo crear el directorio '%s': %w", dir, err)
+	}
+	file, err := os.OpenFile(filePath+Suffix, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir el bloqueo de '%s': %w", filePath, err)
+	}
+	if err := lockFile(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("no se pudo bloquear '%s': %w", filePath, err)
+	}
+	return func() {
+		// Cerrar el archivo también libera el bloqueo si unlockFile fallara.
+		_ = unlockFile(file)
+		file.Close()
+	}, nil
+}
@@ -9,5 +9,6 @@ type StepDTO struct {
 	EndTime time.Time `yaml:"end_time,omitempty"`
 	Reason string `yaml:"reason,omitempty"`
 	Tasks []TaskDTO `yaml:"tasks"`
+	Hooks []TaskDTO `yaml:"hooks,omitempty"`
 	Err string `yaml:"err,omitempty"`
 }
\ No newline at end of file
@@ -13,6 +13,10 @@ func StepToDTO(step *entities.StepRecord) dto.StepDTO {
 	for _, task := range step.Tasks() {
 		tasks = append(tasks, TaskToDTO(task))
 	}
+	hooks := make([]dto.TaskDTO, 0, len(step.Hooks()))
+	for _, hook := range step.Hooks() {
+		hooks = append(hooks, TaskToDTO(hook))
+	}
 	errString := ""
 	if step.Error() != nil {
 		errString = step.Error().Error()
@@ -24,6 +28,7 @@ func StepToDTO(step *entities.StepRecord) dto.StepDTO {
 		EndTime:   step.EndTime(),
 		Reason:    step.Reason(),
 		Tasks:     tasks,
+		Hooks:     hooks,
 		Err:       errString,
 	}
 }
@@ -38,6 +43,15 @@ func StepToDomain(stepDTO dto.StepDTO) (*entities.StepRecord, error) {
 		tasks = append(tasks, task)
 	}
 
+	hooks := make([]*entities.TaskRecord, 0, len(stepDTO.Hooks))
+	for _, hookDTO := range stepDTO.Hooks {
+		hook, err := TaskToDomain(hookDTO)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
 	status, err := vos.NewStatusFromString(stepDTO.Status)
 	if err != nil {
 		return nil, err
@@ -49,5 +63,6 @@ func StepToDomain(stepDTO dto.StepDTO) (*entities.StepRecord, error) {
 		stepDTO.EndTime,
 		stepDTO.Reason,
 		tasks,
+		hooks,
 		errors.New(stepDTO.Err))
 }
@@ -27,7 +27,7 @@ func NewFileLoggerRepository(
 }
 
 func (r *FileLoggerRepository) getPathFile(namesParams appDto.NamesParams) (string, error) {
-	pathLogger := filepath.Join(r.pathStateRoot, namesParams.ProjectName(), namesParams.RepositoryName(), "logs")
+	pathLogger := filepath.Join(r.pathStateRoot, namesParams.ProjectName(), namesParams.RepositoryName(), "logs", namesParams.Environment())
 	if err := os.MkdirAll(pathLogger, 0755); err != nil {
 		return "", err
 	}
@@ -111,6 +111,23 @@ func (p *ConsolePresenterService) Task(task *entities.TaskRecord, step *entities
 	}
 }
 
+// Hook muestra un comando de un hook del paso con su salida. El nombre de la tarea
+// es el tipo de hook: on_failure, finally o rollback.
+func (p *ConsolePresenterService) Hook(hook *entities.TaskRecord, step *entities.StepRecord) {
+	result := p.success
+	if hook.Status() == vos.Failure {
+		result = p.failure
+	}
+	result.Fprintf(p.writer, "<%s>: <HOOK %s> (%s) %s\n", strings.ToUpper(step.Name()),
+		strings.ToUpper(hook.Name()), strings.ToUpper(hook.Status().String()), hook.Command())
+	if output := hook.OutputString(); output != "" {
+		p.subtle.Fprint(p.writer, output)
+	}
+	if hook.Error() != nil {
+		p.errorBody.Fprintf(p.writer, "%s\n", hook.Error().Error())
+	}
+}
+
 func (p *ConsolePresenterService) FinalSummary(log *aggregates.Logger) {
 	faileds := []failedInfo{}
 	for _, step := range log.Steps() {
@@ -1,6 +1,13 @@
 package dto
 
 type FdConfigDTO struct {
-	Project  ProjectDTO  `yaml:"project"`
-	Template TemplateDTO `yaml:"template"`
-}
\ No newline at end of file
+	Project  ProjectDTO      `yaml:"project"`
+	Template TemplateListDTO `yaml:"template"`
+	// Variables agrupa las variables del proyecto por entorno, paso y nombre.
+	Variables map[string]map[string]map[string]interface{} `yaml:"variables,omitempty"`
+}
+
+// LocalConfigDTO es vexconfig.local.yaml: solo se lee su sección de variables.
+type LocalConfigDTO struct {
+	Variables map[string]map[string]map[string]interface{} `yaml:"variables"`
+}
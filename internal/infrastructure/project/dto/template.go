@@ -1,6 +1,45 @@
 package dto
 
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
 type TemplateDTO struct {
 	URL string `yaml:"url"`
 	Ref string `yaml:"ref"`
-}
\ No newline at end of file
+}
+
+// TemplateListDTO es la sección 'template' de vexconfig.yaml. Acepta una única
+// plantilla (un mapa) o una lista ordenada: la plantilla base seguida de sus overlays.
+type TemplateListDTO []TemplateDTO
+
+func (t *TemplateListDTO) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		var template TemplateDTO
+		if err := node.Decode(&template); err != nil {
+			return err
+		}
+		*t = TemplateListDTO{template}
+		return nil
+	case yaml.SequenceNode:
+		var templates []TemplateDTO
+		if err := node.Decode(&templates); err != nil {
+			return err
+		}
+		*t = templates
+		return nil
+	default:
+		return fmt.Errorf("formato de plantilla no soportado en la línea %d", node.Line)
+	}
+}
+
+// MarshalYAML conserva el formato de una única plantilla cuando no hay overlays.
+func (t TemplateListDTO) MarshalYAML() (interface{}, error) {
+	if len(t) == 1 {
+		return t[0], nil
+	}
+	return []TemplateDTO(t), nil
+}
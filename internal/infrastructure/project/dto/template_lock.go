@@ -0,0 +1,11 @@
+package dto
+
+type TemplateLockFileDTO struct {
+	Templates []TemplateLockDTO `yaml:"templates"`
+}
+
+type TemplateLockDTO struct {
+	URL    string `yaml:"url"`
+	Ref    string `yaml:"ref"`
+	Commit string `yaml:"commit"`
+}
@@ -0,0 +1,75 @@
+package project
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// Variables de entorno con las credenciales para el repositorio de plantillas.
+const (
+	envGitToken       = "VEX_GIT_TOKEN"
+	envGitUsername    = "VEX_GIT_USERNAME"
+	envGitSSHKey      = "VEX_GIT_SSH_KEY"
+	envGitSSHPassword = "VEX_GIT_SSH_KEY_PASSWORD"
+
+	defaultTokenUsername = "x-access-token"
+	defaultSSHUser       = "git"
+)
+
+// authFromEnv elige el método de autenticación según el protocolo de la URL:
+//   - https: token de VEX_GIT_TOKEN (usuario en VEX_GIT_USERNAME), o sin credenciales.
+//   - ssh: clave de VEX_GIT_SSH_KEY o, si no se indica, el ssh-agent. El host se
+//     valida contra known_hosts (SSH_KNOWN_HOSTS o ~/.ssh/known_hosts).
+//   - file: sin credenciales.
+func authFromEnv(repoURL string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("URL de repositorio inválida '%s': %w", repoURL, err)
+	}
+
+	switch endpoint.Protocol {
+	case "http", "https":
+		token := os.Getenv(envGitToken)
+		if token == "" {
+			return nil, nil
+		}
+		username := os.Getenv(envGitUsername)
+		if username == "" {
+			username = defaultTokenUsername
+		}
+		return &githttp.BasicAuth{Username: username, Password: token}, nil
+
+	case "ssh":
+		user := endpoint.User
+		if user == "" {
+			user = defaultSSHUser
+		}
+		hostKeyCallback, err := gitssh.NewKnownHostsCallback()
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo cargar known_hosts: %w", err)
+		}
+
+		if keyPath := os.Getenv(envGitSSHKey); keyPath != "" {
+			auth, err := gitssh.NewPublicKeysFromFile(user, keyPath, os.Getenv(envGitSSHPassword))
+			if err != nil {
+				return nil, fmt.Errorf("no se pudo leer la clave ssh '%s': %w", keyPath, err)
+			}
+			auth.HostKeyCallback = hostKeyCallback
+			return auth, nil
+		}
+
+		auth, err := gitssh.NewSSHAgentAuth(user)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo conectar con el ssh-agent: %w", err)
+		}
+		auth.HostKeyCallback = hostKeyCallback
+		return auth, nil
+
+	default:
+		return nil, nil
+	}
+}
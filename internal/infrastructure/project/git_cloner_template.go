@@ -2,20 +2,58 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 
 	"github.com/jairoprogramador/vex/internal/domain/project/ports"
 )
 
+// GitClonerTemplate clona y actualiza los repositorios de plantillas con go-git,
+// sin depender de un binario de git instalado.
 type GitClonerTemplate struct {
+	shallow bool
+	auth    func(repoURL string) (transport.AuthMethod, error)
+}
+
+type ClonerOption func(*GitClonerTemplate)
+
+// lockedCommitRef conserva el último commit bloqueado que se pidió por su SHA.
+const lockedCommitRef = "refs/vex/locked"
+
+// templateRefSpecs trae las ramas del remoto como ramas remotas y todos sus tags.
+var templateRefSpecs = []config.RefSpec{
+	"+refs/heads/*:refs/remotes/origin/*",
+	"+refs/tags/*:refs/tags/*",
 }
 
-func NewGitClonerTemplate() ports.ClonerTemplate {
-	return &GitClonerTemplate{}
+// unshallowDepth es la profundidad con la que 'git fetch --unshallow' completa la
+// historia de un clon superficial.
+const unshallowDepth = 2147483647
+
+// WithShallowClone trae solo el último commit de la referencia. Las referencias que
+// son un SHA se clonan siempre completas, porque no se pueden pedir por nombre.
+func WithShallowClone(shallow bool) ClonerOption {
+	return func(c *GitClonerTemplate) {
+		c.shallow = shallow
+	}
+}
+
+func NewGitClonerTemplate(opts ...ClonerOption) ports.ClonerTemplate {
+	cloner := &GitClonerTemplate{auth: authFromEnv}
+	for _, opt := range opts {
+		opt(cloner)
+	}
+	return cloner
 }
 
 func isGitRepository(path string) (bool, error) {
@@ -29,54 +67,426 @@ func isGitRepository(path string) (bool, error) {
 	return false, err
 }
 
-func (c *GitClonerTemplate) EnsureCloned(ctx context.Context, repoURL, ref, localPath string) error {
-	if _, err := os.Stat(localPath); err == nil {
-		isGit, err := isGitRepository(localPath)
-		if err != nil {
-			return fmt.Errorf("no se pudo verificar si la ruta es un repositorio git: %w", err)
+func isEmptyOrMissingDir(path string) (bool, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return len(entries) == 0, nil
+}
+
+func (c *GitClonerTemplate) EnsureCloned(ctx context.Context, repoURL, ref, barePath, localPath string) error {
+	isGit, err := isGitRepository(localPath)
+	if err != nil {
+		return fmt.Errorf("no se pudo verificar si la ruta es un repositorio git: %w", err)
+	}
+	if isGit {
+		return nil
+	}
+
+	isEmpty, err := isEmptyOrMissingDir(localPath)
+	if err != nil {
+		return fmt.Errorf("no se pudo leer la ruta '%s': %w", localPath, err)
+	}
+	if !isEmpty {
+		return &ports.CloneError{URL: repoURL, Ref: ref, Kind: ports.ErrTemplatePathNotRepository}
+	}
+
+	auth, err := c.auth(repoURL)
+	if err != nil {
+		return &ports.CloneError{URL: repoURL, Ref: ref, Kind: ports.ErrTemplateAuthentication, Cause: err}
+	}
+
+	bare, created, err := c.openOrCloneBare(ctx, repoURL, ref, barePath, auth)
+	if err != nil {
+		return cloneError(repoURL, ref, err)
+	}
+
+	hash, err := resolveRef(bare, ref)
+	if err != nil && !created {
+		// El clon bare ya existía: la referencia puede ser posterior al último fetch.
+		if err = c.fetch(ctx, bare, repoURL, auth); err == nil {
+			hash, err = resolveRef(bare, ref)
+		}
+	}
+	if err != nil {
+		if created {
+			os.RemoveAll(barePath)
+		}
+		return cloneError(repoURL, ref, err)
+	}
+
+	if err := addWorktree(barePath, localPath, hash); err != nil {
+		return fmt.Errorf("no se pudo crear el árbol de trabajo de la plantilla en '%s': %w", localPath, err)
+	}
+	return nil
+}
+
+// openOrCloneBare abre el clon bare del repositorio o lo crea si aún no existe.
+// created indica si se acaba de crear, para poder descartarlo si la referencia no existe.
+func (c *GitClonerTemplate) openOrCloneBare(
+	ctx context.Context, repoURL, ref, barePath string, auth transport.AuthMethod) (*git.Repository, bool, error) {
+
+	repo, err := git.PlainOpen(barePath)
+	if err == nil {
+		return repo, false, nil
+	}
+	if !errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, false, err
+	}
+
+	repo, err = c.cloneBare(ctx, repoURL, ref, barePath, auth)
+	if err != nil {
+		os.RemoveAll(barePath)
+		return nil, false, err
+	}
+	return repo, true, nil
+}
+
+// cloneBare intenta primero un clon superficial de la rama o el tag, si está habilitado,
+// y recurre a un clon completo. La referencia se resuelve después.
+func (c *GitClonerTemplate) cloneBare(
+	ctx context.Context, repoURL, ref, barePath string, auth transport.AuthMethod) (*git.Repository, error) {
+
+	// El clon no depende del HEAD del remoto, que puede apuntar a una rama
+	// inexistente: se traen las ramas y tags y luego se resuelve ref.
+	repo, err := git.PlainInit(barePath, true)
+	if err != nil {
+		return nil, err
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{repoURL}})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.shallow {
+		for _, refSpec := range []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", ref, git.DefaultRemoteName, ref)),
+			config.RefSpec(fmt.Sprintf("+refs/tags/%s:refs/tags/%s", ref, ref)),
+		} {
+			err = repo.FetchContext(ctx, &git.FetchOptions{
+				RemoteName: git.DefaultRemoteName,
+				RefSpecs:   []config.RefSpec{refSpec},
+				Auth:       auth,
+				Depth:      1,
+				Force:      true,
+			})
+			if err == nil {
+				return repo, nil
+			}
+			if !isRefNotFound(err) {
+				return nil, err
+			}
+		}
+	}
+
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   templateRefSpecs,
+		Auth:       auth,
+		Force:      true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// addWorktree crea en localPath un árbol de trabajo enlazado al clon bare, con la
+// misma estructura que 'git worktree add': el HEAD y el índice son propios y los
+// objetos, las referencias y la configuración se comparten.
+func addWorktree(barePath, localPath string, hash plumbing.Hash) error {
+	absLocalPath, err := filepath.Abs(localPath)
+	if err != nil {
+		return err
+	}
+	absBarePath, err := filepath.Abs(barePath)
+	if err != nil {
+		return err
+	}
+
+	adminDir := filepath.Join(absBarePath, "worktrees", filepath.Base(absLocalPath))
+	files := map[string]string{
+		filepath.Join(adminDir, "commondir"):        filepath.Join("..", ".."),
+		filepath.Join(adminDir, "gitdir"):           filepath.Join(absLocalPath, git.GitDirName),
+		filepath.Join(adminDir, "HEAD"):             hash.String(),
+		filepath.Join(absLocalPath, git.GitDirName): "gitdir: " + adminDir,
+	}
+	for _, dir := range []string{adminDir, absLocalPath} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content+"\n"), 0644); err != nil {
+			return err
 		}
+	}
+
+	repo, err := openRepository(absLocalPath)
+	if err == nil {
+		err = checkout(repo, hash)
+	}
+	if err != nil {
+		os.RemoveAll(adminDir)
+		os.RemoveAll(absLocalPath)
+		return err
+	}
+	return nil
+}
 
-		if isGit {
+// openRepository abre un árbol de trabajo enlazado a su clon bare.
+func openRepository(localPath string) (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(localPath, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir el repositorio de plantillas '%s': %w", localPath, err)
+	}
+	return repo, nil
+}
+
+func (c *GitClonerTemplate) Fetch(ctx context.Context, localPath string) error {
+	repo, repoURL, auth, err := c.openRemote(localPath)
+	if err != nil {
+		return err
+	}
+	if err := c.fetch(ctx, repo, repoURL, auth); err != nil {
+		return cloneError(repoURL, "", err)
+	}
+	return nil
+}
+
+// FetchCommit trae un commit que puede no estar en la punta de ninguna rama ni tag.
+// En un clon superficial se pide por su SHA; si el servidor no lo permite o no lo
+// encuentra, se completa la historia, como hace 'git fetch --unshallow'.
+func (c *GitClonerTemplate) FetchCommit(ctx context.Context, localPath, commit string) error {
+	if !plumbing.IsHash(commit) {
+		return fmt.Errorf("'%s' no es un commit válido", commit)
+	}
+	repo, repoURL, auth, err := c.openRemote(localPath)
+	if err != nil {
+		return err
+	}
+	if !c.shallow {
+		if err := c.fetch(ctx, repo, repoURL, auth); err != nil {
+			return cloneError(repoURL, commit, err)
+		}
+		return nil
+	}
+
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", commit, lockedCommitRef))},
+		Auth:       auth,
+		Depth:      1,
+		Force:      true,
+	})
+	if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) {
+		if _, err := repo.CommitObject(plumbing.NewHash(commit)); err == nil {
 			return nil
 		}
 	}
 
-	command := fmt.Sprintf("git clone --branch %s %s %s", ref, repoURL, localPath)
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   templateRefSpecs,
+		Auth:       auth,
+		Depth:      unshallowDepth,
+		Force:      true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return cloneError(repoURL, commit, err)
+	}
+	if _, err := repo.CommitObject(plumbing.NewHash(commit)); err != nil {
+		return &ports.CloneError{URL: repoURL, Ref: commit, Kind: ports.ErrTemplateRefNotFound, Cause: err}
+	}
+	return nil
+}
 
-	res, err := c.Run(ctx, command, "")
+// openRemote abre el árbol de trabajo y obtiene la URL y las credenciales de su remoto.
+func (c *GitClonerTemplate) openRemote(localPath string) (*git.Repository, string, transport.AuthMethod, error) {
+	repo, err := openRepository(localPath)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	remote, err := repo.Remote(git.DefaultRemoteName)
 	if err != nil {
-		return fmt.Errorf("no se pudo iniciar el comando de clonación: %w", err)
+		return nil, "", nil, fmt.Errorf("el repositorio de plantillas no tiene el remoto '%s': %w", git.DefaultRemoteName, err)
 	}
-	if res.ExitCode != 0 {
-		return fmt.Errorf("falló la clonación del repositorio '%s' (código %d): %s", repoURL, res.ExitCode, res.Output)
+	repoURL := remote.Config().URLs[0]
+
+	auth, err := c.auth(repoURL)
+	if err != nil {
+		return nil, "", nil, &ports.CloneError{URL: repoURL, Kind: ports.ErrTemplateAuthentication, Cause: err}
 	}
+	return repo, repoURL, auth, nil
+}
+
+func (c *GitClonerTemplate) fetch(
+	ctx context.Context, repo *git.Repository, repoURL string, auth transport.AuthMethod) error {
 
+	options := &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   templateRefSpecs,
+		Auth:       auth,
+		Force:      true,
+		Prune:      true,
+	}
+	if c.shallow {
+		options.Depth = 1
+	}
+	err := repo.FetchContext(ctx, options)
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err
+	}
 	return nil
 }
 
-func (r *GitClonerTemplate) Run(ctx context.Context, command string, workDir string) (*ports.CommandResultDTO, error) {
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
-	} else {
-		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+func (c *GitClonerTemplate) ResolveRef(ctx context.Context, localPath, ref string) (string, error) {
+	repo, err := openRepository(localPath)
+	if err != nil {
+		return "", err
+	}
+	hash, err := resolveRef(repo, ref)
+	if err != nil {
+		return "", &ports.CloneError{Ref: ref, Kind: ports.ErrTemplateRefNotFound, Cause: err}
 	}
-	cmd.Dir = workDir
+	return hash.String(), nil
+}
 
-	output, err := cmd.CombinedOutput()
+func (c *GitClonerTemplate) HasCommit(ctx context.Context, localPath, commit string) bool {
+	if !plumbing.IsHash(commit) {
+		return false
+	}
+	repo, err := openRepository(localPath)
+	if err != nil {
+		return false
+	}
+	_, err = repo.CommitObject(plumbing.NewHash(commit))
+	return err == nil
+}
 
-	result := &ports.CommandResultDTO{
-		Output:   string(output),
-		ExitCode: 0,
+func (c *GitClonerTemplate) Checkout(ctx context.Context, localPath, commit string) error {
+	if !plumbing.IsHash(commit) {
+		return fmt.Errorf("'%s' no es un commit válido", commit)
+	}
+	repo, err := openRepository(localPath)
+	if err != nil {
+		return err
 	}
+	return checkout(repo, plumbing.NewHash(commit))
+}
 
+func (c *GitClonerTemplate) CommitRange(ctx context.Context, localPath, fromCommit, toCommit string) ([]string, error) {
+	repo, err := openRepository(localPath)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitErr.ExitCode()
-			return result, nil
-		}
 		return nil, err
 	}
 
-	return result, nil
+	known := make(map[plumbing.Hash]struct{})
+	err = walkHistory(repo, plumbing.NewHash(fromCommit), func(commit *object.Commit) error {
+		known[commit.Hash] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo recorrer la historia desde '%s': %w", fromCommit, err)
+	}
+
+	summaries := make([]string, 0)
+	err = walkHistory(repo, plumbing.NewHash(toCommit), func(commit *object.Commit) error {
+		if _, isKnown := known[commit.Hash]; isKnown {
+			return nil
+		}
+		title := strings.SplitN(strings.TrimSpace(commit.Message), "\n", 2)[0]
+		summaries = append(summaries, fmt.Sprintf("%s %s", commit.Hash.String()[:7], title))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo recorrer la historia desde '%s': %w", toCommit, err)
+	}
+	return summaries, nil
+}
+
+// walkHistory recorre los commits alcanzables desde from. En un clon superficial
+// la historia termina donde faltan los padres.
+func walkHistory(repo *git.Repository, from plumbing.Hash, visit func(*object.Commit) error) error {
+	iter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return err
+	}
+	err = iter.ForEach(visit)
+	if err != nil && !errors.Is(err, plumbing.ErrObjectNotFound) && !errors.Is(err, storer.ErrStop) {
+		return err
+	}
+	return nil
+}
+
+// resolveRef busca la referencia como rama remota, tag, rama local y, por último,
+// como revisión (SHA completo o abreviado).
+func resolveRef(repo *git.Repository, ref string) (plumbing.Hash, error) {
+	candidates := []plumbing.ReferenceName{
+		plumbing.NewRemoteReferenceName(git.DefaultRemoteName, ref),
+		plumbing.NewTagReferenceName(ref),
+		plumbing.NewBranchReferenceName(ref),
+	}
+	for _, name := range candidates {
+		reference, err := repo.Reference(name, true)
+		if err == nil {
+			return peelToCommit(repo, reference.Hash())
+		}
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("%w: %s", plumbing.ErrReferenceNotFound, ref)
+	}
+	return peelToCommit(repo, *hash)
+}
+
+// peelToCommit devuelve el commit al que apunta un hash, siguiendo los tags anotados.
+func peelToCommit(repo *git.Repository, hash plumbing.Hash) (plumbing.Hash, error) {
+	if _, err := repo.CommitObject(hash); err == nil {
+		return hash, nil
+	}
+	tag, err := repo.TagObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("'%s' no apunta a un commit: %w", hash.String(), err)
+	}
+	commit, err := tag.Commit()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("el tag '%s' no apunta a un commit: %w", tag.Name, err)
+	}
+	return commit.Hash, nil
+}
+
+func checkout(repo *git.Repository, hash plumbing.Hash) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("no se pudo obtener el árbol de trabajo de la plantilla: %w", err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: hash, Force: true}); err != nil {
+		return fmt.Errorf("no se pudo cambiar la plantilla al commit '%s': %w", hash.String(), err)
+	}
+	return nil
+}
+
+func isRefNotFound(err error) bool {
+	var noMatch git.NoMatchingRefSpecError
+	return errors.Is(err, plumbing.ErrReferenceNotFound) || errors.As(err, &noMatch)
+}
+
+// cloneError traduce los errores de go-git a las causas tipadas del dominio.
+func cloneError(repoURL, ref string, err error) error {
+	kind := error(nil)
+	switch {
+	case errors.Is(err, transport.ErrRepositoryNotFound), errors.Is(err, transport.ErrEmptyRemoteRepository):
+		kind = ports.ErrTemplateRepositoryNotFound
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		kind = ports.ErrTemplateAuthentication
+	case isRefNotFound(err):
+		kind = ports.ErrTemplateRefNotFound
+	}
+	return &ports.CloneError{URL: repoURL, Ref: ref, Kind: kind, Cause: err}
 }
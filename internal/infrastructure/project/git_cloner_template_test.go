@@ -2,150 +2,288 @@ package project_test
 
 import (
 	"context"
+	"errors"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jairoprogramador/vex/internal/domain/project/ports"
 	"github.com/jairoprogramador/vex/internal/infrastructure/project"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// setupTestRepo creates a bare git repository in a temporary directory to act as a remote.
-func setupTestRepo(t *testing.T) string {
+// testRemote es un repositorio bare que actúa como remoto, junto con el clon
+// de trabajo desde el que se publican los commits.
+type testRemote struct {
+	t        *testing.T
+	path     string
+	workDir  string
+	workRepo *git.Repository
+}
+
+// setupTestRepo crea un repositorio bare con una rama 'develop' y un tag anotado 'v1.0.0'.
+func setupTestRepo(t *testing.T) *testRemote {
 	t.Helper()
 
 	remoteDir := t.TempDir()
-	tempCloneDir := t.TempDir()
-
-	// Helper function to run commands in a specific directory.
-	runCmd := func(dir string, name string, args ...string) {
-		t.Helper()
-		cmd := exec.Command(name, args...)
-		cmd.Dir = dir
-		err := cmd.Run()
-		require.NoError(t, err, "Command failed: %s %v", name, args)
-	}
+	_, err := git.PlainInit(remoteDir, true)
+	require.NoError(t, err)
 
-	// 1. Initialize bare remote repository
-	runCmd(remoteDir, "git", "init", "--bare")
-	runCmd(remoteDir, "git", "symbolic-ref", "HEAD", "refs/heads/develop")
+	workDir := t.TempDir()
+	workRepo, err := git.PlainInit(workDir, false)
+	require.NoError(t, err)
+	_, err = workRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}})
+	require.NoError(t, err)
 
-	// 2. Clone the bare repo to a temporary working directory
-	runCmd("", "git", "clone", remoteDir, tempCloneDir)
+	remote := &testRemote{t: t, path: remoteDir, workDir: workDir, workRepo: workRepo}
+	first := remote.commit("chore: initial commit", "initial commit")
 
-	// 3. Configure the temporary repo, create and commit a file
-	runCmd(tempCloneDir, "git", "config", "user.email", "test@example.com")
-	runCmd(tempCloneDir, "git", "config", "user.name", "Test User")
-	runCmd(tempCloneDir, "git", "checkout", "-b", "develop")
-	err := os.WriteFile(filepath.Join(tempCloneDir, "README.md"), []byte("initial commit"), 0644)
+	err = workRepo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("develop"), first))
+	require.NoError(t, err)
+	_, err = workRepo.CreateTag("v1.0.0", first, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+		Message: "release v1.0.0",
+	})
 	require.NoError(t, err)
-	runCmd(tempCloneDir, "git", "add", "README.md")
-	runCmd(tempCloneDir, "git", "commit", "-m", "chore: initial commit")
+	remote.push()
 
-	// 4. Push the new branch to the bare "remote"
-	runCmd(tempCloneDir, "git", "push", "-u", "origin", "develop")
+	return remote
+}
 
-	return remoteDir
+func (r *testRemote) commit(message, content string) plumbing.Hash {
+	r.t.Helper()
+	worktree, err := r.workRepo.Worktree()
+	require.NoError(r.t, err)
+	require.NoError(r.t, os.WriteFile(filepath.Join(r.workDir, "README.md"), []byte(content), 0644))
+	_, err = worktree.Add("README.md")
+	require.NoError(r.t, err)
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(r.t, err)
+	return hash
+}
+
+// publish añade un commit a 'develop' y lo publica en el remoto.
+func (r *testRemote) publish(message, content string) plumbing.Hash {
+	r.t.Helper()
+	hash := r.commit(message, content)
+	err := r.workRepo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("develop"), hash))
+	require.NoError(r.t, err)
+	r.push()
+	return hash
+}
+
+func (r *testRemote) push() {
+	r.t.Helper()
+	err := r.workRepo.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{"+refs/heads/develop:refs/heads/develop", "+refs/tags/*:refs/tags/*"},
+	})
+	if !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		require.NoError(r.t, err)
+	}
+}
+
+// barePath devuelve una ruta nueva para el clon bare compartido.
+func barePath(t *testing.T) string {
+	t.Helper()
+	return filepath.Join(t.TempDir(), "template.git")
+}
+
+func readReadme(t *testing.T, clonePath string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(clonePath, "README.md"))
+	require.NoError(t, err)
+	return string(content)
 }
 
 func TestGitClonerTemplate_EnsureCloned(t *testing.T) {
-	remoteRepoPath := setupTestRepo(t)
+	remote := setupTestRepo(t)
 	cloner := project.NewGitClonerTemplate()
+	ctx := context.Background()
 
 	t.Run("should clone repository successfully when it does not exist", func(t *testing.T) {
-		// Arrange
-		destDir := t.TempDir()
-		clonePath := filepath.Join(destDir, "test-repo")
+		clonePath := filepath.Join(t.TempDir(), "test-repo")
 
-		// Act
-		err := cloner.EnsureCloned(context.Background(), remoteRepoPath, "develop", clonePath)
+		err := cloner.EnsureCloned(ctx, remote.path, "develop", barePath(t), clonePath)
 
-		// Assert
 		require.NoError(t, err)
-		// Verify that it is a git repo
 		_, err = os.Stat(filepath.Join(clonePath, ".git"))
 		assert.NoError(t, err, ".git directory should exist after clone")
+		assert.Equal(t, "initial commit", readReadme(t, clonePath))
+	})
+
+	t.Run("should clone at a tag and at a commit SHA", func(t *testing.T) {
+		tagPath := filepath.Join(t.TempDir(), "by-tag")
+		require.NoError(t, cloner.EnsureCloned(ctx, remote.path, "v1.0.0", barePath(t), tagPath))
+		tagCommit, err := cloner.ResolveRef(ctx, tagPath, "v1.0.0")
+		require.NoError(t, err)
+
+		shaPath := filepath.Join(t.TempDir(), "by-sha")
+		require.NoError(t, cloner.EnsureCloned(ctx, remote.path, tagCommit, barePath(t), shaPath))
+		shaCommit, err := cloner.ResolveRef(ctx, shaPath, tagCommit)
+		require.NoError(t, err)
+		assert.Equal(t, tagCommit, shaCommit)
+	})
+
+	t.Run("should make a shallow clone when enabled", func(t *testing.T) {
+		shallowCloner := project.NewGitClonerTemplate(project.WithShallowClone(true))
+		clonePath := filepath.Join(t.TempDir(), "shallow")
+		bare := barePath(t)
+
+		require.NoError(t, shallowCloner.EnsureCloned(ctx, remote.path, "develop", bare, clonePath))
+		_, err := os.Stat(filepath.Join(bare, "shallow"))
+		assert.NoError(t, err, "a shallow clone should record its boundary")
+	})
+
+	t.Run("should share one bare clone between the worktrees of each ref", func(t *testing.T) {
+		bare := barePath(t)
+		developPath := filepath.Join(t.TempDir(), "develop")
+		tagPath := filepath.Join(t.TempDir(), "v1")
+
+		require.NoError(t, cloner.EnsureCloned(ctx, remote.path, "develop", bare, developPath))
+		require.NoError(t, cloner.EnsureCloned(ctx, remote.path, "v1.0.0", bare, tagPath))
+		second := remote.publish("feat: second commit", "second commit")
+
+		require.NoError(t, cloner.Fetch(ctx, tagPath))
+		assert.True(t, cloner.HasCommit(ctx, developPath, second.String()), "a fetch from one worktree should be visible to every worktree")
+		require.NoError(t, cloner.Checkout(ctx, developPath, second.String()))
+		assert.Equal(t, "second commit", readReadme(t, developPath))
+		assert.Equal(t, "initial commit", readReadme(t, tagPath))
+
+		worktrees, err := os.ReadDir(filepath.Join(bare, "worktrees"))
+		require.NoError(t, err)
+		assert.Len(t, worktrees, 2)
 	})
 
 	t.Run("should do nothing if repository already exists and is a git repo", func(t *testing.T) {
-		// Arrange
-		destDir := t.TempDir()
-		// Manually clone it first, specifying the develop branch
-		cmd := exec.Command("git", "clone", "--branch", "develop", remoteRepoPath, destDir)
-		err := cmd.Run()
-		require.NoError(t, err, "Pre-cloning for test failed")
+		clonePath := filepath.Join(t.TempDir(), "existing")
+		require.NoError(t, cloner.EnsureCloned(ctx, remote.path, "develop", barePath(t), clonePath))
 
-		// Act
-		err = cloner.EnsureCloned(context.Background(), remoteRepoPath, "develop", destDir)
+		err := cloner.EnsureCloned(ctx, remote.path, "develop", barePath(t), clonePath)
 
-		// Assert
 		require.NoError(t, err)
-		// We can't easily assert "nothing happened", but we can be sure no error was thrown.
 	})
 
-	t.Run("should return an error if destination exists but is not a git repository", func(t *testing.T) {
-		// Arrange
+	t.Run("should clone into an existing empty directory", func(t *testing.T) {
 		destDir := t.TempDir()
-		// The directory is empty, so it's not a git repo.
 
-		// Act
-		err := cloner.EnsureCloned(context.Background(), remoteRepoPath, "develop", destDir)
+		err := cloner.EnsureCloned(ctx, remote.path, "develop", barePath(t), destDir)
 
-		// Assert
-		// The current implementation doesn't error out, it just proceeds to clone.
-		// Let's check if the clone was successful instead.
 		require.NoError(t, err)
 		_, statErr := os.Stat(filepath.Join(destDir, ".git"))
 		assert.NoError(t, statErr, ".git should exist after cloning into an empty dir")
 	})
 
-	t.Run("should return an error for an invalid repository URL", func(t *testing.T) {
-		// Arrange
+	t.Run("should return a typed error if destination is a non-empty directory", func(t *testing.T) {
 		destDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(destDir, "file.txt"), []byte("x"), 0644))
+
+		err := cloner.EnsureCloned(ctx, remote.path, "develop", barePath(t), destDir)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ports.ErrTemplatePathNotRepository)
+	})
 
-		// Act
-		// Try to clone a non-existent branch to trigger a different kind of error
-		err := cloner.EnsureCloned(context.Background(), remoteRepoPath, "non-existent-branch", destDir)
+	t.Run("should return a typed error for a non-existent ref", func(t *testing.T) {
+		clonePath := filepath.Join(t.TempDir(), "missing-ref")
+
+		err := cloner.EnsureCloned(ctx, remote.path, "non-existent-branch", barePath(t), clonePath)
 
-		// Assert
 		require.Error(t, err)
+		assert.ErrorIs(t, err, ports.ErrTemplateRefNotFound)
 		assert.Contains(t, err.Error(), "falló la clonación del repositorio")
+		_, statErr := os.Stat(clonePath)
+		assert.True(t, os.IsNotExist(statErr), "a failed clone should not leave a partial directory")
+	})
+
+	t.Run("should return a typed error for a non-existent repository", func(t *testing.T) {
+		clonePath := filepath.Join(t.TempDir(), "missing-repo")
+
+		err := cloner.EnsureCloned(ctx, filepath.Join(t.TempDir(), "nope"), "develop", barePath(t), clonePath)
+
+		require.Error(t, err)
+		var cloneErr *ports.CloneError
+		assert.True(t, errors.As(err, &cloneErr))
 	})
 }
 
-func TestGitClonerTemplate_Run(t *testing.T) {
+func TestGitClonerTemplate_FetchAndCheckout(t *testing.T) {
+	remote := setupTestRepo(t)
 	cloner := project.NewGitClonerTemplate()
+	ctx := context.Background()
 
-	t.Run("should execute command successfully", func(t *testing.T) {
-		// Arrange
-		command := "echo 'hello world'"
+	clonePath := filepath.Join(t.TempDir(), "template with spaces")
+	require.NoError(t, cloner.EnsureCloned(ctx, remote.path, "develop", barePath(t), clonePath))
 
-		// Act
-		result, err := cloner.Run(context.Background(), command, "")
+	firstCommit, err := cloner.ResolveRef(ctx, clonePath, "develop")
+	require.NoError(t, err)
+
+	second := remote.publish("fix: second commit", "second commit")
+
+	t.Run("should resolve the new commit only after fetching", func(t *testing.T) {
+		stale, err := cloner.ResolveRef(ctx, clonePath, "develop")
+		require.NoError(t, err)
+		assert.Equal(t, firstCommit, stale)
+		assert.False(t, cloner.HasCommit(ctx, clonePath, second.String()))
 
-		// Assert
+		require.NoError(t, cloner.Fetch(ctx, clonePath))
+		secondCommit, err := cloner.ResolveRef(ctx, clonePath, "develop")
 		require.NoError(t, err)
-		assert.Equal(t, 0, result.ExitCode)
-		assert.Contains(t, result.Output, "hello world")
+		assert.Equal(t, second.String(), secondCommit)
+		assert.True(t, cloner.HasCommit(ctx, clonePath, secondCommit))
+
+		commits, err := cloner.CommitRange(ctx, clonePath, firstCommit, secondCommit)
+		require.NoError(t, err)
+		require.Len(t, commits, 1)
+		assert.Contains(t, commits[0], "fix: second commit")
+
+		require.NoError(t, cloner.Checkout(ctx, clonePath, secondCommit))
+		assert.Equal(t, "second commit", readReadme(t, clonePath))
+
+		require.NoError(t, cloner.Checkout(ctx, clonePath, firstCommit))
+		assert.Equal(t, "initial commit", readReadme(t, clonePath))
 	})
 
-	t.Run("should capture non-zero exit code", func(t *testing.T) {
-		// Arrange
-		// 'exit 123' is a command that will exit with code 123
-		command := "exit 123"
-		if runtime.GOOS == "windows" {
-			// On windows, we can use a small batch script logic
-			command = "cmd /c exit 123"
-		}
+	t.Run("should return an error for an unknown ref", func(t *testing.T) {
+		_, err := cloner.ResolveRef(ctx, clonePath, "does-not-exist")
+		assert.ErrorIs(t, err, ports.ErrTemplateRefNotFound)
+	})
+}
+
+func TestGitClonerTemplate_FetchCommit(t *testing.T) {
+	remote := setupTestRepo(t)
+	ctx := context.Background()
+
+	locked := remote.publish("fix: locked commit", "locked commit")
+	remote.publish("feat: newer commit", "newer commit")
+
+	t.Run("should fetch a locked commit behind the tip of a shallow clone", func(t *testing.T) {
+		cloner := project.NewGitClonerTemplate(project.WithShallowClone(true))
+		clonePath := filepath.Join(t.TempDir(), "shallow")
+		require.NoError(t, cloner.EnsureCloned(ctx, remote.path, "develop", barePath(t), clonePath))
+		require.False(t, cloner.HasCommit(ctx, clonePath, locked.String()))
+
+		require.NoError(t, cloner.FetchCommit(ctx, clonePath, locked.String()))
+		assert.True(t, cloner.HasCommit(ctx, clonePath, locked.String()))
+
+		require.NoError(t, cloner.Checkout(ctx, clonePath, locked.String()))
+		assert.Equal(t, "locked commit", readReadme(t, clonePath))
+	})
 
-		// Act
-		result, err := cloner.Run(context.Background(), command, "")
+	t.Run("should return an error for a commit the remote does not have", func(t *testing.T) {
+		cloner := project.NewGitClonerTemplate(project.WithShallowClone(true))
+		clonePath := filepath.Join(t.TempDir(), "shallow")
+		require.NoError(t, cloner.EnsureCloned(ctx, remote.path, "develop", barePath(t), clonePath))
 
-		// Assert
-		require.NoError(t, err, "Run should not return an error for a command that just exits non-zero")
-		assert.Equal(t, 123, result.ExitCode)
+		err := cloner.FetchCommit(ctx, clonePath, "0123456789abcdef0123456789abcdef01234567")
+		assert.ErrorIs(t, err, ports.ErrTemplateRefNotFound)
 	})
 }
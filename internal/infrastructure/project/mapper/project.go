@@ -6,6 +6,14 @@ import (
 )
 
 func ProjectToDto(data *ports.ProjectConfigDTO) dto.FdConfigDTO {
+	templates := dto.TemplateListDTO{{
+		URL: data.TemplateURL,
+		Ref: data.TemplateRef,
+	}}
+	for _, overlay := range data.TemplateOverlays {
+		templates = append(templates, dto.TemplateDTO{URL: overlay.URL, Ref: overlay.Ref})
+	}
+
 	return dto.FdConfigDTO{
 		Project: dto.ProjectDTO{
 			ID:           data.ID,
@@ -15,22 +23,29 @@ func ProjectToDto(data *ports.ProjectConfigDTO) dto.FdConfigDTO {
 			Description:  data.Description,
 			Version:      data.Version,
 		},
-		Template: dto.TemplateDTO{
-			URL: data.TemplateURL,
-			Ref: data.TemplateRef,
-		},
+		Template:  templates,
+		Variables: data.Variables,
 	}
 }
 
 func ProjectToDomain(fdConfig dto.FdConfigDTO) *ports.ProjectConfigDTO {
-	return &ports.ProjectConfigDTO{
+	config := &ports.ProjectConfigDTO{
 		ID:           fdConfig.Project.ID,
 		Name:         fdConfig.Project.Name,
 		Organization: fdConfig.Project.Organization,
 		Team:         fdConfig.Project.Team,
 		Description:  fdConfig.Project.Description,
 		Version:      fdConfig.Project.Version,
-		TemplateURL:  fdConfig.Template.URL,
-		TemplateRef:  fdConfig.Template.Ref,
+		Variables:    fdConfig.Variables,
+	}
+	if len(fdConfig.Template) == 0 {
+		return config
+	}
+
+	config.TemplateURL = fdConfig.Template[0].URL
+	config.TemplateRef = fdConfig.Template[0].Ref
+	for _, overlay := range fdConfig.Template[1:] {
+		config.TemplateOverlays = append(config.TemplateOverlays, ports.TemplateConfigDTO{URL: overlay.URL, Ref: overlay.Ref})
 	}
+	return config
 }
@@ -32,6 +32,22 @@ func (r *YAMLProjectRepository) Load(ctx context.Context, pathFile string) (*por
 	return mapper.ProjectToDomain(dto), nil
 }
 
+func (r *YAMLProjectRepository) LoadVariables(ctx context.Context, pathFile string) (ports.VariablesConfigDTO, error) {
+	data, err := os.ReadFile(pathFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("no se pudo leer el archivo de variables '%s': %w", pathFile, err)
+	}
+
+	var localDTO dto.LocalConfigDTO
+	if err := yaml.Unmarshal(data, &localDTO); err != nil {
+		return nil, fmt.Errorf("error al parsear el archivo YAML de variables '%s': %w", pathFile, err)
+	}
+	return localDTO.Variables, nil
+}
+
 func (r *YAMLProjectRepository) Save(ctx context.Context, pathFile string, data *ports.ProjectConfigDTO) error {
 	dto := mapper.ProjectToDto(data)
 
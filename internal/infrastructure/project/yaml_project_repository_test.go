@@ -49,6 +49,38 @@ template:
 		assert.Equal(t, "main", config.TemplateRef)
 	})
 
+	t.Run("should load a base template with overlays", func(t *testing.T) {
+		// Arrange
+		repo := project.NewYAMLProjectRepository()
+		filePath := filepath.Join(t.TempDir(), "vexconfig.yaml")
+
+		yamlContent := `
+project:
+  id: "proj_123"
+  name: "my-app"
+  organization: "my-org"
+  team: "my-team"
+  version: "1.0.0"
+template:
+  - url: "https://github.com/templates/go.git"
+    ref: "main"
+  - url: "https://github.com/my-org/go-overlay.git"
+    ref: "v1.2.0"
+`
+		require.NoError(t, os.WriteFile(filePath, []byte(yamlContent), 0644))
+
+		// Act
+		config, err := repo.Load(context.Background(), filePath)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/templates/go.git", config.TemplateURL)
+		assert.Equal(t, "main", config.TemplateRef)
+		require.Len(t, config.TemplateOverlays, 1)
+		assert.Equal(t, "https://github.com/my-org/go-overlay.git", config.TemplateOverlays[0].URL)
+		assert.Equal(t, "v1.2.0", config.TemplateOverlays[0].Ref)
+	})
+
 	t.Run("should return error if file does not exist", func(t *testing.T) {
 		// Arrange
 		repo := project.NewYAMLProjectRepository()
@@ -144,3 +176,35 @@ func TestYAMLProjectRepository_SaveAndLoad(t *testing.T) {
 		assert.Equal(t, originalConfig, loadedConfig, "Loaded config should be identical to the saved one")
 	})
 }
+
+func TestYAMLProjectRepository_LoadVariables(t *testing.T) {
+	repo := project.NewYAMLProjectRepository()
+	ctx := context.Background()
+
+	t.Run("should read only the variables section", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "vexconfig.local.yaml")
+		yamlContent := `
+variables:
+  "*":
+    deploy:
+      debug: true
+  dev:
+    "*":
+      region: "eu-west-1"
+`
+		require.NoError(t, os.WriteFile(filePath, []byte(yamlContent), 0644))
+
+		variables, err := repo.LoadVariables(ctx, filePath)
+
+		require.NoError(t, err)
+		assert.Equal(t, true, variables["*"]["deploy"]["debug"])
+		assert.Equal(t, "eu-west-1", variables["dev"]["*"]["region"])
+	})
+
+	t.Run("should return no variables if the file does not exist", func(t *testing.T) {
+		variables, err := repo.LoadVariables(ctx, filepath.Join(t.TempDir(), "vexconfig.local.yaml"))
+
+		require.NoError(t, err)
+		assert.Empty(t, variables)
+	})
+}
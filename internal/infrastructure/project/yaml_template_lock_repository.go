@@ -0,0 +1,68 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jairoprogramador/vex/internal/domain/project/ports"
+	"github.com/jairoprogramador/vex/internal/domain/project/vos"
+	"github.com/jairoprogramador/vex/internal/infrastructure/project/dto"
+
+	"gopkg.in/yaml.v3"
+)
+
+const templateLockHeader = "# Generado por vex. Actualízalo con 'vex template update'.\n"
+
+type YAMLTemplateLockRepository struct{}
+
+func NewYAMLTemplateLockRepository() ports.TemplateLockRepository {
+	return &YAMLTemplateLockRepository{}
+}
+
+func (r *YAMLTemplateLockRepository) Load(ctx context.Context, pathFile string) ([]vos.TemplateLock, error) {
+	data, err := os.ReadFile(pathFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []vos.TemplateLock{}, nil
+		}
+		return nil, fmt.Errorf("no se pudo leer el archivo de bloqueo '%s': %w", pathFile, err)
+	}
+
+	var lockFile dto.TemplateLockFileDTO
+	if err := yaml.Unmarshal(data, &lockFile); err != nil {
+		return nil, fmt.Errorf("error al parsear el archivo de bloqueo '%s': %w", pathFile, err)
+	}
+
+	locks := make([]vos.TemplateLock, 0, len(lockFile.Templates))
+	for _, lockDTO := range lockFile.Templates {
+		lock, err := vos.NewTemplateLock(lockDTO.URL, lockDTO.Ref, lockDTO.Commit)
+		if err != nil {
+			return nil, fmt.Errorf("bloqueo inválido en '%s': %w", pathFile, err)
+		}
+		locks = append(locks, lock)
+	}
+	return locks, nil
+}
+
+func (r *YAMLTemplateLockRepository) Save(ctx context.Context, pathFile string, locks []vos.TemplateLock) error {
+	lockFile := dto.TemplateLockFileDTO{Templates: make([]dto.TemplateLockDTO, 0, len(locks))}
+	for _, lock := range locks {
+		lockFile.Templates = append(lockFile.Templates, dto.TemplateLockDTO{
+			URL:    lock.URL(),
+			Ref:    lock.Ref(),
+			Commit: lock.Commit(),
+		})
+	}
+
+	yamlData, err := yaml.Marshal(&lockFile)
+	if err != nil {
+		return fmt.Errorf("error al serializar el archivo de bloqueo: %w", err)
+	}
+
+	if err := os.WriteFile(pathFile, append([]byte(templateLockHeader), yamlData...), 0644); err != nil {
+		return fmt.Errorf("no se pudo escribir el archivo de bloqueo '%s': %w", pathFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// inTotoPayloadType es el tipo de contenido de un sobre DSSE con una declaración in-toto.
+const inTotoPayloadType = "application/vnd.in-toto+json"
+
+// EnvelopeDTO es un sobre DSSE (Dead Simple Signing Envelope).
+type EnvelopeDTO struct {
+	PayloadType string         `json:"payloadType"`
+	Payload     string         `json:"payload"`
+	Signatures  []SignatureDTO `json:"signatures"`
+}
+
+type SignatureDTO struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// pae codifica el tipo y el contenido según el Pre-Authentication Encoding de DSSE,
+// que es lo que realmente se firma.
+func pae(payloadType string, payload []byte) []byte {
+	header := fmt.Sprintf("DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	return append([]byte(header), payload...)
+}
+
+func signEnvelope(payload []byte, privateKey ed25519.PrivateKey) EnvelopeDTO {
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	signature := ed25519.Sign(privateKey, pae(inTotoPayloadType, payload))
+	return EnvelopeDTO{
+		PayloadType: inTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []SignatureDTO{{
+			KeyID: keyID(publicKey),
+			Sig:   base64.StdEncoding.EncodeToString(signature),
+		}},
+	}
+}
+
+// openEnvelope devuelve el contenido del sobre sin comprobar su firma.
+func openEnvelope(envelope EnvelopeDTO) ([]byte, error) {
+	if envelope.PayloadType != inTotoPayloadType {
+		return nil, fmt.Errorf("tipo de contenido no soportado: %s", envelope.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("contenido del sobre mal codificado: %w", err)
+	}
+	return payload, nil
+}
+
+// verifyEnvelope comprueba que alguna firma del contenido del sobre corresponda a publicKey.
+func verifyEnvelope(envelope EnvelopeDTO, payload []byte, publicKey ed25519.PublicKey) error {
+	expectedKeyID := keyID(publicKey)
+	for _, signature := range envelope.Signatures {
+		if signature.KeyID != "" && signature.KeyID != expectedKeyID {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(publicKey, pae(envelope.PayloadType, payload), sig) {
+			return nil
+		}
+	}
+	return errors.New("ninguna firma del sobre corresponde a la clave pública local")
+}
@@ -0,0 +1,124 @@
+package provenance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jairoprogramador/vex/internal/domain/provenance/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/provenance/ports"
+	"github.com/jairoprogramador/vex/internal/domain/provenance/vos"
+)
+
+const attestationExtension = ".intoto.json"
+
+// DSSEAttestationStore guarda las declaraciones de procedencia como sobres DSSE
+// firmados con una clave ed25519 local.
+type DSSEAttestationStore struct {
+	keysDir string
+}
+
+// NewDSSEAttestationStore crea una nueva instancia de DSSEAttestationStore.
+// La clave de firma se lee de keysDir y se genera allí si no existe.
+func NewDSSEAttestationStore(keysDir string) ports.AttestationStore {
+	return &DSSEAttestationStore{keysDir: keysDir}
+}
+
+func (s *DSSEAttestationStore) Save(dirPath string, statement *aggregates.Statement) (string, error) {
+	if statement == nil {
+		return "", errors.New("no se puede guardar una declaración de procedencia nula")
+	}
+	privateKey, err := loadOrCreatePrivateKey(s.keysDir)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(toStatementDTO(statement))
+	if err != nil {
+		return "", fmt.Errorf("no se pudo serializar la declaración de procedencia: %w", err)
+	}
+	data, err := json.MarshalIndent(signEnvelope(payload, privateKey), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("no se pudo serializar el sobre firmado: %w", err)
+	}
+
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return "", fmt.Errorf("no se pudo crear el directorio de procedencia '%s': %w", dirPath, err)
+	}
+	filePath := filepath.Join(dirPath, fmt.Sprintf("%s-%s%s",
+		statement.FinishedOn().UTC().Format("20060102T150405.000"), statement.Version(), attestationExtension))
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo crear la declaración de procedencia '%s': %w", filePath, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return "", fmt.Errorf("no se pudo escribir la declaración de procedencia '%s': %w", filePath, err)
+	}
+	return filePath, nil
+}
+
+// Load lee la declaración aunque su firma no corresponda a la clave local, para que
+// quien la verifica pueda informar de ello junto al resto de comprobaciones.
+func (s *DSSEAttestationStore) Load(filePath string) (*aggregates.Statement, vos.SignatureCheck, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, vos.SignatureCheck{}, fmt.Errorf("no se pudo leer la declaración de procedencia '%s': %w", filePath, err)
+	}
+	var envelope EnvelopeDTO
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, vos.SignatureCheck{}, fmt.Errorf("sobre firmado corrupto '%s': %w", filePath, err)
+	}
+	payload, err := openEnvelope(envelope)
+	if err != nil {
+		return nil, vos.SignatureCheck{}, fmt.Errorf("sobre firmado inválido '%s': %w", filePath, err)
+	}
+
+	var dto StatementDTO
+	if err := json.Unmarshal(payload, &dto); err != nil {
+		return nil, vos.SignatureCheck{}, fmt.Errorf("declaración de procedencia corrupta '%s': %w", filePath, err)
+	}
+	if dto.Type != inTotoStatementType || dto.PredicateType != slsaPredicateType {
+		return nil, vos.SignatureCheck{}, fmt.Errorf("declaración de procedencia no soportada '%s': %s, %s", filePath, dto.Type, dto.PredicateType)
+	}
+	statement, err := fromStatementDTO(dto)
+	if err != nil {
+		return nil, vos.SignatureCheck{}, err
+	}
+	return statement, s.checkSignature(envelope, payload), nil
+}
+
+func (s *DSSEAttestationStore) checkSignature(envelope EnvelopeDTO, payload []byte) vos.SignatureCheck {
+	publicKey, err := loadPublicKey(s.keysDir)
+	if err != nil {
+		return vos.NewSignatureCheck(false, err.Error())
+	}
+	if err := verifyEnvelope(envelope, payload, publicKey); err != nil {
+		return vos.NewSignatureCheck(false, err.Error())
+	}
+	return vos.NewSignatureCheck(true, "la firma corresponde a la clave local "+keyID(publicKey)[:16])
+}
+
+func (s *DSSEAttestationStore) FindLatest(dirPath string) (string, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no se pudo leer el directorio de procedencia '%s': %w", dirPath, err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), attestationExtension) {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no hay declaraciones de procedencia en '%s'", dirPath)
+	}
+	sort.Strings(names)
+	return filepath.Join(dirPath, names[len(names)-1]), nil
+}
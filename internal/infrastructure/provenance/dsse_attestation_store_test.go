@@ -0,0 +1,101 @@
+package provenance
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jairoprogramador/vex/internal/domain/provenance/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/provenance/vos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStatement(t *testing.T) *aggregates.Statement {
+	t.Helper()
+	source, err := vos.NewResourceDescriptor(vos.SourceDependency, "https://example.com/app.git",
+		map[string]string{vos.GitCommitDigest: "abc123"})
+	require.NoError(t, err)
+	artifact, err := vos.NewResourceDescriptor("dist/app.tar.gz", "",
+		map[string]string{vos.SHA256Digest: "deadbeef"})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	statement, err := aggregates.NewStatement(
+		[]vos.ResourceDescriptor{artifact}, []vos.ResourceDescriptor{source},
+		"prod", "deploy", "v1.0.0", "release-id", "1.2.3", now, now)
+	require.NoError(t, err)
+	return statement
+}
+
+func TestDSSEAttestationStore(t *testing.T) {
+	keysDir := t.TempDir()
+	dirPath := t.TempDir()
+	store := NewDSSEAttestationStore(keysDir)
+
+	statementPath, err := store.Save(dirPath, newTestStatement(t))
+	require.NoError(t, err)
+
+	t.Run("debería leer una declaración firmada con la clave local", func(t *testing.T) {
+		latest, err := store.FindLatest(dirPath)
+		require.NoError(t, err)
+		assert.Equal(t, statementPath, latest)
+
+		statement, signature, err := store.Load(statementPath)
+		require.NoError(t, err)
+		assert.True(t, signature.IsValid())
+		assert.Equal(t, "prod", statement.Environment())
+		assert.Equal(t, "1.2.3", statement.BuilderVersion())
+
+		source, exists := statement.Dependency(vos.SourceDependency)
+		require.True(t, exists)
+		assert.Equal(t, "abc123", source.Digest()[vos.GitCommitDigest])
+		assert.Equal(t, "deadbeef", statement.Subjects()[0].Digest()[vos.SHA256Digest])
+	})
+
+	t.Run("debería marcar como inválida la firma de una declaración modificada", func(t *testing.T) {
+		data, err := os.ReadFile(statementPath)
+		require.NoError(t, err)
+		var envelope EnvelopeDTO
+		require.NoError(t, json.Unmarshal(data, &envelope))
+
+		payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+		require.NoError(t, err)
+		var statement StatementDTO
+		require.NoError(t, json.Unmarshal(payload, &statement))
+		statement.Subject[0].Digest[vos.SHA256Digest] = "cafebabe"
+		tampered, err := json.Marshal(statement)
+		require.NoError(t, err)
+		envelope.Payload = base64.StdEncoding.EncodeToString(tampered)
+
+		tamperedPath := filepath.Join(t.TempDir(), "tampered.intoto.json")
+		data, err = json.Marshal(envelope)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(tamperedPath, data, 0644))
+
+		loaded, signature, err := store.Load(tamperedPath)
+		require.NoError(t, err)
+		assert.False(t, signature.IsValid())
+		assert.Equal(t, "cafebabe", loaded.Subjects()[0].Digest()[vos.SHA256Digest])
+	})
+
+	t.Run("debería marcar como inválida la firma de otra clave", func(t *testing.T) {
+		otherStore := NewDSSEAttestationStore(t.TempDir())
+		otherPath, err := otherStore.Save(t.TempDir(), newTestStatement(t))
+		require.NoError(t, err)
+
+		_, signature, err := store.Load(otherPath)
+		require.NoError(t, err)
+		assert.False(t, signature.IsValid())
+	})
+
+	t.Run("debería marcar como inválida la firma sin una clave pública local", func(t *testing.T) {
+		_, signature, err := NewDSSEAttestationStore(t.TempDir()).Load(statementPath)
+		require.NoError(t, err)
+		assert.False(t, signature.IsValid())
+		assert.NotEmpty(t, signature.Detail())
+	})
+}
@@ -0,0 +1,103 @@
+package provenance
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	privateKeyFile = "provenance.key"
+	publicKeyFile  = "provenance.pub"
+)
+
+// loadOrCreatePrivateKey lee la clave de firma local. La primera vez la genera,
+// junto con su clave pública, en keysDir.
+func loadOrCreatePrivateKey(keysDir string) (ed25519.PrivateKey, error) {
+	privatePath := filepath.Join(keysDir, privateKeyFile)
+	data, err := os.ReadFile(privatePath)
+	if err == nil {
+		return decodePrivateKey(data, privatePath)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no se pudo leer la clave de firma '%s': %w", privatePath, err)
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo generar la clave de firma: %w", err)
+	}
+	if err := os.MkdirAll(keysDir, 0700); err != nil {
+		return nil, fmt.Errorf("no se pudo crear el directorio de claves '%s': %w", keysDir, err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo codificar la clave de firma: %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo codificar la clave pública: %w", err)
+	}
+
+	err = os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo guardar la clave de firma '%s': %w", privatePath, err)
+	}
+	publicPath := filepath.Join(keysDir, publicKeyFile)
+	err = os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo guardar la clave pública '%s': %w", publicPath, err)
+	}
+	return privateKey, nil
+}
+
+func loadPublicKey(keysDir string) (ed25519.PublicKey, error) {
+	publicPath := filepath.Join(keysDir, publicKeyFile)
+	data, err := os.ReadFile(publicPath)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la clave pública '%s': %w", publicPath, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("la clave pública '%s' no tiene formato PEM", publicPath)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("clave pública inválida '%s': %w", publicPath, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("la clave pública '%s' no es ed25519", publicPath)
+	}
+	return publicKey, nil
+}
+
+func decodePrivateKey(data []byte, path string) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("la clave de firma '%s' no tiene formato PEM", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("clave de firma inválida '%s': %w", path, err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("la clave de firma '%s' no es ed25519", path)
+	}
+	return privateKey, nil
+}
+
+// keyID identifica una clave pública por el sha256 de sus bytes.
+func keyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:])
+}
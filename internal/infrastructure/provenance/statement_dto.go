@@ -0,0 +1,135 @@
+package provenance
+
+import (
+	"time"
+
+	"github.com/jairoprogramador/vex/internal/domain/provenance/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/provenance/vos"
+)
+
+const (
+	inTotoStatementType = "https://in-toto.io/Statement/v1"
+	slsaPredicateType   = "https://slsa.dev/provenance/v1"
+	vexBuildType        = "https://github.com/jairoprogramador/vex/deploy/v1"
+	vexBuilderID        = "https://github.com/jairoprogramador/vex"
+)
+
+// StatementDTO es una declaración in-toto v1 con un predicado de procedencia SLSA v1.
+type StatementDTO struct {
+	Type          string                  `json:"_type"`
+	Subject       []ResourceDescriptorDTO `json:"subject"`
+	PredicateType string                  `json:"predicateType"`
+	Predicate     ProvenanceDTO           `json:"predicate"`
+}
+
+type ResourceDescriptorDTO struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+type ProvenanceDTO struct {
+	BuildDefinition BuildDefinitionDTO `json:"buildDefinition"`
+	RunDetails      RunDetailsDTO      `json:"runDetails"`
+}
+
+type BuildDefinitionDTO struct {
+	BuildType            string                  `json:"buildType"`
+	ExternalParameters   ExternalParametersDTO   `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptorDTO `json:"resolvedDependencies"`
+}
+
+type ExternalParametersDTO struct {
+	Environment string `json:"environment"`
+	Step        string `json:"step"`
+	Version     string `json:"version"`
+}
+
+type RunDetailsDTO struct {
+	Builder  BuilderDTO  `json:"builder"`
+	Metadata MetadataDTO `json:"metadata"`
+}
+
+type BuilderDTO struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+type MetadataDTO struct {
+	InvocationID string    `json:"invocationId,omitempty"`
+	StartedOn    time.Time `json:"startedOn"`
+	FinishedOn   time.Time `json:"finishedOn"`
+}
+
+func toStatementDTO(statement *aggregates.Statement) StatementDTO {
+	return StatementDTO{
+		Type:          inTotoStatementType,
+		Subject:       toResourceDescriptorDTOs(statement.Subjects()),
+		PredicateType: slsaPredicateType,
+		Predicate: ProvenanceDTO{
+			BuildDefinition: BuildDefinitionDTO{
+				BuildType: vexBuildType,
+				ExternalParameters: ExternalParametersDTO{
+					Environment: statement.Environment(),
+					Step:        statement.FinalStep(),
+					Version:     statement.Version(),
+				},
+				ResolvedDependencies: toResourceDescriptorDTOs(statement.Dependencies()),
+			},
+			RunDetails: RunDetailsDTO{
+				Builder: BuilderDTO{
+					ID:      vexBuilderID,
+					Version: map[string]string{"vex": statement.BuilderVersion()},
+				},
+				Metadata: MetadataDTO{
+					InvocationID: statement.InvocationID(),
+					StartedOn:    statement.StartedOn(),
+					FinishedOn:   statement.FinishedOn(),
+				},
+			},
+		},
+	}
+}
+
+func fromStatementDTO(dto StatementDTO) (*aggregates.Statement, error) {
+	subjects, err := fromResourceDescriptorDTOs(dto.Subject)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := fromResourceDescriptorDTOs(dto.Predicate.BuildDefinition.ResolvedDependencies)
+	if err != nil {
+		return nil, err
+	}
+	parameters := dto.Predicate.BuildDefinition.ExternalParameters
+	metadata := dto.Predicate.RunDetails.Metadata
+	return aggregates.NewStatement(
+		subjects, dependencies,
+		parameters.Environment, parameters.Step, parameters.Version,
+		metadata.InvocationID, dto.Predicate.RunDetails.Builder.Version["vex"],
+		metadata.StartedOn, metadata.FinishedOn,
+	)
+}
+
+func toResourceDescriptorDTOs(descriptors []vos.ResourceDescriptor) []ResourceDescriptorDTO {
+	dtos := make([]ResourceDescriptorDTO, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		dtos = append(dtos, ResourceDescriptorDTO{
+			Name:   descriptor.Name(),
+			URI:    descriptor.URI(),
+			Digest: descriptor.Digest(),
+		})
+	}
+	return dtos
+}
+
+func fromResourceDescriptorDTOs(dtos []ResourceDescriptorDTO) ([]vos.ResourceDescriptor, error) {
+	descriptors := make([]vos.ResourceDescriptor, 0, len(dtos))
+	for _, dto := range dtos {
+		descriptor, err := vos.NewResourceDescriptor(dto.Name, dto.URI, dto.Digest)
+		if err != nil {
+			return nil, err
+		}
+		descriptors = append(descriptors, descriptor)
+	}
+	return descriptors, nil
+}
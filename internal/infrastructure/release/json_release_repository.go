@@ -0,0 +1,99 @@
+package release
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/release/ports"
+	"github.com/jairoprogramador/vex/internal/infrastructure/atomicfile"
+)
+
+const releaseExtension = ".json"
+
+// JSONReleaseRepository guarda cada ejecución en su propio archivo JSON.
+type JSONReleaseRepository struct{}
+
+// NewJSONReleaseRepository crea una nueva instancia de JSONReleaseRepository.
+func NewJSONReleaseRepository() ports.ReleaseRepository {
+	return &JSONReleaseRepository{}
+}
+
+// Save escribe la ejecución en un archivo nuevo. Nunca sobrescribe un registro
+// existente. Escribe en un archivo temporal y lo renombra, para que una ejecución
+// interrumpida no deje un registro a medias.
+func (r *JSONReleaseRepository) Save(dirPath string, release *aggregates.Release) error {
+	if release == nil {
+		return errors.New("no se puede guardar una ejecución nula")
+	}
+
+	data, err := json.MarshalIndent(toReleaseDTO(release), "", "  ")
+	if err != nil {
+		return fmt.Errorf("no se pudo serializar la ejecución: %w", err)
+	}
+
+	filePath := filepath.Join(dirPath, fileName(release))
+	if _, err := os.Stat(filePath); err == nil {
+		return fmt.Errorf("ya existe el registro de la ejecución '%s'", filePath)
+	}
+	if err := atomicfile.WriteFile(filePath, data); err != nil {
+		return fmt.Errorf("no se pudo guardar el registro de la ejecución '%s': %w", filePath, err)
+	}
+	return nil
+}
+
+// FindAll lee todas las ejecuciones del directorio, ordenadas por fecha de inicio.
+// Un registro que no se puede leer no impide leer los demás.
+func (r *JSONReleaseRepository) FindAll(dirPath string) ([]*aggregates.Release, []error, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []*aggregates.Release{}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("no se pudo leer el directorio de ejecuciones '%s': %w", dirPath, err)
+	}
+
+	releases := make([]*aggregates.Release, 0, len(entries))
+	var skipped []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), releaseExtension) {
+			continue
+		}
+		release, err := readRelease(filepath.Join(dirPath, entry.Name()))
+		if err != nil {
+			skipped = append(skipped, err)
+			continue
+		}
+		releases = append(releases, release)
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].StartedAt().Before(releases[j].StartedAt())
+	})
+	return releases, skipped, nil
+}
+
+func readRelease(filePath string) (*aggregates.Release, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el registro '%s': %w", filePath, err)
+	}
+	var dto ReleaseDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("registro de ejecución corrupto '%s': %w", filePath, err)
+	}
+	release, err := fromReleaseDTO(dto)
+	if err != nil {
+		return nil, fmt.Errorf("registro de ejecución inválido '%s': %w", filePath, err)
+	}
+	return release, nil
+}
+
+func fileName(release *aggregates.Release) string {
+	return fmt.Sprintf("%s-%s%s", release.Version(), release.StartedAt().Format("20060102T150405.000"), releaseExtension)
+}
@@ -0,0 +1,53 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/release/vos"
+)
+
+func newFinishedRelease(t *testing.T) *aggregates.Release {
+	t.Helper()
+	release, err := aggregates.NewRelease("sand", "deploy", "v1.0.0",
+		"0123456789abcdef0123456789abcdef01234567", vos.Execution)
+	require.NoError(t, err)
+	release.Finish(nil, map[string]string{"region": "eu"})
+	return release
+}
+
+func TestJSONReleaseRepository_SaveNeverOverwritesARecord(t *testing.T) {
+	repo := NewJSONReleaseRepository()
+	dirPath := filepath.Join(t.TempDir(), "releases", "sand")
+	release := newFinishedRelease(t)
+
+	require.NoError(t, repo.Save(dirPath, release))
+	require.Error(t, repo.Save(dirPath, release))
+
+	entries, err := os.ReadDir(dirPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no deben quedar archivos temporales")
+	assert.Equal(t, fileName(release), entries[0].Name())
+}
+
+func TestJSONReleaseRepository_FindAllSkipsUnreadableRecords(t *testing.T) {
+	repo := NewJSONReleaseRepository()
+	dirPath := t.TempDir()
+	release := newFinishedRelease(t)
+	require.NoError(t, repo.Save(dirPath, release))
+	corruptPath := filepath.Join(dirPath, "v0.9.0-20240101T000000.000.json")
+	require.NoError(t, os.WriteFile(corruptPath, []byte(`{"id": "trunca`), 0644))
+
+	releases, skipped, err := repo.FindAll(dirPath)
+
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	assert.Equal(t, release.ID(), releases[0].ID())
+	require.Len(t, skipped, 1)
+	assert.Contains(t, skipped[0].Error(), corruptPath)
+}
@@ -0,0 +1,220 @@
+package release
+
+import (
+	"time"
+
+	"github.com/jairoprogramador/vex/internal/domain/release/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/release/entities"
+	"github.com/jairoprogramador/vex/internal/domain/release/vos"
+)
+
+// ReleaseDTO es la representación en disco del manifiesto de una ejecución.
+type ReleaseDTO struct {
+	ID           string            `json:"id"`
+	Environment  string            `json:"environment"`
+	FinalStep    string            `json:"final_step"`
+	Version      string            `json:"version"`
+	Commit       string            `json:"commit"`
+	Kind         string            `json:"kind"`
+	Status       string            `json:"status"`
+	RollbackOf   string            `json:"rollback_of,omitempty"`
+	PromotedFrom string            `json:"promoted_from,omitempty"`
+	ToolVersion  string            `json:"tool_version,omitempty"`
+	Template     TemplateDTO       `json:"template"`
+	Overlays     []TemplateDTO     `json:"overlays,omitempty"`
+	StartedAt    time.Time         `json:"started_at"`
+	FinishedAt   time.Time         `json:"finished_at"`
+	Steps        []StepRunDTO      `json:"steps"`
+	Variables    map[string]string `json:"variables"`
+	Overrides    map[string]string `json:"overrides,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+type TemplateDTO struct {
+	URL string `json:"url"`
+	Ref string `json:"ref"`
+	SHA string `json:"sha,omitempty"`
+}
+
+type StepRunDTO struct {
+	Name         string             `json:"name"`
+	Outcome      string             `json:"outcome"`
+	StartedAt    time.Time          `json:"started_at"`
+	FinishedAt   time.Time          `json:"finished_at"`
+	Fingerprints FingerprintsDTO    `json:"fingerprints"`
+	Outputs      map[string]string  `json:"outputs,omitempty"`
+	Variables    []VariableTraceDTO `json:"variables,omitempty"`
+	Hooks        []HookRunDTO       `json:"hooks,omitempty"`
+}
+
+// HookRunDTO es un comando de un hook ejecutado para el paso.
+type HookRunDTO struct {
+	Kind    string `json:"kind"`
+	Command string `json:"command"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// VariableTraceDTO es la cadena de resolución de una variable, del valor vigente al más antiguo.
+type VariableTraceDTO struct {
+	Name  string              `json:"name"`
+	Chain []VariableOriginDTO `json:"chain"`
+}
+
+type VariableOriginDTO struct {
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+type FingerprintsDTO struct {
+	Code        string `json:"code,omitempty"`
+	Instruction string `json:"instruction,omitempty"`
+	Vars        string `json:"vars,omitempty"`
+	Environment string `json:"environment,omitempty"`
+}
+
+func toReleaseDTO(release *aggregates.Release) ReleaseDTO {
+	steps := make([]StepRunDTO, 0, len(release.Steps()))
+	for _, step := range release.Steps() {
+		steps = append(steps, toStepRunDTO(step))
+	}
+	var overlays []TemplateDTO
+	for _, overlay := range release.TemplateOverlays() {
+		overlays = append(overlays, toTemplateDTO(overlay))
+	}
+	return ReleaseDTO{
+		ID:           release.ID(),
+		Environment:  release.Environment(),
+		FinalStep:    release.FinalStep(),
+		Version:      release.Version(),
+		Commit:       release.Commit(),
+		Kind:         release.Kind().String(),
+		Status:       release.Status().String(),
+		RollbackOf:   release.RollbackOf(),
+		PromotedFrom: release.PromotedFrom(),
+		ToolVersion:  release.ToolVersion(),
+		Template:     toTemplateDTO(release.Template()),
+		Overlays:     overlays,
+		StartedAt:    release.StartedAt(),
+		FinishedAt:   release.FinishedAt(),
+		Steps:        steps,
+		Variables:    release.Variables(),
+		Overrides:    release.Overrides(),
+		Error:        release.ErrorMessage(),
+	}
+}
+
+func toTemplateDTO(template vos.TemplateSource) TemplateDTO {
+	return TemplateDTO{URL: template.URL(), Ref: template.Ref(), SHA: template.SHA()}
+}
+
+func toStepRunDTO(step *entities.StepRun) StepRunDTO {
+	fingerprints := step.Fingerprints()
+	return StepRunDTO{
+		Name:       step.Name(),
+		Outcome:    step.Outcome().String(),
+		StartedAt:  step.StartedAt(),
+		FinishedAt: step.FinishedAt(),
+		Fingerprints: FingerprintsDTO{
+			Code:        fingerprints.Code(),
+			Instruction: fingerprints.Instruction(),
+			Vars:        fingerprints.Vars(),
+			Environment: fingerprints.Environment(),
+		},
+		Outputs:   step.Outputs(),
+		Variables: toVariableTraceDTOs(step.Variables()),
+		Hooks:     toHookRunDTOs(step.Hooks()),
+	}
+}
+
+func toHookRunDTOs(hooks []vos.HookRun) []HookRunDTO {
+	var dtos []HookRunDTO
+	for _, hook := range hooks {
+		dtos = append(dtos, HookRunDTO{
+			Kind: hook.Kind(), Command: hook.Command(), Success: hook.Succeeded(), Error: hook.ErrorMessage(),
+		})
+	}
+	return dtos
+}
+
+func fromHookRunDTOs(dtos []HookRunDTO) []vos.HookRun {
+	hooks := make([]vos.HookRun, 0, len(dtos))
+	for _, dto := range dtos {
+		hooks = append(hooks, vos.NewHookRun(dto.Kind, dto.Command, dto.Success, dto.Error))
+	}
+	return hooks
+}
+
+func toVariableTraceDTOs(traces []vos.VariableTrace) []VariableTraceDTO {
+	var dtos []VariableTraceDTO
+	for _, trace := range traces {
+		chain := make([]VariableOriginDTO, 0, len(trace.Chain()))
+		for _, origin := range trace.Chain() {
+			chain = append(chain, VariableOriginDTO{Value: origin.Value(), Source: origin.Source()})
+		}
+		dtos = append(dtos, VariableTraceDTO{Name: trace.Name(), Chain: chain})
+	}
+	return dtos
+}
+
+func fromVariableTraceDTOs(dtos []VariableTraceDTO) []vos.VariableTrace {
+	traces := make([]vos.VariableTrace, 0, len(dtos))
+	for _, dto := range dtos {
+		chain := make([]vos.VariableOrigin, 0, len(dto.Chain))
+		for _, origin := range dto.Chain {
+			chain = append(chain, vos.NewVariableOrigin(origin.Value, origin.Source))
+		}
+		traces = append(traces, vos.NewVariableTrace(dto.Name, chain))
+	}
+	return traces
+}
+
+func fromReleaseDTO(dto ReleaseDTO) (*aggregates.Release, error) {
+	kind, err := vos.NewKind(dto.Kind)
+	if err != nil {
+		return nil, err
+	}
+	status, err := vos.NewStatus(dto.Status)
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]*entities.StepRun, 0, len(dto.Steps))
+	for _, stepDTO := range dto.Steps {
+		step, err := fromStepRunDTO(stepDTO)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	overlays := make([]vos.TemplateSource, 0, len(dto.Overlays))
+	for _, overlay := range dto.Overlays {
+		overlays = append(overlays, vos.NewTemplateSource(overlay.URL, overlay.Ref, overlay.SHA))
+	}
+	return aggregates.HydrateRelease(
+		dto.ID, dto.Environment, dto.FinalStep, dto.Version, dto.Commit,
+		kind, status, dto.RollbackOf, dto.PromotedFrom,
+		dto.StartedAt, dto.FinishedAt,
+		dto.Variables, dto.Overrides, dto.Error,
+		vos.NewTemplateSource(dto.Template.URL, dto.Template.Ref, dto.Template.SHA),
+		overlays,
+		dto.ToolVersion,
+		steps,
+	), nil
+}
+
+func fromStepRunDTO(dto StepRunDTO) (*entities.StepRun, error) {
+	outcome, err := vos.NewStepOutcome(dto.Outcome)
+	if err != nil {
+		return nil, err
+	}
+	return entities.HydrateStepRun(
+		dto.Name, outcome,
+		dto.StartedAt, dto.FinishedAt,
+		vos.NewStepFingerprints(
+			dto.Fingerprints.Code, dto.Fingerprints.Instruction,
+			dto.Fingerprints.Vars, dto.Fingerprints.Environment),
+		dto.Outputs,
+		fromVariableTraceDTOs(dto.Variables),
+		fromHookRunDTOs(dto.Hooks),
+	), nil
+}
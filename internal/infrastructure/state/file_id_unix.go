@@ -0,0 +1,16 @@
+//go:build !windows
+
+package state
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileInode devuelve el inodo del archivo, o 0 si el sistema no lo expone.
+func fileInode(info fs.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package state
+
+import "io/fs"
+
+// fileInode devuelve 0: en Windows el índice se apoya solo en el tamaño y la fecha.
+func fileInode(info fs.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jairoprogramador/vex/internal/domain/state/ports"
+)
+
+// manifestKeySize es el tamaño en bytes de la clave de los manifiestos de variables.
+const manifestKeySize = 32
+
+// FileManifestKeyRepository guarda la clave de los manifiestos de variables como un
+// archivo hexadecimal legible solo por el usuario.
+type FileManifestKeyRepository struct{}
+
+// NewFileManifestKeyRepository crea una nueva instancia de FileManifestKeyRepository.
+func NewFileManifestKeyRepository() ports.ManifestKeyRepository {
+	return &FileManifestKeyRepository{}
+}
+
+func (r *FileManifestKeyRepository) LoadOrCreate(filePath string) ([]byte, error) {
+	key, err := readManifestKey(filePath)
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return key, err
+	}
+
+	key = make([]byte, manifestKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("no se pudo generar la clave de los manifiestos: %w", err)
+	}
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("no se pudo crear el directorio '%s': %w", dir, err)
+	}
+
+	// La clave se escribe en un temporal y se enlaza con su nombre: si otra ejecución
+	// la creó antes, el enlace falla y se usa la suya.
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo crear un archivo temporal en '%s': %w", dir, err)
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.WriteString(hex.EncodeToString(key))
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo escribir la clave de los manifiestos '%s': %w", filePath, err)
+	}
+	if err := os.Link(tmpFile.Name(), filePath); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return readManifestKey(filePath)
+		}
+		return nil, fmt.Errorf("no se pudo guardar la clave de los manifiestos '%s': %w", filePath, err)
+	}
+	return key, nil
+}
+
+func readManifestKey(filePath string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no se pudo leer la clave de los manifiestos '%s': %w", filePath, err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != manifestKeySize {
+		return nil, fmt.Errorf("la clave de los manifiestos '%s' está corrupta", filePath)
+	}
+	return key, nil
+}
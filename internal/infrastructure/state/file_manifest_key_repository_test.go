@@ -0,0 +1,37 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileManifestKeyRepository(t *testing.T) {
+	repo := NewFileManifestKeyRepository()
+	keyPath := filepath.Join(t.TempDir(), "workspace", "manifest.key")
+
+	t.Run("debería crear la clave una sola vez y solo legible por el usuario", func(t *testing.T) {
+		key, err := repo.LoadOrCreate(keyPath)
+		require.NoError(t, err)
+		assert.Len(t, key, manifestKeySize)
+
+		info, err := os.Stat(keyPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		again, err := repo.LoadOrCreate(keyPath)
+		require.NoError(t, err)
+		assert.Equal(t, key, again)
+	})
+
+	t.Run("debería rechazar una clave corrupta", func(t *testing.T) {
+		corruptPath := filepath.Join(t.TempDir(), "manifest.key")
+		require.NoError(t, os.WriteFile(corruptPath, []byte("not-hex"), 0600))
+
+		_, err := repo.LoadOrCreate(corruptPath)
+		assert.ErrorContains(t, err, "corrupta")
+	})
+}
@@ -0,0 +1,162 @@
+package state
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jairoprogramador/vex/internal/infrastructure/atomicfile"
+)
+
+// racyWindow es el margen con el que se desconfía de un archivo modificado justo
+// antes de calcular su hash: en sistemas de archivos con fechas poco precisas, un
+// cambio posterior en el mismo intervalo no alteraría la fecha guardada.
+const racyWindow = time.Second
+
+// fingerprintIndexDTO es el formato del índice en disco.
+type fingerprintIndexDTO struct {
+	Entries map[string]indexEntryDTO
+}
+
+type indexEntryDTO struct {
+	Size     int64
+	ModTime  int64
+	Inode    uint64
+	Hash     string
+	HashedAt int64
+}
+
+// fingerprintIndex recuerda el hash de cada archivo junto con su tamaño, fecha de
+// modificación e inodo, para no volver a leer los archivos que no cambiaron. Vive en
+// memoria durante la ejecución y, si tiene ruta, se guarda entre ejecuciones.
+type fingerprintIndex struct {
+	mu       sync.Mutex
+	filePath string
+	loaded   bool
+	dirty    bool
+	entries  map[string]indexEntryDTO
+	// seen y walkedDirs permiten descartar al guardar los archivos que ya no existen.
+	seen       map[string]bool
+	walkedDirs map[string]bool
+}
+
+func newFingerprintIndex(filePath string) *fingerprintIndex {
+	return &fingerprintIndex{
+		filePath:   filePath,
+		entries:    make(map[string]indexEntryDTO),
+		seen:       make(map[string]bool),
+		walkedDirs: make(map[string]bool),
+	}
+}
+
+// load lee el índice la primera vez que se usa. Un índice ilegible se descarta:
+// solo cuesta volver a calcular los hashes.
+func (i *fingerprintIndex) load() {
+	if i.loaded {
+		return
+	}
+	i.loaded = true
+	if i.filePath == "" {
+		return
+	}
+	file, err := os.Open(i.filePath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	var dto fingerprintIndexDTO
+	if err := gob.NewDecoder(file).Decode(&dto); err == nil && dto.Entries != nil {
+		i.entries = dto.Entries
+	}
+}
+
+// lookup devuelve el hash guardado de un archivo si su tamaño, fecha e inodo no cambiaron.
+func (i *fingerprintIndex) lookup(filePath string, info fs.FileInfo) (string, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.load()
+	i.seen[filePath] = true
+
+	entry, exists := i.entries[filePath]
+	if !exists {
+		return "", false
+	}
+	modTime := info.ModTime().UnixNano()
+	if entry.Size != info.Size() || entry.ModTime != modTime || entry.Inode != fileInode(info) {
+		return "", false
+	}
+	if modTime >= entry.HashedAt-racyWindow.Nanoseconds() {
+		return "", false
+	}
+	return entry.Hash, true
+}
+
+func (i *fingerprintIndex) store(filePath string, info fs.FileInfo, hash string, hashedAt time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.load()
+	i.seen[filePath] = true
+	i.entries[filePath] = indexEntryDTO{
+		Size:     info.Size(),
+		ModTime:  info.ModTime().UnixNano(),
+		Inode:    fileInode(info),
+		Hash:     hash,
+		HashedAt: hashedAt.UnixNano(),
+	}
+	i.dirty = true
+}
+
+// markWalked registra que se recorrió un directorio completo.
+func (i *fingerprintIndex) markWalked(dirPath string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.walkedDirs[dirPath] = true
+}
+
+// save guarda el índice si cambió, descartando los archivos de los directorios
+// recorridos que ya no existen. Escribe en un archivo temporal y lo renombra para
+// que una ejecución simultánea nunca lea un índice a medias.
+func (i *fingerprintIndex) save() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.filePath == "" {
+		return nil
+	}
+	for filePath := range i.entries {
+		if i.seen[filePath] || !i.insideWalkedDir(filePath) {
+			continue
+		}
+		if _, err := os.Lstat(filePath); errors.Is(err, os.ErrNotExist) {
+			delete(i.entries, filePath)
+			i.dirty = true
+		}
+	}
+	if !i.dirty {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(fingerprintIndexDTO{Entries: i.entries}); err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(i.filePath, buffer.Bytes()); err != nil {
+		return err
+	}
+	i.dirty = false
+	return nil
+}
+
+func (i *fingerprintIndex) insideWalkedDir(filePath string) bool {
+	for dirPath := range i.walkedDirs {
+		if strings.HasPrefix(filePath, dirPath+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package state
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"time"
+
+	"github.com/jairoprogramador/vex/internal/domain/state/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/state/vos"
+)
+
+// gobStateTableDTO es el formato de las tablas de estado anteriores a JSON. Solo se
+// lee para migrarlas: gob asocia los campos por nombre, así que no deben cambiar.
+type gobStateTableDTO struct {
+	Name    string
+	Entries []*gobStateEntryDTO
+}
+
+type gobStateEntryDTO struct {
+	Code        string
+	Instruction string
+	Environment string
+	Vars        string
+	CreatedAt   time.Time
+	// CodeManifest y VarsManifest son los manifiestos codificados con gob y
+	// comprimidos con gzip.
+	CodeManifest []byte
+	VarsManifest []byte
+}
+
+// decodeGobStateTable lee una tabla de estado guardada con gob.
+func decodeGobStateTable(data []byte) (*aggregates.StateTable, error) {
+	var dto gobStateTableDTO
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&dto); err != nil {
+		return nil, err
+	}
+
+	domainEntries := make([]*aggregates.StateEntry, 0, len(dto.Entries))
+	for _, dtoEntry := range dto.Entries {
+		domainEntries = append(domainEntries, newStateEntry(
+			dtoEntry.Code, dtoEntry.Instruction, dtoEntry.Vars, dtoEntry.Environment, dtoEntry.CreatedAt,
+			decompressManifest(dtoEntry.CodeManifest), decompressManifest(dtoEntry.VarsManifest)))
+	}
+	return aggregates.LoadStateTable(dto.Name, domainEntries), nil
+}
+
+// decompressManifest recupera un manifiesto guardado. Si no se puede leer, como
+// ocurre con los fingerprints, se trata como si la entrada no lo tuviera.
+func decompressManifest(data []byte) vos.Manifest {
+	if len(data) == 0 {
+		return vos.Manifest{}
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return vos.Manifest{}
+	}
+	defer reader.Close()
+	var hashes map[string]string
+	if err := gob.NewDecoder(reader).Decode(&hashes); err != nil {
+		return vos.Manifest{}
+	}
+	return vos.NewManifest(hashes)
+}
@@ -0,0 +1,99 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+const (
+	gitignoreFileName = ".gitignore"
+	vexignoreFileName = ".vexignore"
+)
+
+// ignoreRule es un patrón de un archivo de exclusión, relativo al directorio que lo contiene.
+type ignoreRule struct {
+	baseDir string
+	negate  bool
+	matcher *gitignore.GitIgnore
+}
+
+// ignoreRules reproduce la semántica de git: gana el último patrón que coincide, y los
+// .gitignore de los subdirectorios se evalúan después de los de sus padres. Las reglas
+// se comparten entre directorios hermanos, así que with siempre devuelve una copia.
+type ignoreRules struct {
+	rules []ignoreRule
+}
+
+// rootIgnoreRules carga las reglas que aplican a todo el directorio, de menor a mayor
+// prioridad: .git/info/exclude y .gitignore de la raíz. Las de .vexignore se devuelven
+// aparte porque prevalecen sobre cualquier .gitignore.
+func rootIgnoreRules(dirPath string) (ignoreRules, ignoreRules, error) {
+	var rules ignoreRules
+	for _, fileName := range []string{filepath.Join(".git", "info", "exclude"), gitignoreFileName} {
+		lines, err := readIgnoreFile(filepath.Join(dirPath, fileName))
+		if err != nil {
+			return ignoreRules{}, ignoreRules{}, err
+		}
+		rules = rules.with("", lines)
+	}
+	lines, err := readIgnoreFile(filepath.Join(dirPath, vexignoreFileName))
+	if err != nil {
+		return ignoreRules{}, ignoreRules{}, err
+	}
+	return rules, ignoreRules{}.with("", lines), nil
+}
+
+func readIgnoreFile(filePath string) ([]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read ignore file %s: %w", filePath, err)
+	}
+	return strings.Split(string(data), "\n"), nil
+}
+
+// with añade los patrones de un archivo de exclusión ubicado en baseDir.
+func (r ignoreRules) with(baseDir string, lines []string) ignoreRules {
+	rules := append([]ignoreRule(nil), r.rules...)
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		negate := strings.HasPrefix(line, "!")
+		if negate {
+			line = line[1:]
+		}
+		rules = append(rules, ignoreRule{
+			baseDir: baseDir,
+			negate:  negate,
+			matcher: gitignore.CompileIgnoreLines(line),
+		})
+	}
+	return ignoreRules{rules: rules}
+}
+
+// match indica si alguna regla decide sobre relPath y, en ese caso, si lo excluye.
+func (r ignoreRules) match(relPath string) (ignored bool, decided bool) {
+	for _, rule := range r.rules {
+		rulePath := relPath
+		if rule.baseDir != "" {
+			var inside bool
+			rulePath, inside = strings.CutPrefix(relPath, rule.baseDir+string(filepath.Separator))
+			if !inside {
+				continue
+			}
+		}
+		if rule.matcher.MatchesPath(rulePath) {
+			ignored, decided = !rule.negate, true
+		}
+	}
+	return ignored, decided
+}
@@ -0,0 +1,110 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jairoprogramador/vex/internal/domain/state/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/state/ports"
+	"github.com/jairoprogramador/vex/internal/infrastructure/atomicfile"
+)
+
+// JSONStateRepository guarda cada tabla de estado en un archivo JSON con la versión
+// de su formato. Las tablas guardadas con gob por versiones anteriores se leen tal
+// cual; solo Migrate las convierte a JSON.
+type JSONStateRepository struct{}
+
+func NewJSONStateRepository() ports.StateRepository {
+	return &JSONStateRepository{}
+}
+
+// Get lee una tabla de estado sin modificar el archivo, aunque use un formato anterior.
+func (r *JSONStateRepository) Get(filePath string) (*aggregates.StateTable, error) {
+	stateTable, _, err := r.read(filePath)
+	return stateTable, err
+}
+
+func (r *JSONStateRepository) Save(filePath string, stateTable *aggregates.StateTable) error {
+	if stateTable == nil {
+		return errors.New("no se puede guardar una tabla de estado nula")
+	}
+
+	// Mapear del agregado de dominio al DTO
+	data, err := json.MarshalIndent(toStateTableDTO(stateTable), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error al codificar el estado: %w", err)
+	}
+
+	// Escribir en un archivo temporal y renombrarlo, para que una ejecución
+	// simultánea nunca lea una tabla a medias.
+	return atomicfile.WriteFile(filePath, data)
+}
+
+// List devuelve las tablas de estado de un directorio, ordenadas por nombre.
+func (r *JSONStateRepository) List(dirPath string) ([]string, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("no se pudo leer el directorio de estado '%s': %w", dirPath, err)
+	}
+
+	filePaths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			filePaths = append(filePaths, filepath.Join(dirPath, entry.Name()))
+		}
+	}
+	return filePaths, nil
+}
+
+// Migrate convierte a JSON una tabla de estado guardada con gob. Indica si la convirtió.
+func (r *JSONStateRepository) Migrate(filePath string) (bool, error) {
+	stateTable, legacy, err := r.read(filePath)
+	if err != nil || !legacy {
+		return false, err
+	}
+	if err := r.Save(filePath, stateTable); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// read lee una tabla de estado e indica si estaba guardada con gob.
+func (r *JSONStateRepository) read(filePath string) (*aggregates.StateTable, bool, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// Si el archivo no existe, no es un error. Simplemente no hay estado.
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		// Un archivo vacío significa que no hay estado, no es un error fatal.
+		return nil, false, nil
+	}
+
+	if !json.Valid(data) {
+		stateTable, err := decodeGobStateTable(data)
+		if err != nil {
+			return nil, false, fmt.Errorf("error al decodificar el estado '%s': %w", filePath, err)
+		}
+		return stateTable, true, nil
+	}
+
+	var stateTableDTO StateTableDTO
+	if err := json.Unmarshal(data, &stateTableDTO); err != nil {
+		return nil, false, fmt.Errorf("error al decodificar el estado '%s': %w", filePath, err)
+	}
+	if stateTableDTO.SchemaVersion > stateSchemaVersion {
+		return nil, false, fmt.Errorf("el estado '%s' usa la versión %d del formato y esta versión de vex solo admite hasta la %d; actualiza vex",
+			filePath, stateTableDTO.SchemaVersion, stateSchemaVersion)
+	}
+	return fromDTO(&stateTableDTO), false, nil
+}
@@ -0,0 +1,104 @@
+package state
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jairoprogramador/vex/internal/domain/state/aggregates"
+	"github.com/jairoprogramador/vex/internal/domain/state/vos"
+)
+
+func TestJSONStateRepository_SaveAndGet_KeepsManifests(t *testing.T) {
+	repo := NewJSONStateRepository()
+	filePath := filepath.Join(t.TempDir(), "state", "package.tb")
+
+	code, _ := vos.NewFingerprint("code")
+	instruction, _ := vos.NewFingerprint("instruction")
+	vars, _ := vos.NewFingerprint("vars")
+	env, _ := vos.NewEnvironment("sand")
+	codeManifest := vos.NewManifest(map[string]string{"main.go": "h1", "go.mod": "h2"})
+	varsManifest := vos.NewManifest(map[string]string{"region": "h3"})
+
+	table := aggregates.NewStateTable("package")
+	table.AddEntry(aggregates.NewStateEntry(code, instruction, vars, env, aggregates.WithManifests(codeManifest, varsManifest)))
+	table.AddEntry(aggregates.NewStateEntry(code, instruction, vars, env))
+	require.NoError(t, repo.Save(filePath, table))
+
+	loaded, err := repo.Get(filePath)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries(), 2)
+	assert.Equal(t, codeManifest.Hashes(), loaded.Entries()[0].CodeManifest().Hashes())
+	assert.Equal(t, varsManifest.Hashes(), loaded.Entries()[0].VarsManifest().Hashes())
+	assert.True(t, loaded.Entries()[1].CodeManifest().IsEmpty())
+
+	filePaths, err := repo.List(filepath.Dir(filePath))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filePath}, filePaths)
+}
+
+func TestJSONStateRepository_MigratesGobTables(t *testing.T) {
+	repo := NewJSONStateRepository()
+	filePath := filepath.Join(t.TempDir(), "package.tb")
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	var buffer bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buffer).Encode(gobStateTableDTO{
+		Name: "package",
+		Entries: []*gobStateEntryDTO{{
+			Code: "code", Instruction: "instruction", Vars: "vars", Environment: "sand", CreatedAt: createdAt,
+			CodeManifest: compressGobManifest(t, map[string]string{"main.go": "h1"}),
+		}},
+	}))
+	require.NoError(t, os.WriteFile(filePath, buffer.Bytes(), 0644))
+
+	loaded, err := repo.Get(filePath)
+	require.NoError(t, err)
+	require.Len(t, loaded.Entries(), 1)
+	assert.Equal(t, "code", loaded.Entries()[0].Code().String())
+	assert.Equal(t, createdAt, loaded.Entries()[0].CreatedAt())
+	assert.Equal(t, map[string]string{"main.go": "h1"}, loaded.Entries()[0].CodeManifest().Hashes())
+
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, buffer.Bytes(), data, "Get no debe reescribir la tabla")
+
+	migrated, err := repo.Migrate(filePath)
+	require.NoError(t, err)
+	assert.True(t, migrated)
+
+	data, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	var dto StateTableDTO
+	require.NoError(t, json.Unmarshal(data, &dto))
+	assert.Equal(t, stateSchemaVersion, dto.SchemaVersion)
+
+	migrated, err = repo.Migrate(filePath)
+	require.NoError(t, err)
+	assert.False(t, migrated)
+}
+
+func TestJSONStateRepository_RejectsNewerSchema(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "package.tb")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"schema_version": 99, "name": "package"}`), 0644))
+
+	_, err := NewJSONStateRepository().Get(filePath)
+
+	assert.ErrorContains(t, err, "versión 99")
+}
+
+func compressGobManifest(t *testing.T, hashes map[string]string) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	require.NoError(t, gob.NewEncoder(writer).Encode(hashes))
+	require.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
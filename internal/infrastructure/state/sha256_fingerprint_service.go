@@ -6,22 +6,64 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/jairoprogramador/vex/internal/domain/state/ports"
 	"github.com/jairoprogramador/vex/internal/domain/state/vos"
-	gitignore "github.com/sabhiram/go-gitignore"
 )
 
 // Sha256FingerprintService implementa FingerprintService usando el algoritmo SHA-256.
-type Sha256FingerprintService struct{}
+// Recuerda durante la ejecución el hash de cada archivo, junto con su tamaño, fecha
+// de modificación e inodo, para leer solo los archivos que cambiaron.
+type Sha256FingerprintService struct {
+	index    *fingerprintIndex
+	paranoid bool
+	workers  int
+}
+
+type Sha256FingerprintOption func(*Sha256FingerprintService)
+
+// WithIndexFile guarda el índice de hashes en indexPath para reutilizarlo entre ejecuciones.
+func WithIndexFile(indexPath string) Sha256FingerprintOption {
+	return func(s *Sha256FingerprintService) {
+		s.index = newFingerprintIndex(indexPath)
+	}
+}
+
+// WithParanoid vuelve a leer todos los archivos sin confiar en el índice. Los hashes
+// calculados sí actualizan el índice.
+func WithParanoid(paranoid bool) Sha256FingerprintOption {
+	return func(s *Sha256FingerprintService) {
+		s.paranoid = paranoid
+	}
+}
+
+// WithWorkers fija cuántos archivos se leen a la vez.
+func WithWorkers(workers int) Sha256FingerprintOption {
+	return func(s *Sha256FingerprintService) {
+		if workers > 0 {
+			s.workers = workers
+		}
+	}
+}
 
 // NewSha256FingerprintService crea una nueva instancia de Sha256FingerprintService.
-func NewSha256FingerprintService() ports.FingerprintService {
-	return &Sha256FingerprintService{}
+func NewSha256FingerprintService(opts ...Sha256FingerprintOption) ports.FingerprintService {
+	service := &Sha256FingerprintService{
+		index:   newFingerprintIndex(""),
+		workers: runtime.NumCPU(),
+	}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
 }
 
 // FromFile calcula el fingerprint de un único archivo.
@@ -44,21 +86,37 @@ func (s *Sha256FingerprintService) FromFile(filePath string) (vos.Fingerprint, e
 	return vos.NewFingerprint(hex.EncodeToString(hashBytes))
 }
 
-// FromDirectory calcula el fingerprint de un directorio, respetando .gitignore.
+// FromDirectory calcula el fingerprint de un directorio, respetando .gitignore,
+// .git/info/exclude y .vexignore.
 func (s *Sha256FingerprintService) FromDirectory(dirPath string) (vos.Fingerprint, error) {
-	var ignorer *gitignore.GitIgnore
-	gitignorePath := filepath.Join(dirPath, ".gitignore")
-	if _, err := os.Stat(gitignorePath); err == nil {
-		ignorer, err = gitignore.CompileIgnoreFile(gitignorePath)
-		if err != nil {
-			return vos.Fingerprint{}, fmt.Errorf("failed to compile .gitignore file: %w", err)
-		}
+	manifest, err := s.DirectoryManifest(dirPath, vos.FingerprintScope{})
+	if err != nil {
+		return vos.Fingerprint{}, err
+	}
+	return s.FromManifest(manifest)
+}
+
+// DirectoryManifest calcula el hash de cada archivo de un directorio incluido en scope.
+// Respeta .git/info/exclude, el .gitignore de la raíz y los de cada subdirectorio, y
+// .vexignore, que prevalece sobre todos ellos.
+func (s *Sha256FingerprintService) DirectoryManifest(dirPath string, scope vos.FingerprintScope) (vos.Manifest, error) {
+	dirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return vos.Manifest{}, fmt.Errorf("failed to resolve directory %s: %w", dirPath, err)
+	}
+	rootRules, vexRules, err := rootIgnoreRules(dirPath)
+	if err != nil {
+		return vos.Manifest{}, err
 	}
+	// rulesByDir guarda las reglas vigentes en cada directorio recorrido.
+	rulesByDir := map[string]ignoreRules{".": rootRules}
 
-	// fileHashes almacenará la ruta relativa y el hash de cada archivo.
-	var fileHashes []string
+	// fileHashes almacenará el hash de cada archivo por su ruta relativa; pending, los
+	// archivos que no están en el índice o cambiaron desde que se indexaron.
+	fileHashes := make(map[string]string)
+	var pending []pendingFile
 
-	err := filepath.WalkDir(dirPath, func(path string, d os.DirEntry, err error) error {
+	err = filepath.WalkDir(dirPath, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -79,42 +137,137 @@ func (s *Sha256FingerprintService) FromDirectory(dirPath string) (vos.Fingerprin
 			return filepath.SkipDir
 		}
 
-		// Comprobar si la ruta debe ser ignorada por .gitignore.
-		if ignorer != nil && ignorer.MatchesPath(relPath) {
+		// Comprobar si la ruta debe ser ignorada.
+		rules := rulesByDir[filepath.Dir(relPath)]
+		if isIgnored(rules, vexRules, relPath) || (d.IsDir() && scope.ExcludesDir(relPath)) {
 			if d.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// Si es un directorio, no hacemos nada más.
+		// Un directorio hereda las reglas de su padre más las de su propio .gitignore.
 		if d.IsDir() {
+			lines, err := readIgnoreFile(filepath.Join(path, gitignoreFileName))
+			if err != nil {
+				return err
+			}
+			rulesByDir[relPath] = rules.with(relPath, lines)
 			return nil
 		}
 
-		// Calcular el hash del archivo.
-		fileFingerprint, err := s.FromFile(path)
-		if err != nil {
-			return fmt.Errorf("failed to get fingerprint for file %s: %w", path, err)
+		if !scope.Includes(relPath) {
+			return nil
 		}
 
-		// Añadir la ruta relativa y el hash a nuestra lista para el hash final.
-		// Se usa un separador para asegurar que no haya colisiones con nombres de archivo.
-		fileHashes = append(fileHashes, fmt.Sprintf("%s:%s", relPath, fileFingerprint.String()))
+		// Los enlaces simbólicos se leen siempre: su fecha no cambia con el destino.
+		if d.Type()&fs.ModeSymlink != 0 {
+			pending = append(pending, pendingFile{relPath: relPath, path: path})
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat file %s: %w", path, err)
+		}
+		if !s.paranoid {
+			if hash, indexed := s.index.lookup(path, info); indexed {
+				fileHashes[relPath] = hash
+				return nil
+			}
+		}
+		pending = append(pending, pendingFile{relPath: relPath, path: path, info: info})
 
 		return nil
 	})
 
 	if err != nil {
-		return vos.Fingerprint{}, fmt.Errorf("failed to walk directory %s: %w", dirPath, err)
+		return vos.Manifest{}, fmt.Errorf("failed to walk directory %s: %w", dirPath, err)
+	}
+
+	if err := s.hashFiles(pending, fileHashes); err != nil {
+		return vos.Manifest{}, err
+	}
+	s.index.markWalked(dirPath)
+	// El índice es una caché: si no se puede guardar, la próxima ejecución
+	// simplemente vuelve a leer los archivos.
+	_ = s.index.save()
+
+	return vos.NewManifest(fileHashes), nil
+}
+
+// pendingFile es un archivo cuyo hash hay que calcular. info es nil para los enlaces
+// simbólicos, que no se guardan en el índice.
+type pendingFile struct {
+	relPath string
+	path    string
+	info    fs.FileInfo
+}
+
+// hashFiles calcula en paralelo el hash de los archivos pendientes y los añade a
+// fileHashes y al índice.
+func (s *Sha256FingerprintService) hashFiles(pending []pendingFile, fileHashes map[string]string) error {
+	jobs := make(chan pendingFile)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < min(s.workers, len(pending)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				hashedAt := time.Now()
+				fileFingerprint, err := s.FromFile(file.path)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("failed to get fingerprint for file %s: %w", file.path, err)
+					}
+				} else {
+					fileHashes[file.relPath] = fileFingerprint.String()
+				}
+				mu.Unlock()
+
+				if err == nil && file.info != nil {
+					s.index.store(file.path, file.info, fileFingerprint.String(), hashedAt)
+				}
+			}
+		}()
+	}
+	for _, file := range pending {
+		jobs <- file
+	}
+	close(jobs)
+	wg.Wait()
+	return firstErr
+}
+
+// isIgnored aplica las reglas de los .gitignore y, por encima de ellas, las de .vexignore.
+func isIgnored(rules, vexRules ignoreRules, relPath string) bool {
+	if ignored, decided := vexRules.match(relPath); decided {
+		return ignored
+	}
+	ignored, _ := rules.match(relPath)
+	return ignored
+}
+
+// FromManifest combina los hashes de un manifiesto en un único fingerprint.
+func (s *Sha256FingerprintService) FromManifest(manifest vos.Manifest) (vos.Fingerprint, error) {
+	// Se usa un separador para asegurar que no haya colisiones con nombres de archivo.
+	lines := make([]string, 0, len(manifest.Hashes()))
+	for key, hash := range manifest.Hashes() {
+		lines = append(lines, fmt.Sprintf("%s:%s", key, hash))
 	}
 
-	// ¡Paso crítico! Ordenar los hashes para una firma final estable.
-	sort.Strings(fileHashes)
+	// ¡Paso crítico! Ordenar las firmas para un fingerprint final estable. Se
+	// ordenan las líneas completas para no alterar los fingerprints ya guardados.
+	sort.Strings(lines)
 
 	// Unir todas las firmas en un solo string y calcular el hash final.
 	finalHasher := sha256.New()
-	finalHasher.Write([]byte(strings.Join(fileHashes, "\n")))
+	finalHasher.Write([]byte(strings.Join(lines, "\n")))
 
 	hashBytes := finalHasher.Sum(nil)
 	return vos.NewFingerprint(hex.EncodeToString(hashBytes))
@@ -9,9 +9,12 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/jairoprogramador/vex/internal/domain/state/vos"
 )
 
 // hashFromString calcula el hash SHA-256 de una cadena.
@@ -181,3 +184,142 @@ func TestSha256FingerprintService_FromDirectory(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestSha256FingerprintService_DirectoryManifest(t *testing.T) {
+	s := NewSha256FingerprintService()
+	files := map[string]string{
+		".gitignore":   "*.tmp",
+		"file1.txt":    "file1",
+		"file.tmp":     "temp file",
+		"src/main.go":  "source",
+		"src/main.go2": "other",
+	}
+	testDir := createTestDir(t, files)
+
+	manifest, err := s.DirectoryManifest(testDir, vos.FingerprintScope{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		".gitignore":                     hashFromString("*.tmp"),
+		"file1.txt":                      hashFromString("file1"),
+		filepath.Join("src", "main.go"):  hashFromString("source"),
+		filepath.Join("src", "main.go2"): hashFromString("other"),
+	}, manifest.Hashes())
+
+	fromManifest, err := s.FromManifest(manifest)
+	require.NoError(t, err)
+	fromDirectory, err := s.FromDirectory(testDir)
+	require.NoError(t, err)
+	assert.Equal(t, fromDirectory, fromManifest, "el manifiesto debe dar el mismo fingerprint que el directorio")
+}
+
+func TestSha256FingerprintService_DirectoryManifest_IgnoreFiles(t *testing.T) {
+	s := NewSha256FingerprintService()
+	manifestKeys := func(t *testing.T, files map[string]string, scope vos.FingerprintScope) []string {
+		manifest, err := s.DirectoryManifest(createTestDir(t, files), scope)
+		require.NoError(t, err)
+		return manifest.Keys()
+	}
+
+	t.Run("debería respetar los .gitignore de los subdirectorios", func(t *testing.T) {
+		keys := manifestKeys(t, map[string]string{
+			".gitignore":        "*.log",
+			"app/.gitignore":    "build/\n!keep.log",
+			"app/build/out.bin": "bin",
+			"app/keep.log":      "keep",
+			"app/other.log":     "other",
+			"app/main.go":       "main",
+			"lib/build/out.bin": "bin",
+		}, vos.FingerprintScope{})
+		assert.Equal(t, []string{
+			".gitignore",
+			filepath.Join("app", ".gitignore"),
+			filepath.Join("app", "keep.log"),
+			filepath.Join("app", "main.go"),
+			filepath.Join("lib", "build", "out.bin"),
+		}, keys)
+	})
+
+	t.Run("debería respetar .git/info/exclude y .vexignore", func(t *testing.T) {
+		keys := manifestKeys(t, map[string]string{
+			".git/info/exclude": "local.txt",
+			".vexignore":        "README.md\ndocs/",
+			"local.txt":         "local",
+			"README.md":         "readme",
+			"docs/guide.md":     "guide",
+			"main.go":           "main",
+		}, vos.FingerprintScope{})
+		assert.Equal(t, []string{".vexignore", "main.go"}, keys)
+	})
+
+	t.Run("debería limitar los archivos al scope del paso", func(t *testing.T) {
+		keys := manifestKeys(t, map[string]string{
+			"pom.xml":           "pom",
+			"README.md":         "readme",
+			"src/main/App.java": "app",
+			"src/main/NOTES.md": "notes",
+			"test/AppTest.java": "test",
+		}, vos.NewFingerprintScope([]string{"src/**", "pom.xml"}, []string{"**/*.md"}))
+		assert.Equal(t, []string{"pom.xml", filepath.Join("src", "main", "App.java")}, keys)
+	})
+}
+
+func TestSha256FingerprintService_Index(t *testing.T) {
+	// setOld deja la fecha de modificación fuera de la ventana en la que el índice desconfía.
+	old := time.Now().Add(-time.Hour)
+	setOld := func(t *testing.T, path string) {
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+	files := map[string]string{"a.txt": "aaaa", "src/b.go": "bbbb", "src/c.go": "cccc"}
+
+	t.Run("debería dar el mismo resultado que un recálculo completo", func(t *testing.T) {
+		testDir := createTestDir(t, files)
+		indexPath := filepath.Join(t.TempDir(), "index.idx")
+
+		first, err := NewSha256FingerprintService(WithIndexFile(indexPath), WithWorkers(2)).FromDirectory(testDir)
+		require.NoError(t, err)
+		second, err := NewSha256FingerprintService(WithIndexFile(indexPath)).FromDirectory(testDir)
+		require.NoError(t, err)
+
+		assert.Equal(t, calculateExpectedDirectoryHash(files), first.String())
+		assert.Equal(t, first, second)
+		assert.FileExists(t, indexPath)
+	})
+
+	t.Run("debería reutilizar el hash de un archivo sin cambios y recalcularlo con paranoid", func(t *testing.T) {
+		testDir := createTestDir(t, files)
+		target := filepath.Join(testDir, "a.txt")
+		setOld(t, target)
+		indexPath := filepath.Join(t.TempDir(), "index.idx")
+
+		_, err := NewSha256FingerprintService(WithIndexFile(indexPath)).DirectoryManifest(testDir, vos.FingerprintScope{})
+		require.NoError(t, err)
+
+		// Mismo tamaño y misma fecha: el índice no puede notar el cambio.
+		require.NoError(t, os.WriteFile(target, []byte("zzzz"), 0644))
+		setOld(t, target)
+
+		indexed, err := NewSha256FingerprintService(WithIndexFile(indexPath)).DirectoryManifest(testDir, vos.FingerprintScope{})
+		require.NoError(t, err)
+		paranoid, err := NewSha256FingerprintService(WithIndexFile(indexPath), WithParanoid(true)).DirectoryManifest(testDir, vos.FingerprintScope{})
+		require.NoError(t, err)
+
+		assert.Equal(t, hashFromString("aaaa"), indexed.Hashes()["a.txt"])
+		assert.Equal(t, hashFromString("zzzz"), paranoid.Hashes()["a.txt"])
+	})
+
+	t.Run("debería recalcular el hash de un archivo que cambió de tamaño", func(t *testing.T) {
+		testDir := createTestDir(t, files)
+		target := filepath.Join(testDir, "src", "b.go")
+		setOld(t, target)
+		service := NewSha256FingerprintService()
+
+		_, err := service.DirectoryManifest(testDir, vos.FingerprintScope{})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(target, []byte("bbbbbb"), 0644))
+		setOld(t, target)
+
+		manifest, err := service.DirectoryManifest(testDir, vos.FingerprintScope{})
+		require.NoError(t, err)
+		assert.Equal(t, hashFromString("bbbbbb"), manifest.Hashes()[filepath.Join("src", "b.go")])
+	})
+}
@@ -7,17 +7,26 @@ import (
 	"github.com/jairoprogramador/vex/internal/domain/state/vos"
 )
 
+// stateSchemaVersion es la versión del formato de las tablas de estado. Se incrementa
+// con cada cambio incompatible de StateTableDTO.
+const stateSchemaVersion = 1
+
 type StateTableDTO struct {
-	Name    string
-	Entries []*StateEntryDTO
+	SchemaVersion int              `json:"schema_version"`
+	Name          string           `json:"name"`
+	Entries       []*StateEntryDTO `json:"entries"`
 }
 
 type StateEntryDTO struct {
-	Code        string
-	Instruction string
-	Environment string
-	Vars        string
-	CreatedAt   time.Time
+	Code        string    `json:"code"`
+	Instruction string    `json:"instruction"`
+	Environment string    `json:"environment"`
+	Vars        string    `json:"vars"`
+	CreatedAt   time.Time `json:"created_at"`
+	// CodeManifest y VarsManifest guardan el hash de cada archivo y de cada variable.
+	// Las entradas creadas antes de que se guardaran no los tienen.
+	CodeManifest map[string]string `json:"code_manifest,omitempty"`
+	VarsManifest map[string]string `json:"vars_manifest,omitempty"`
 }
 
 func toStateTableDTO(aggregate *aggregates.StateTable) *StateTableDTO {
@@ -28,17 +37,20 @@ func toStateTableDTO(aggregate *aggregates.StateTable) *StateTableDTO {
 	dtoEntries := make([]*StateEntryDTO, 0, len(aggregate.Entries()))
 	for _, entry := range aggregate.Entries() {
 		dtoEntries = append(dtoEntries, &StateEntryDTO{
-			Code:        entry.Code().String(),
-			Instruction: entry.Instruction().String(),
-			Environment: entry.Environment().String(),
-			Vars:        entry.Vars().String(),
-			CreatedAt:   entry.CreatedAt(),
+			Code:         entry.Code().String(),
+			Instruction:  entry.Instruction().String(),
+			Environment:  entry.Environment().String(),
+			Vars:         entry.Vars().String(),
+			CreatedAt:    entry.CreatedAt(),
+			CodeManifest: manifestHashes(entry.CodeManifest()),
+			VarsManifest: manifestHashes(entry.VarsManifest()),
 		})
 	}
 
 	return &StateTableDTO{
-		Name:    aggregate.Name(),
-		Entries: dtoEntries,
+		SchemaVersion: stateSchemaVersion,
+		Name:          aggregate.Name(),
+		Entries:       dtoEntries,
 	}
 }
 
@@ -49,27 +61,46 @@ func fromDTO(dto *StateTableDTO) *aggregates.StateTable {
 
 	domainEntries := make([]*aggregates.StateEntry, 0, len(dto.Entries))
 	for _, dtoEntry := range dto.Entries {
+		domainEntries = append(domainEntries, newStateEntry(
+			dtoEntry.Code, dtoEntry.Instruction, dtoEntry.Vars, dtoEntry.Environment, dtoEntry.CreatedAt,
+			vos.NewManifest(dtoEntry.CodeManifest), vos.NewManifest(dtoEntry.VarsManifest)))
+	}
+	return aggregates.LoadStateTable(dto.Name, domainEntries)
+}
 
-		codeFp, err := vos.NewFingerprint(dtoEntry.Code)
-		if err != nil {
-			codeFp = vos.Fingerprint{}
-		}
-		instFp, err := vos.NewFingerprint(dtoEntry.Instruction)
-		if err != nil {
-			instFp = vos.Fingerprint{}
-		}
-		varsFp, err := vos.NewFingerprint(dtoEntry.Vars)
-		if err != nil {
-			varsFp = vos.Fingerprint{}
-		}
-		env, err := vos.NewEnvironment(dtoEntry.Environment)
-		if err != nil {
-			env = vos.Environment{}
-		}
+// newStateEntry reconstruye una entrada guardada. Un fingerprint o un entorno
+// inválido se trata como vacío, de modo que la entrada nunca coincide.
+func newStateEntry(
+	code, instruction, vars, environment string, createdAt time.Time,
+	codeManifest, varsManifest vos.Manifest) *aggregates.StateEntry {
 
-		entry := aggregates.NewStateEntry(codeFp, instFp, varsFp, env)
-		entry.SetCreatedAt(dtoEntry.CreatedAt)
-		domainEntries = append(domainEntries, entry)
+	codeFp, err := vos.NewFingerprint(code)
+	if err != nil {
+		codeFp = vos.Fingerprint{}
 	}
-	return aggregates.LoadStateTable(dto.Name, domainEntries)
+	instFp, err := vos.NewFingerprint(instruction)
+	if err != nil {
+		instFp = vos.Fingerprint{}
+	}
+	varsFp, err := vos.NewFingerprint(vars)
+	if err != nil {
+		varsFp = vos.Fingerprint{}
+	}
+	env, err := vos.NewEnvironment(environment)
+	if err != nil {
+		env = vos.Environment{}
+	}
+
+	entry := aggregates.NewStateEntry(codeFp, instFp, varsFp, env, aggregates.WithManifests(codeManifest, varsManifest))
+	entry.SetCreatedAt(createdAt)
+	return entry
+}
+
+// manifestHashes devuelve los hashes de un manifiesto, o nil si está vacío para no
+// escribirlo en la tabla.
+func manifestHashes(manifest vos.Manifest) map[string]string {
+	if manifest.IsEmpty() {
+		return nil
+	}
+	return manifest.Hashes()
 }
@@ -2,14 +2,19 @@ package versioning
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/jairoprogramador/vex/internal/domain/versioning/ports"
 	"github.com/jairoprogramador/vex/internal/domain/versioning/vos"
@@ -28,7 +33,7 @@ func NewGoGitRepository() ports.GitRepository {
 
 // GetLastCommit obtiene el último commit de la rama actual (HEAD).
 func (r *GoGitRepository) GetLastCommit(ctx context.Context, repoPath string) (*vos.Commit, error) {
-	repo, err := git.PlainOpen(repoPath)
+	repo, err := openRepository(repoPath)
 	if err != nil {
 		return nil, fmt.Errorf("error al abrir el repositorio: %w", err)
 	}
@@ -54,7 +59,7 @@ func (r *GoGitRepository) GetLastCommit(ctx context.Context, repoPath string) (*
 // GetCommitsSinceTag obtiene todos los commits desde un tag específico.
 // Si lastTag está vacío, devuelve todos los commits.
 func (r *GoGitRepository) GetCommitsSinceTag(ctx context.Context, repoPath string, lastTag string) ([]*vos.Commit, error) {
-	repo, err := git.PlainOpen(repoPath)
+	repo, err := openRepository(repoPath)
 	if err != nil {
 		return nil, fmt.Errorf("error al abrir el repositorio: %w", err)
 	}
@@ -119,7 +124,7 @@ func (r *GoGitRepository) GetCommitsSinceTag(ctx context.Context, repoPath strin
 
 // GetLastSemverTag obtiene el último tag semántico del repositorio.
 func (r *GoGitRepository) GetLastSemverTag(ctx context.Context, repoPath string) (string, error) {
-	repo, err := git.PlainOpen(repoPath)
+	repo, err := openRepository(repoPath)
 	if err != nil {
 		return "", fmt.Errorf("error al abrir el repositorio: %w", err)
 	}
@@ -159,7 +164,7 @@ func (r *GoGitRepository) GetLastSemverTag(ctx context.Context, repoPath string)
 // CreateTagForCommit crea un nuevo tag apuntando a un commit específico.
 // Devuelve un error si el tag ya existe o el commit no se encuentra.
 func (r *GoGitRepository) CreateTagForCommit(ctx context.Context, repoPath string, commitHash string, tagName string) error {
-	repo, err := git.PlainOpen(repoPath)
+	repo, err := openRepository(repoPath)
 	if err != nil {
 		return fmt.Errorf("error al abrir el repositorio: %w", err)
 	}
@@ -192,3 +197,213 @@ func (r *GoGitRepository) CreateTagForCommit(ctx context.Context, repoPath strin
 
 	return nil
 }
+
+// ExportRevision escribe los archivos de una revisión en destPath.
+// Funciona como un worktree de solo lectura: el repositorio original no se modifica.
+func (r *GoGitRepository) ExportRevision(ctx context.Context, repoPath string, revision string, destPath string) (*vos.Commit, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el repositorio: %w", err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo resolver la revisión '%s': %w", revision, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el commit object: %w", err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el árbol del commit '%s': %w", hash.String(), err)
+	}
+
+	err = tree.Files().ForEach(func(file *object.File) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		return exportFile(file, filepath.Join(destPath, filepath.FromSlash(file.Name)))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al exportar la revisión '%s': %w", revision, err)
+	}
+
+	return &vos.Commit{
+		Hash:    commit.Hash.String(),
+		Message: commit.Message,
+		Author:  commit.Author.Name,
+		Date:    commit.Author.When,
+	}, nil
+}
+
+// GetRemoteURL devuelve la primera URL configurada para el remoto 'origin'.
+func (r *GoGitRepository) GetRemoteURL(ctx context.Context, repoPath string) (string, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("error al abrir el repositorio: %w", err)
+	}
+
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		if errors.Is(err, git.ErrRemoteNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("error al obtener el remoto '%s': %w", git.DefaultRemoteName, err)
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", nil
+	}
+	return urls[0], nil
+}
+
+// GetWorkingTreeStatus obtiene el estado del árbol de trabajo y lo compara con la
+// rama '<remote>/<rama actual>'. No consulta el remoto: usa la última información
+// obtenida con fetch o push.
+func (r *GoGitRepository) GetWorkingTreeStatus(
+	ctx context.Context, repoPath string, remote string) (*vos.WorkingTreeStatus, error) {
+	repo, err := openRepository(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el repositorio: %w", err)
+	}
+
+	headRef, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener HEAD: %w", err)
+	}
+	status := &vos.WorkingTreeStatus{Head: headRef.Hash().String()}
+	if headRef.Name().IsBranch() {
+		status.Branch = headRef.Name().Short()
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el árbol de trabajo: %w", err)
+	}
+	fileStatus, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el estado del árbol de trabajo: %w", err)
+	}
+	for file, state := range fileStatus {
+		if state.Staging != git.Unmodified || state.Worktree != git.Unmodified {
+			status.ChangedFiles = append(status.ChangedFiles, file)
+		}
+	}
+	sort.Strings(status.ChangedFiles)
+
+	if status.IsDetached() {
+		if err := addRefsAtHead(repo, remote, headRef.Hash(), status); err != nil {
+			return nil, err
+		}
+		return status, nil
+	}
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, status.Branch), true)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return status, nil
+		}
+		return nil, fmt.Errorf("error al obtener la rama remota '%s/%s': %w", remote, status.Branch, err)
+	}
+	status.RemoteBranch = remote + "/" + status.Branch
+
+	if remoteRef.Hash() == headRef.Hash() {
+		status.Pushed = true
+		return status, nil
+	}
+	headCommit, err := repo.CommitObject(headRef.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el commit de HEAD: %w", err)
+	}
+	remoteCommit, err := repo.CommitObject(remoteRef.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el commit de '%s': %w", status.RemoteBranch, err)
+	}
+	status.Pushed, err = headCommit.IsAncestor(remoteCommit)
+	if err != nil {
+		return nil, fmt.Errorf("error al comparar HEAD con '%s': %w", status.RemoteBranch, err)
+	}
+	return status, nil
+}
+
+// addRefsAtHead anota las ramas remotas y los tags que apuntan a HEAD. En CI el
+// repositorio suele estar en un commit desacoplado de la rama o del tag que se construye.
+func addRefsAtHead(repo *git.Repository, remote string, head plumbing.Hash, status *vos.WorkingTreeStatus) error {
+	refs, err := repo.References()
+	if err != nil {
+		return fmt.Errorf("error al obtener las referencias: %w", err)
+	}
+	remotePrefix := remote + "/"
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		switch {
+		case ref.Name().IsRemote() && strings.HasPrefix(ref.Name().Short(), remotePrefix):
+			if ref.Hash() == head {
+				status.RemoteBranchesAtHead = append(status.RemoteBranchesAtHead,
+					strings.TrimPrefix(ref.Name().Short(), remotePrefix))
+			}
+		case ref.Name().IsTag():
+			target := ref.Hash()
+			if tag, err := repo.TagObject(target); err == nil {
+				target = tag.Target
+			}
+			if target == head {
+				status.TagsAtHead = append(status.TagsAtHead, ref.Name().Short())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error al recorrer las referencias: %w", err)
+	}
+	sort.Strings(status.RemoteBranchesAtHead)
+	sort.Strings(status.TagsAtHead)
+	return nil
+}
+
+func exportFile(file *object.File, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+
+	if file.Mode == filemode.Symlink {
+		target, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, destPath)
+	}
+
+	perm, err := file.Mode.ToOSFileMode()
+	if err != nil {
+		return err
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	destFile, err := os.OpenFile(destPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer destFile.Close()
+
+	_, err = io.Copy(destFile, reader)
+	return err
+}
+
+// openRepository abre un repositorio normal, bare o un árbol de trabajo enlazado
+// (git worktree), cuyos objetos y referencias viven en el repositorio principal.
+func openRepository(repoPath string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+}
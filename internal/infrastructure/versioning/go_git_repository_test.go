@@ -92,4 +92,62 @@ func TestGoGitRepository(t *testing.T) {
 			assert.Len(t, commits, 4, "Debería devolver todos los commits")
 		})
 	})
+
+	t.Run("ExportRevision", func(t *testing.T) {
+		destDir := t.TempDir()
+		commit, err := repoService.ExportRevision(ctx, tmpDir, "v0.1.0", destDir)
+		require.NoError(t, err)
+		assert.Equal(t, c1.String(), commit.Hash)
+
+		content, err := os.ReadFile(filepath.Join(destDir, "test.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "content v1", string(content))
+
+		_, err = repoService.ExportRevision(ctx, tmpDir, "v9.9.9", t.TempDir())
+		assert.Error(t, err, "Debería fallar con una revisión inexistente")
+	})
+
+	t.Run("GetWorkingTreeStatus", func(t *testing.T) {
+		status, err := repoService.GetWorkingTreeStatus(ctx, tmpDir, "origin")
+		require.NoError(t, err)
+		assert.Equal(t, "master", status.Branch)
+		assert.Equal(t, c4.String(), status.Head)
+		assert.True(t, status.IsClean())
+		assert.Empty(t, status.RemoteBranch, "Sin rama remota no hay seguimiento")
+		assert.False(t, status.Pushed)
+
+		remoteBranch := plumbing.NewRemoteReferenceName("origin", "master")
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(remoteBranch, c3)))
+		status, err = repoService.GetWorkingTreeStatus(ctx, tmpDir, "origin")
+		require.NoError(t, err)
+		assert.Equal(t, "origin/master", status.RemoteBranch)
+		assert.False(t, status.Pushed, "HEAD tiene un commit que el remoto no conoce")
+
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(remoteBranch, c4)))
+		status, err = repoService.GetWorkingTreeStatus(ctx, tmpDir, "origin")
+		require.NoError(t, err)
+		assert.True(t, status.Pushed)
+
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "test.txt"), []byte("dirty"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "new.txt"), []byte("new"), 0644))
+		status, err = repoService.GetWorkingTreeStatus(ctx, tmpDir, "origin")
+		require.NoError(t, err)
+		assert.False(t, status.IsClean())
+		assert.Equal(t, []string{"new.txt", "test.txt"}, status.ChangedFiles)
+	})
+
+	t.Run("GetWorkingTreeStatus con HEAD desacoplado", func(t *testing.T) {
+		require.NoError(t, w.Checkout(&git.CheckoutOptions{Hash: c3, Force: true}))
+		t.Cleanup(func() {
+			require.NoError(t, w.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master"), Force: true}))
+		})
+		releaseBranch := plumbing.NewRemoteReferenceName("origin", "release/1.0")
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(releaseBranch, c3)))
+
+		status, err := repoService.GetWorkingTreeStatus(ctx, tmpDir, "origin")
+		require.NoError(t, err)
+		assert.True(t, status.IsDetached())
+		assert.Equal(t, []string{"release/1.0"}, status.RemoteBranchesAtHead)
+		assert.Equal(t, []string{"beta", "v1.0.0"}, status.TagsAtHead)
+	})
 }